@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+/**
+ * AdminHandler exposes runtime controls for a logger. It has no authentication
+ * of its own, so mount it on an internal-only listener or behind your own auth:
+ *
+ *	mux.Handle("/admin/logging/", http.StripPrefix("/admin/logging", logging.AdminHandler(logger)))
+ *
+ * Endpoints:
+ *
+ *	GET  /level       current minimum level
+ *	PUT  /level       set the level from {"level":"DEBUG"} or ?level=DEBUG
+ *	POST /flush       push queued Loki entries and sync files
+ *	GET  /stats       pipeline counters (Logger.Stats) and writer statistics per file
+ *	POST /alert/test  send a CRITICAL test alert through every configured alerter
+ *
+ * @param l Logger to control
+ * @return http.Handler Handler serving the admin endpoints
+ */
+func AdminHandler(l *Logger) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /level", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"level": l.Level()})
+	})
+
+	setLevel := func(w http.ResponseWriter, r *http.Request) {
+		level := r.URL.Query().Get("level")
+		if level == "" {
+			var body struct {
+				Level string `json:"level"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "expected {\"level\": \"...\"} or ?level="})
+				return
+			}
+			level = body.Level
+		}
+
+		if err := l.SetLevel(LogLevel(level)); err != nil {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+			return
+		}
+
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"level": l.Level()})
+	}
+	mux.HandleFunc("PUT /level", setLevel)
+	mux.HandleFunc("POST /level", setLevel)
+
+	mux.HandleFunc("POST /flush", func(w http.ResponseWriter, r *http.Request) {
+		if err := l.Flush(); err != nil {
+			writeAdminJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"flushed": true})
+	})
+
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, r *http.Request) {
+		writers := make(map[string]WriterStats, len(l.files)+1)
+		for _, f := range l.files {
+			writers[f.basePath] = f.Stats()
+		}
+		if l.debugStream != nil {
+			if f := l.debugStream.opened(); f != nil {
+				writers[f.basePath] = f.Stats()
+			}
+		}
+
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+			"service":  l.config.ServiceName,
+			"level":    l.Level(),
+			"pipeline": l.Stats(),
+			"writers":  writers,
+		})
+	})
+
+	mux.HandleFunc("POST /alert/test", func(w http.ResponseWriter, r *http.Request) {
+		if l.alertManager == nil {
+			writeAdminJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"error": "alerts are not configured"})
+			return
+		}
+
+		// the timestamp keeps repeated tests from being rate limited as duplicates
+		err := fmt.Errorf("test alert from admin endpoint at %s", time.Now().Format(time.RFC3339Nano))
+		l.sendAlert(r.Context(), string(LevelCritical), err, 1)
+
+		writeAdminJSON(w, http.StatusAccepted, map[string]interface{}{"sent": true})
+	})
+
+	return mux
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
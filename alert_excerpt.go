@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"strings"
+	"sync"
+)
+
+const (
+	defaultExcerptLines = 30
+	excerptEntries      = 512
+)
+
+/**
+ * ExcerptConfig attaches recent error-log output to alerts. Lines caps the
+ * excerpt (default 30, keeping the most recent lines). With Request, only
+ * error-log entries mentioning the alert's request ID are included, i.e. its
+ * error blocks; alerts without a request ID or matching entries fall back to
+ * the most recent lines.
+ */
+type ExcerptConfig struct {
+	Lines   int  `yaml:"lines"`
+	Request bool `yaml:"request"`
+}
+
+/**
+ * excerptBuffer is an extra error-stream writer that keeps the last writes in
+ * memory. log.Logger makes one Write per entry, or per part of a boxed error
+ * block.
+ */
+type excerptBuffer struct {
+	config  *ExcerptConfig
+	mu      sync.Mutex
+	entries []string
+	next    int
+}
+
+func newExcerptBuffer(config *ExcerptConfig) *excerptBuffer {
+	return &excerptBuffer{config: config, entries: make([]string, 0, excerptEntries)}
+}
+
+func (b *excerptBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.entries) < excerptEntries {
+		b.entries = append(b.entries, string(p))
+	} else {
+		b.entries[b.next] = string(p)
+		b.next = (b.next + 1) % excerptEntries
+	}
+	return len(p), nil
+}
+
+/**
+ * excerpt returns the configured tail of the error log for an alert.
+ */
+func (b *excerptBuffer) excerpt(requestID string) string {
+	b.mu.Lock()
+	ordered := make([]string, 0, len(b.entries))
+	ordered = append(ordered, b.entries[b.next:]...)
+	ordered = append(ordered, b.entries[:b.next]...)
+	b.mu.Unlock()
+
+	if b.config.Request && requestID != "" {
+		var matching []string
+		for _, entry := range ordered {
+			if strings.Contains(entry, requestID) {
+				matching = append(matching, entry)
+			}
+		}
+		if len(matching) > 0 {
+			ordered = matching
+		}
+	}
+
+	limit := b.config.Lines
+	if limit <= 0 {
+		limit = defaultExcerptLines
+	}
+	lines := strings.Split(strings.TrimRight(strings.Join(ordered, ""), "\n"), "\n")
+	if len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+	return strings.Join(lines, "\n")
+}
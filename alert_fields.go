@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+)
+
+type alertFieldsKey struct{}
+
+/**
+ * WithAlertField attaches a key/value pair that alerts raised with ctx carry
+ * as an extra field, e.g. the order ID or customer being processed. Fields
+ * accumulate in the order they are added; a repeated key replaces the value.
+ *
+ * @param ctx Request context
+ * @param key Field name shown by the alert providers
+ * @param value Field value
+ * @return context.Context Context carrying the field
+ */
+func WithAlertField(ctx context.Context, key, value string) context.Context {
+	fields := mergeAlertFields(AlertFieldsFromContext(ctx), []alerts.Field{{Key: key, Value: value}})
+	return context.WithValue(ctx, alertFieldsKey{}, fields)
+}
+
+/**
+ * AlertFieldsFromContext returns the fields added with WithAlertField.
+ *
+ * @param ctx Request context
+ * @return []alerts.Field Fields in insertion order, nil if none
+ */
+func AlertFieldsFromContext(ctx context.Context) []alerts.Field {
+	fields, _ := ctx.Value(alertFieldsKey{}).([]alerts.Field)
+	return fields
+}
+
+/**
+ * WithAlertFields returns a logger whose alerts always carry fields, sorted by
+ * key, e.g. the tenant or region a worker serves. Context fields with the same
+ * key take precedence. The returned logger shares writers with l; close only l.
+ *
+ * @param fields Field names and values
+ * @return *Logger Logger adding the fields to every alert
+ */
+func (l *Logger) WithAlertFields(fields map[string]string) *Logger {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	extra := make([]alerts.Field, 0, len(keys))
+	for _, k := range keys {
+		extra = append(extra, alerts.Field{Key: k, Value: fields[k]})
+	}
+
+	clone := *l
+	clone.alertFields = mergeAlertFields(l.alertFields, extra)
+	return &clone
+}
+
+// mergeAlertFields returns a new slice; later fields replace earlier ones with the same key in place
+func mergeAlertFields(base, extra []alerts.Field) []alerts.Field {
+	if len(extra) == 0 {
+		return base
+	}
+
+	out := append(make([]alerts.Field, 0, len(base)+len(extra)), base...)
+	for _, f := range extra {
+		replaced := false
+		for i := range out {
+			if out[i].Key == f.Key {
+				out[i].Value = f.Value
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			out = append(out, f)
+		}
+	}
+	return out
+}
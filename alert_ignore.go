@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+/**
+ * The default ignore rules match errors caused by clients going away rather than
+ * by the service: cancelled requests, and writes to closed connections.
+ */
+var (
+	defaultIgnoreErrors   = []error{context.Canceled}
+	defaultIgnorePatterns = []string{`(?i)broken pipe`, `(?i)connection reset by peer`, `context canceled`}
+)
+
+/**
+ * alertIgnore decides which errors are logged but never alerted on. An error
+ * matches when errors.Is reports one of errs or its message matches a pattern.
+ */
+type alertIgnore struct {
+	errs     []error
+	patterns []*regexp.Regexp
+}
+
+func newAlertIgnore(cfg *AlertsConfig) (*alertIgnore, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	ignore := &alertIgnore{errs: append([]error{}, cfg.IgnoreErrors...)}
+	patterns := cfg.IgnorePatterns
+	if !cfg.NoDefaultIgnores {
+		ignore.errs = append(ignore.errs, defaultIgnoreErrors...)
+		patterns = append(append([]string{}, defaultIgnorePatterns...), patterns...)
+	}
+
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alert ignore pattern %q: %w", p, err)
+		}
+		ignore.patterns = append(ignore.patterns, re)
+	}
+	return ignore, nil
+}
+
+func (a *alertIgnore) match(err error) bool {
+	if a == nil {
+		return false
+	}
+	for _, target := range a.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	msg := err.Error()
+	for _, re := range a.patterns {
+		if re.MatchString(msg) {
+			return true
+		}
+	}
+	return false
+}
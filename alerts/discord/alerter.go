@@ -1,25 +1,32 @@
 package discord
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ahmadsaubani/go-logging-lib/alerts"
+	"github.com/ahmadsaubani/go-logging-lib/outbound"
 )
 
 type Config struct {
-	Enabled    bool   `yaml:"enabled"`
-	WebhookURL string `yaml:"webhook_url"`
-	Username   string `yaml:"username"`
-	AvatarURL  string `yaml:"avatar_url"`
+	Enabled    bool                `yaml:"enabled"`
+	WebhookURL string              `yaml:"webhook_url"`
+	Username   string              `yaml:"username"`
+	AvatarURL  string              `yaml:"avatar_url"`
+	TLS        *outbound.TLSConfig `yaml:"tls,omitempty"`
+	Proxy      string              `yaml:"proxy"`
+	TimeoutSec int                 `yaml:"timeout_sec"`
+	Retries    int                 `yaml:"retries"`
 }
 
 type Alerter struct {
-	config *Config
-	client *http.Client
+	config    *Config
+	client    *http.Client
+	clientErr error
 }
 
 /**
@@ -30,9 +37,17 @@ type Alerter struct {
  * @return *Alerter Ready-to-use Discord alerter
  */
 func New(config *Config) *Alerter {
+	// a broken TLS or proxy config is reported by every Send rather than silently ignored
+	client, err := outbound.NewHTTPClient(outbound.ClientOptions{
+		TLS:     config.TLS,
+		Proxy:   config.Proxy,
+		Timeout: alerts.Timeout(config.TimeoutSec),
+	})
+
 	return &Alerter{
-		config: config,
-		client: &http.Client{Timeout: 10 * time.Second},
+		config:    config,
+		client:    client,
+		clientErr: err,
 	}
 }
 
@@ -40,14 +55,22 @@ func (a *Alerter) Name() string {
 	return "Discord"
 }
 
+func (a *Alerter) Retries() int {
+	return a.config.Retries
+}
+
 /**
  * Send dispatches an alert to Discord via webhook.
  * Creates a rich embed message with color-coded severity and detailed fields.
  *
+ * @param ctx Cancels the send, e.g. on shutdown
  * @param payload Alert data containing error details and request metadata
  * @return error Returns nil on success, or error if webhook fails
  */
-func (a *Alerter) Send(payload alerts.Payload) error {
+func (a *Alerter) Send(ctx context.Context, payload alerts.Payload) error {
+	if a.clientErr != nil {
+		return fmt.Errorf("invalid client config: %w", a.clientErr)
+	}
 	if a.config.WebhookURL == "" {
 		return fmt.Errorf("discord webhook URL is empty")
 	}
@@ -59,7 +82,7 @@ func (a *Alerter) Send(payload alerts.Payload) error {
 		return fmt.Errorf("failed to marshal discord message: %w", err)
 	}
 
-	resp, err := a.client.Post(a.config.WebhookURL, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := outbound.PostJSON(ctx, a.client, a.config.WebhookURL, jsonData)
 	if err != nil {
 		return fmt.Errorf("failed to send discord webhook: %w", err)
 	}
@@ -81,8 +104,10 @@ func (a *Alerter) buildMessage(payload alerts.Payload) map[string]interface{} {
 		"color":       color,
 		"timestamp":   payload.Timestamp.Format(time.RFC3339),
 		"fields": []map[string]interface{}{
-			{"name": "Service", "value": payload.ServiceName, "inline": true},
+			{"name": "Service", "value": payload.ServiceLabel(), "inline": true},
 			{"name": "Level", "value": payload.Level, "inline": true},
+			{"name": "Environment", "value": defaultIfEmpty(payload.Environment, "N/A"), "inline": true},
+			{"name": "Host", "value": defaultIfEmpty(payload.Hostname, "N/A"), "inline": true},
 			{"name": "Method", "value": payload.Method, "inline": true},
 			{"name": "Path", "value": payload.Path, "inline": false},
 			{"name": "Client IP", "value": defaultIfEmpty(payload.IP, "N/A"), "inline": true},
@@ -94,6 +119,27 @@ func (a *Alerter) buildMessage(payload alerts.Payload) map[string]interface{} {
 		},
 	}
 
+	if len(payload.Links) > 0 {
+		var links []string
+		for _, link := range payload.Links {
+			links = append(links, fmt.Sprintf("[%s](%s)", link.Name, link.URL))
+		}
+		embed["fields"] = append(embed["fields"].([]map[string]interface{}),
+			map[string]interface{}{"name": "Links", "value": truncate(strings.Join(links, " • "), 1024), "inline": false},
+		)
+	}
+
+	// Discord rejects embeds with more than 25 fields; keep one slot for the stack
+	for _, f := range payload.Fields {
+		fields := embed["fields"].([]map[string]interface{})
+		if len(fields) >= 24 {
+			break
+		}
+		embed["fields"] = append(fields,
+			map[string]interface{}{"name": truncate(f.Key, 256), "value": truncate(defaultIfEmpty(f.Value, "N/A"), 1024), "inline": true},
+		)
+	}
+
 	if len(payload.Stack) > 0 {
 		stackStr := "```\n"
 		for _, frame := range payload.Stack {
@@ -109,6 +155,15 @@ func (a *Alerter) buildMessage(payload alerts.Payload) map[string]interface{} {
 		"embeds": []map[string]interface{}{embed},
 	}
 
+	// a second embed has room for 4096 characters, more than a field
+	if payload.Excerpt != "" {
+		message["embeds"] = append(message["embeds"].([]map[string]interface{}), map[string]interface{}{
+			"title":       "Log Excerpt",
+			"description": "```\n" + payload.ExcerptTail(4000) + "\n```",
+			"color":       color,
+		})
+	}
+
 	if a.config.Username != "" {
 		message["username"] = a.config.Username
 	}
@@ -121,6 +176,7 @@ func (a *Alerter) buildMessage(payload alerts.Payload) map[string]interface{} {
 
 func (a *Alerter) getLevelColor(level string) int {
 	colors := map[string]int{
+		"FATAL":    0x6F42C1,
 		"CRITICAL": 0xDC3545,
 		"ERROR":    0xFD7E14,
 		"WARN":     0xFFC107,
@@ -2,45 +2,109 @@ package email
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"fmt"
 	"html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
 	"net/smtp"
+	"net/textproto"
 	"strings"
+	texttemplate "text/template"
+	"time"
 
 	"github.com/ahmadsaubani/go-logging-lib/alerts"
+	"github.com/ahmadsaubani/go-logging-lib/outbound"
 )
 
 type Config struct {
-	Enabled    bool     `yaml:"enabled"`
-	SMTPHost   string   `yaml:"smtp_host"`
-	SMTPPort   int      `yaml:"smtp_port"`
-	Username   string   `yaml:"username"`
-	Password   string   `yaml:"password"`
-	From       string   `yaml:"from"`
-	To         []string `yaml:"to"`
-	UseTLS     bool     `yaml:"use_tls"`
-	SkipVerify bool     `yaml:"skip_verify"`
+	Enabled    bool                `yaml:"enabled"`
+	SMTPHost   string              `yaml:"smtp_host"`
+	SMTPPort   int                 `yaml:"smtp_port"`
+	Username   string              `yaml:"username"`
+	Password   string              `yaml:"password"`
+	From       string              `yaml:"from"`
+	To         []string            `yaml:"to"`
+	UseTLS     bool                `yaml:"use_tls"`
+	StartTLS   string              `yaml:"starttls"`
+	SkipVerify bool                `yaml:"skip_verify"`
+	TLS        *outbound.TLSConfig `yaml:"tls,omitempty"`
+	TimeoutSec int                 `yaml:"timeout_sec"`
+	Retries    int                 `yaml:"retries"`
+
+	// Provider selects the transport: smtp (default), sendgrid, mailgun or ses
+	Provider        string `yaml:"provider"`
+	APIKey          string `yaml:"api_key"`
+	APIURL          string `yaml:"api_url"`
+	Domain          string `yaml:"domain"`
+	Region          string `yaml:"region"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	Proxy           string `yaml:"proxy"`
+
+	// TemplateFile and TextTemplateFile replace the built-in templates (see TemplateData)
+	TemplateFile     string `yaml:"template_file"`
+	TextTemplateFile string `yaml:"text_template_file"`
+}
+
+type message struct {
+	subject     string
+	html        string
+	text        string
+	attachments []attachment
+}
+
+type attachment struct {
+	filename string
+	data     []byte
 }
 
 type Alerter struct {
-	config   *Config
-	template *template.Template
+	config       *Config
+	template     *template.Template
+	textTemplate *texttemplate.Template
+	client       *http.Client
+	clientErr    error
 }
 
 /**
  * New creates a new Email alerter instance.
- * Uses SMTP to send HTML-formatted emails with professional template.
- * Supports both plain SMTP and TLS connections.
+ * Uses SMTP to send HTML-formatted emails with professional template and a
+ * plaintext alternative for text-only clients; either template can be
+ * replaced by a file.
+ * Supports both plain SMTP and TLS connections, or the SendGrid, Mailgun and
+ * Amazon SES HTTPS APIs where outbound SMTP is blocked.
  *
  * @param config SMTP configuration including host, credentials, and recipients
  * @return *Alerter Ready-to-use Email alerter
  */
 func New(config *Config) *Alerter {
-	tmpl := template.Must(template.New("email").Parse(htmlTemplate))
+	// logging.New rejects broken template files via CheckTemplates; used on its
+	// own, the alerter falls back to the built-in templates
+	tmpl, textTmpl, err := loadTemplates(config)
+	if err != nil {
+		tmpl, textTmpl = builtinHTML, builtinText
+	}
+
+	// only the API providers use the HTTP client
+	client, err := outbound.NewHTTPClient(outbound.ClientOptions{
+		TLS:     config.TLS,
+		Proxy:   config.Proxy,
+		Timeout: alerts.Timeout(config.TimeoutSec),
+	})
+
 	return &Alerter{
-		config:   config,
-		template: tmpl,
+		config:       config,
+		template:     tmpl,
+		textTemplate: textTmpl,
+		client:       client,
+		clientErr:    err,
 	}
 }
 
@@ -48,75 +112,238 @@ func (a *Alerter) Name() string {
 	return "Email"
 }
 
+func (a *Alerter) Retries() int {
+	return a.config.Retries
+}
+
 /**
- * Send dispatches an alert via SMTP email.
+ * Send dispatches an alert via SMTP or the configured email API.
  * Renders HTML template with error details and sends to all configured recipients.
  * Automatically handles TLS if configured.
  *
+ * @param ctx Cancels the send, e.g. on shutdown
  * @param payload Alert data containing error details and request metadata
  * @return error Returns nil on success, or error if SMTP fails
  */
-func (a *Alerter) Send(payload alerts.Payload) error {
-	if a.config.SMTPHost == "" || len(a.config.To) == 0 {
-		return fmt.Errorf("email SMTP host or recipients is empty")
+func (a *Alerter) Send(ctx context.Context, payload alerts.Payload) error {
+	if len(a.config.To) == 0 {
+		return fmt.Errorf("email recipients is empty")
 	}
 
-	subject := fmt.Sprintf("[%s] %s - %s", payload.Level, payload.ServiceName, truncate(payload.Error, 50))
+	service := payload.ServiceName
+	if payload.Environment != "" {
+		service += " (" + payload.Environment + ")"
+	}
+	subject := fmt.Sprintf("[%s] %s - %s", payload.Level, service, truncate(payload.Error, 50))
 
-	body, err := a.renderTemplate(payload)
+	htmlBody, textBody, err := a.renderTemplate(payload)
 	if err != nil {
 		return fmt.Errorf("failed to render email template: %w", err)
 	}
 
-	message := a.buildMessage(subject, body)
-	addr := fmt.Sprintf("%s:%d", a.config.SMTPHost, a.config.SMTPPort)
-	auth := a.getAuth()
+	msg := message{subject: subject, html: htmlBody, text: textBody}
+	if payload.Excerpt != "" {
+		msg.attachments = []attachment{{filename: "log-excerpt.txt", data: []byte(payload.Excerpt)}}
+	}
+	return a.send(ctx, msg)
+}
 
-	if a.config.UseTLS {
-		return a.sendWithTLS(addr, auth, message)
+/**
+ * SendMessage emails already rendered bodies to the configured recipients,
+ * for messages other than alerts such as periodic reports. With a textBody
+ * the message is multipart/alternative, otherwise HTML only.
+ *
+ * @param ctx Cancels the send
+ * @param subject Subject line
+ * @param htmlBody HTML document
+ * @param textBody Plaintext rendering of the same content, may be empty
+ * @return error Returns nil on success, or error if delivery fails
+ */
+func (a *Alerter) SendMessage(ctx context.Context, subject, htmlBody, textBody string) error {
+	if len(a.config.To) == 0 {
+		return fmt.Errorf("email recipients is empty")
+	}
+	return a.send(ctx, message{subject: subject, html: htmlBody, text: textBody})
+}
+
+func (a *Alerter) send(ctx context.Context, msg message) error {
+	provider := strings.ToLower(a.config.Provider)
+	if provider != "" && provider != "smtp" && a.clientErr != nil {
+		return fmt.Errorf("invalid client config: %w", a.clientErr)
 	}
 
-	return smtp.SendMail(addr, auth, a.config.From, a.config.To, []byte(message))
+	switch provider {
+	case "", "smtp":
+		return a.sendSMTP(ctx, msg)
+	case "sendgrid":
+		return a.sendSendGrid(ctx, msg)
+	case "mailgun":
+		return a.sendMailgun(ctx, msg)
+	case "ses":
+		return a.sendSES(ctx, msg)
+	}
+	return fmt.Errorf("unsupported email provider %q", a.config.Provider)
 }
 
-func (a *Alerter) renderTemplate(payload alerts.Payload) (string, error) {
-	data := templateData{
-		LevelColor:  getLevelColor(payload.Level),
-		MethodColor: getMethodColor(payload.Method),
-		Level:       payload.Level,
-		ServiceName: payload.ServiceName,
-		Timestamp:   payload.Timestamp.Format("02 Jan 2006, 15:04:05"),
-		Error:       payload.Error,
-		Method:      payload.Method,
-		Path:        payload.Path,
-		IP:          defaultIfEmpty(payload.IP, "N/A"),
-		Source:      fmt.Sprintf("%s:%d", payload.File, payload.Line),
-		RequestID:   defaultIfEmpty(payload.RequestID, "N/A"),
-		UserAgent:   defaultIfEmpty(payload.UserAgent, "N/A"),
-		Stack:       payload.Stack,
-		Year:        payload.Timestamp.Year(),
+func (a *Alerter) sendSMTP(ctx context.Context, msg message) error {
+	if a.config.SMTPHost == "" {
+		return fmt.Errorf("email SMTP host is empty")
 	}
 
-	var buf bytes.Buffer
-	if err := a.template.Execute(&buf, data); err != nil {
+	raw, err := a.buildMessage(msg)
+	if err != nil {
+		return fmt.Errorf("failed to build email message: %w", err)
+	}
+
+	port := a.config.SMTPPort
+	if port == 0 {
+		port = 587
+		if a.config.UseTLS {
+			port = 465
+		}
+	}
+	addr := fmt.Sprintf("%s:%d", a.config.SMTPHost, port)
+	auth := a.getAuth()
+
+	return a.deliver(ctx, addr, auth, raw)
+}
+
+/**
+ * renderTemplate renders both bodies, falling back to the built-in template
+ * when a custom one fails so the alert still goes out.
+ */
+func (a *Alerter) renderTemplate(payload alerts.Payload) (string, string, error) {
+	data := newTemplateData(payload)
+
+	var htmlBuf, textBuf bytes.Buffer
+	if err := a.template.Execute(&htmlBuf, data); err != nil {
+		htmlBuf.Reset()
+		if err := builtinHTML.Execute(&htmlBuf, data); err != nil {
+			return "", "", err
+		}
+	}
+	if err := a.textTemplate.Execute(&textBuf, data); err != nil {
+		textBuf.Reset()
+		if err := builtinText.Execute(&textBuf, data); err != nil {
+			return "", "", err
+		}
+	}
+
+	return htmlBuf.String(), textBuf.String(), nil
+}
+
+/**
+ * buildMessage assembles the MIME message. Bodies are quoted-printable encoded
+ * so long lines and non-ASCII text survive SMTP relays; with a text body the
+ * plaintext part comes first, as clients show the last part they support.
+ * Attachments wrap the body in multipart/mixed.
+ */
+func (a *Alerter) buildMessage(msg message) (string, error) {
+	var out strings.Builder
+
+	out.WriteString(fmt.Sprintf("From: %s\r\n", a.config.From))
+	out.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(a.config.To, ", ")))
+	out.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.subject)))
+	out.WriteString(fmt.Sprintf("Date: %s\r\n", time.Now().Format(time.RFC1123Z)))
+	out.WriteString("MIME-Version: 1.0\r\n")
+
+	header, body, err := buildBody(msg.html, msg.text)
+	if err != nil {
 		return "", err
 	}
 
-	return buf.String(), nil
+	if len(msg.attachments) > 0 {
+		var mixed bytes.Buffer
+		parts := multipart.NewWriter(&mixed)
+		w, err := parts.CreatePart(header)
+		if err != nil {
+			return "", err
+		}
+		w.Write(body)
+
+		for _, att := range msg.attachments {
+			w, err := parts.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {mime.FormatMediaType("text/plain", map[string]string{"charset": "UTF-8", "name": att.filename})},
+				"Content-Transfer-Encoding": {"base64"},
+				"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": att.filename})},
+			})
+			if err != nil {
+				return "", err
+			}
+			writeBase64Lines(w, att.data)
+		}
+		if err := parts.Close(); err != nil {
+			return "", err
+		}
+		header, body = textproto.MIMEHeader{"Content-Type": {"multipart/mixed; boundary=" + parts.Boundary()}}, mixed.Bytes()
+	}
+
+	out.WriteString(fmt.Sprintf("Content-Type: %s\r\n", header.Get("Content-Type")))
+	if encoding := header.Get("Content-Transfer-Encoding"); encoding != "" {
+		out.WriteString(fmt.Sprintf("Content-Transfer-Encoding: %s\r\n", encoding))
+	}
+	out.WriteString("\r\n")
+	out.Write(body)
+
+	return out.String(), nil
 }
 
-func (a *Alerter) buildMessage(subject, body string) string {
-	var msg strings.Builder
+/**
+ * buildBody returns the headers and encoded content of the message body:
+ * quoted-printable HTML, or multipart/alternative with a text part.
+ */
+func buildBody(htmlBody, textBody string) (textproto.MIMEHeader, []byte, error) {
+	var body bytes.Buffer
+
+	if textBody == "" {
+		if err := writeQuotedPrintable(&body, htmlBody); err != nil {
+			return nil, nil, err
+		}
+		return textproto.MIMEHeader{
+			"Content-Type":              {"text/html; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		}, body.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", textBody},
+		{"text/html; charset=UTF-8", htmlBody},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := writeQuotedPrintable(w, part.content); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	return textproto.MIMEHeader{"Content-Type": {"multipart/alternative; boundary=" + parts.Boundary()}}, body.Bytes(), nil
+}
 
-	msg.WriteString(fmt.Sprintf("From: %s\r\n", a.config.From))
-	msg.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(a.config.To, ", ")))
-	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
-	msg.WriteString("MIME-Version: 1.0\r\n")
-	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
-	msg.WriteString("\r\n")
-	msg.WriteString(body)
+// writeBase64Lines writes data base64 encoded in lines of 76 characters as MIME requires
+func writeBase64Lines(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		io.WriteString(w, encoded[:76]+"\r\n")
+		encoded = encoded[76:]
+	}
+	io.WriteString(w, encoded)
+}
 
-	return msg.String()
+func writeQuotedPrintable(w io.Writer, s string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(s)); err != nil {
+		return err
+	}
+	return qp.Close()
 }
 
 func (a *Alerter) getAuth() smtp.Auth {
@@ -126,17 +353,78 @@ func (a *Alerter) getAuth() smtp.Auth {
 	return nil
 }
 
-func (a *Alerter) sendWithTLS(addr string, auth smtp.Auth, message string) error {
-	tlsConfig := &tls.Config{
-		InsecureSkipVerify: a.config.SkipVerify,
-		ServerName:         a.config.SMTPHost,
+/**
+ * tlsConfig merges the TLS settings with the legacy SkipVerify flag and
+ * verifies against SMTPHost unless ServerName is set.
+ */
+func (a *Alerter) tlsConfig() (*tls.Config, error) {
+	cfg, err := a.config.TLS.Build()
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS config: %w", err)
+	}
+	if cfg == nil {
+		cfg = &tls.Config{}
 	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = a.config.SMTPHost
+	}
+	if a.config.SkipVerify {
+		cfg.InsecureSkipVerify = true
+	}
+	return cfg, nil
+}
+
+/**
+ * startTLSPolicy returns how a plain connection is upgraded: "required",
+ * "disabled" or "opportunistic". The default is opportunistic, or required
+ * when TLS settings are given.
+ */
+func (a *Alerter) startTLSPolicy() (string, error) {
+	switch policy := strings.ToLower(a.config.StartTLS); policy {
+	case "":
+		if a.config.TLS != nil {
+			return "required", nil
+		}
+		return "opportunistic", nil
+	case "required", "opportunistic", "disabled":
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown starttls policy %q", a.config.StartTLS)
+}
 
-	conn, err := tls.Dial("tcp", addr, tlsConfig)
+/**
+ * deliver runs one SMTP session bounded by the configured timeout and ctx,
+ * which closes the connection when cancelled mid-session. It connects
+ * with implicit TLS (UseTLS, usually port 465) or plain TCP; a plain
+ * connection (usually port 587) is upgraded with STARTTLS according to the
+ * StartTLS policy, verifying the server certificate against the TLS settings.
+ */
+func (a *Alerter) deliver(ctx context.Context, addr string, auth smtp.Auth, message string) error {
+	tlsConfig, err := a.tlsConfig()
+	if err != nil {
+		return err
+	}
+	policy, err := a.startTLSPolicy()
+	if err != nil {
+		return err
+	}
+
+	timeout := alerts.Timeout(a.config.TimeoutSec)
+	dialer := &net.Dialer{Timeout: timeout}
+
+	var conn net.Conn
+	if a.config.UseTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to connect to SMTP server: %w", err)
 	}
 	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
 
 	client, err := smtp.NewClient(conn, a.config.SMTPHost)
 	if err != nil {
@@ -144,6 +432,18 @@ func (a *Alerter) sendWithTLS(addr string, auth smtp.Auth, message string) error
 	}
 	defer client.Close()
 
+	if !a.config.UseTLS && policy != "disabled" {
+		offered, _ := client.Extension("STARTTLS")
+		if !offered && policy == "required" {
+			return fmt.Errorf("SMTP server does not offer STARTTLS")
+		}
+		if offered {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("SMTP STARTTLS failed: %w", err)
+			}
+		}
+	}
+
 	if auth != nil {
 		if err := client.Auth(auth); err != nil {
 			return fmt.Errorf("SMTP auth failed: %w", err)
@@ -178,6 +478,7 @@ func (a *Alerter) sendWithTLS(addr string, auth smtp.Auth, message string) error
 
 func getLevelColor(level string) string {
 	colors := map[string]string{
+		"FATAL":    "#a855f7",
 		"CRITICAL": "#ef4444",
 		"ERROR":    "#f97316",
 		"WARN":     "#eab308",
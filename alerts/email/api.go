@@ -0,0 +1,128 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	defaultSendGridURL = "https://api.sendgrid.com/v3/mail/send"
+	defaultMailgunURL  = "https://api.mailgun.net"
+)
+
+/**
+ * sendSendGrid sends the message through the SendGrid v3 mail send API.
+ */
+func (a *Alerter) sendSendGrid(ctx context.Context, msg message) error {
+	if a.config.APIKey == "" {
+		return fmt.Errorf("sendgrid API key is empty")
+	}
+
+	var to []map[string]string
+	for _, addr := range a.config.To {
+		to = append(to, map[string]string{"email": addr})
+	}
+	var content []map[string]string
+	if msg.text != "" {
+		content = append(content, map[string]string{"type": "text/plain", "value": msg.text})
+	}
+	content = append(content, map[string]string{"type": "text/html", "value": msg.html})
+
+	request := map[string]interface{}{
+		"personalizations": []map[string]interface{}{{"to": to}},
+		"from":             map[string]string{"email": a.config.From},
+		"subject":          msg.subject,
+		"content":          content,
+	}
+	if len(msg.attachments) > 0 {
+		var attachments []map[string]string
+		for _, att := range msg.attachments {
+			attachments = append(attachments, map[string]string{
+				"content":     base64.StdEncoding.EncodeToString(att.data),
+				"filename":    att.filename,
+				"type":        "text/plain",
+				"disposition": "attachment",
+			})
+		}
+		request["attachments"] = attachments
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sendgrid message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, defaultIfEmpty(a.config.APIURL, defaultSendGridURL), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+a.config.APIKey)
+
+	return a.doAPI(req, "sendgrid")
+}
+
+/**
+ * sendMailgun sends the message through the Mailgun messages API as a
+ * multipart form, which also carries attachments. APIURL selects the
+ * region, e.g. https://api.eu.mailgun.net.
+ */
+func (a *Alerter) sendMailgun(ctx context.Context, msg message) error {
+	if a.config.APIKey == "" || a.config.Domain == "" {
+		return fmt.Errorf("mailgun API key or domain is empty")
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("from", a.config.From)
+	for _, addr := range a.config.To {
+		form.WriteField("to", addr)
+	}
+	form.WriteField("subject", msg.subject)
+	form.WriteField("html", msg.html)
+	if msg.text != "" {
+		form.WriteField("text", msg.text)
+	}
+	for _, att := range msg.attachments {
+		w, err := form.CreateFormFile("attachment", att.filename)
+		if err != nil {
+			return err
+		}
+		w.Write(att.data)
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/v3/%s/messages", strings.TrimSuffix(defaultIfEmpty(a.config.APIURL, defaultMailgunURL), "/"), url.PathEscape(a.config.Domain))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.SetBasicAuth("api", a.config.APIKey)
+
+	return a.doAPI(req, "mailgun")
+}
+
+func (a *Alerter) doAPI(req *http.Request, provider string) error {
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s API: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s API returned status %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+/**
+ * sendSES sends the message through the Amazon SES v2 SendEmail API, signed
+ * with AWS Signature Version 4. Messages with attachments are sent as raw
+ * MIME, the only SES content type that supports them. Credentials fall back to AWS_ACCESS_KEY_ID,
+ * AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN, the region to AWS_REGION.
+ */
+func (a *Alerter) sendSES(ctx context.Context, msg message) error {
+	region := defaultIfEmpty(a.config.Region, os.Getenv("AWS_REGION"))
+	accessKey := defaultIfEmpty(a.config.AccessKeyID, os.Getenv("AWS_ACCESS_KEY_ID"))
+	secretKey := defaultIfEmpty(a.config.SecretAccessKey, os.Getenv("AWS_SECRET_ACCESS_KEY"))
+	if region == "" || accessKey == "" || secretKey == "" {
+		return fmt.Errorf("ses region or credentials are empty")
+	}
+
+	var content map[string]interface{}
+	if len(msg.attachments) > 0 {
+		raw, err := a.buildMessage(msg)
+		if err != nil {
+			return fmt.Errorf("failed to build email message: %w", err)
+		}
+		// []byte is encoded as base64, as the API expects
+		content = map[string]interface{}{"Raw": map[string][]byte{"Data": []byte(raw)}}
+	} else {
+		simple := map[string]interface{}{
+			"Subject": map[string]string{"Data": msg.subject, "Charset": "UTF-8"},
+			"Body": map[string]interface{}{
+				"Html": map[string]string{"Data": msg.html, "Charset": "UTF-8"},
+			},
+		}
+		if msg.text != "" {
+			simple["Body"].(map[string]interface{})["Text"] = map[string]string{"Data": msg.text, "Charset": "UTF-8"}
+		}
+		content = map[string]interface{}{"Simple": simple}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"FromEmailAddress": a.config.From,
+		"Destination":      map[string]interface{}{"ToAddresses": a.config.To},
+		"Content":          content,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal ses message: %w", err)
+	}
+
+	endpoint := defaultIfEmpty(a.config.APIURL, fmt.Sprintf("https://email.%s.amazonaws.com", region))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" && a.config.AccessKeyID == "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+	signV4(req, body, accessKey, secretKey, region, "ses", time.Now())
+
+	return a.doAPI(req, "ses")
+}
+
+/**
+ * signV4 adds an AWS Signature Version 4 Authorization header covering the
+ * host, x-amz-* headers and content type of req.
+ */
+func signV4(req *http.Request, body []byte, accessKey, secretKey, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method, path, req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -1,5 +1,16 @@
 package email
 
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+)
+
 const htmlTemplate = `<!DOCTYPE html>
 <html>
 <head>
@@ -52,7 +63,7 @@ const htmlTemplate = `<!DOCTYPE html>
 <tr>
 <td width="50%" style="padding:12px 0;vertical-align:top;">
 <p style="margin:0 0 4px 0;font-size:12px;color:#999;">Service</p>
-<p style="margin:0;font-size:14px;color:#333;font-weight:500;">{{.ServiceName}}</p>
+<p style="margin:0;font-size:14px;color:#333;font-weight:500;">{{.Service}}</p>
 </td>
 <td width="50%" style="padding:12px 0;vertical-align:top;">
 <p style="margin:0 0 4px 0;font-size:12px;color:#999;">Method</p>
@@ -60,6 +71,16 @@ const htmlTemplate = `<!DOCTYPE html>
 </td>
 </tr>
 <tr>
+<td width="50%" style="padding:12px 0;border-top:1px solid #f0f0f0;vertical-align:top;">
+<p style="margin:0 0 4px 0;font-size:12px;color:#999;">Environment</p>
+<p style="margin:0;font-size:14px;color:#333;">{{.Environment}}</p>
+</td>
+<td width="50%" style="padding:12px 0;border-top:1px solid #f0f0f0;vertical-align:top;">
+<p style="margin:0 0 4px 0;font-size:12px;color:#999;">Host</p>
+<p style="margin:0;font-size:14px;color:#333;font-family:'Courier New',monospace;">{{.Hostname}}</p>
+</td>
+</tr>
+<tr>
 <td colspan="2" style="padding:12px 0;border-top:1px solid #f0f0f0;">
 <p style="margin:0 0 4px 0;font-size:12px;color:#999;">Path</p>
 <p style="margin:0;font-size:14px;color:#333;font-family:'Courier New',monospace;word-break:break-all;">{{.Path}}</p>
@@ -91,6 +112,30 @@ const htmlTemplate = `<!DOCTYPE html>
 </td>
 </tr>
 
+{{if .Links}}
+<tr>
+<td style="padding:24px 40px;border-bottom:1px solid #e9ecef;">
+{{range .Links}}<a href="{{.URL}}" style="display:inline-block;margin:0 8px 8px 0;background:#3b82f6;color:#fff;padding:8px 16px;border-radius:4px;font-size:13px;font-weight:600;text-decoration:none;">View in {{.Name}}</a>{{end}}
+</td>
+</tr>
+{{end}}
+
+{{if .Fields}}
+<tr>
+<td style="padding:32px 40px;border-bottom:1px solid #e9ecef;">
+<p style="margin:0 0 20px 0;font-size:11px;text-transform:uppercase;letter-spacing:1px;color:#999;font-weight:600;">Additional Details</p>
+<table width="100%" cellpadding="0" cellspacing="0">
+{{range .Fields}}
+<tr>
+<td width="35%" style="padding:8px 0;border-top:1px solid #f0f0f0;font-size:12px;color:#999;vertical-align:top;">{{.Key}}</td>
+<td style="padding:8px 0;border-top:1px solid #f0f0f0;font-size:14px;color:#333;word-break:break-all;">{{.Value}}</td>
+</tr>
+{{end}}
+</table>
+</td>
+</tr>
+{{end}}
+
 <tr>
 <td style="padding:32px 40px;">
 <p style="margin:0 0 16px 0;font-size:11px;text-transform:uppercase;letter-spacing:1px;color:#999;font-weight:600;">Stack Trace</p>
@@ -127,11 +172,55 @@ const htmlTemplate = `<!DOCTYPE html>
 </body>
 </html>`
 
-type templateData struct {
+const textTemplate = `{{.Level}} ALERT - {{.ServiceName}}
+Time: {{.Timestamp}}
+
+Error:
+{{.Error}}
+
+Request Details
+  Service:     {{.Service}}
+  Environment: {{.Environment}}
+  Host:        {{.Hostname}}
+  Method:      {{.Method}}
+  Path:        {{.Path}}
+  Client IP:   {{.IP}}
+  Source:      {{.Source}}
+  Request ID:  {{.RequestID}}
+  User Agent:  {{.UserAgent}}
+{{if .Links}}
+Links
+{{range .Links}}  {{.Name}}: {{.URL}}
+{{end}}{{end}}{{if .Fields}}
+Additional Details
+{{range .Fields}}  {{.Key}}: {{.Value}}
+{{end}}{{end}}
+Stack Trace
+{{range .Stack}}  {{.}}
+{{else}}  No stack trace available
+{{end}}
+--
+Sent by Go Logging Library. This is an automated alert notification.
+`
+
+var (
+	builtinHTML = template.Must(template.New("email").Parse(htmlTemplate))
+	builtinText = texttemplate.Must(texttemplate.New("email").Parse(textTemplate))
+)
+
+/**
+ * TemplateData is what the HTML and plaintext templates render, built-in or
+ * from TemplateFile / TextTemplateFile. Missing optional values are "N/A";
+ * Payload gives access to the raw alert, e.g. {{.Payload.Timestamp}}.
+ */
+type TemplateData struct {
 	LevelColor  string
 	MethodColor string
 	Level       string
 	ServiceName string
+	Service     string
+	Environment string
+	Hostname    string
 	Timestamp   string
 	Error       string
 	Method      string
@@ -140,6 +229,98 @@ type templateData struct {
 	Source      string
 	RequestID   string
 	UserAgent   string
+	Fields      []alerts.Field
+	Links       []alerts.Link
 	Stack       []string
 	Year        int
+	Payload     alerts.Payload
+}
+
+/**
+ * loadTemplates parses the configured template files, using the built-in
+ * template for each one that is not set.
+ */
+func loadTemplates(config *Config) (*template.Template, *texttemplate.Template, error) {
+	htmlTmpl, textTmpl := builtinHTML, builtinText
+
+	if config.TemplateFile != "" {
+		content, err := os.ReadFile(config.TemplateFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read email template: %w", err)
+		}
+		if htmlTmpl, err = template.New("email").Parse(string(content)); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse email template: %w", err)
+		}
+	}
+
+	if config.TextTemplateFile != "" {
+		content, err := os.ReadFile(config.TextTemplateFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read email text template: %w", err)
+		}
+		if textTmpl, err = texttemplate.New("email").Parse(string(content)); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse email text template: %w", err)
+		}
+	}
+
+	return htmlTmpl, textTmpl, nil
+}
+
+/**
+ * CheckTemplates loads TemplateFile and TextTemplateFile and renders them
+ * with sample data, so a missing file or a reference to an unknown field is
+ * reported at startup instead of on the first alert.
+ *
+ * @param config Email configuration with the template paths
+ * @return error Error if a template cannot be read, parsed or rendered
+ */
+func CheckTemplates(config *Config) error {
+	htmlTmpl, textTmpl, err := loadTemplates(config)
+	if err != nil {
+		return err
+	}
+
+	sample := newTemplateData(alerts.Payload{
+		ServiceName: "sample-service",
+		Level:       string(alerts.LevelError),
+		Error:       "sample error",
+		Method:      "GET",
+		Path:        "/sample",
+		Stack:       []string{"main.go:1 main"},
+		Fields:      []alerts.Field{{Key: "key", Value: "value"}},
+		Links:       []alerts.Link{{Name: "Logs", URL: "https://example.com"}},
+		Timestamp:   time.Now(),
+	})
+	if err := htmlTmpl.Execute(io.Discard, sample); err != nil {
+		return fmt.Errorf("failed to render email template: %w", err)
+	}
+	if err := textTmpl.Execute(io.Discard, sample); err != nil {
+		return fmt.Errorf("failed to render email text template: %w", err)
+	}
+	return nil
+}
+
+func newTemplateData(payload alerts.Payload) TemplateData {
+	return TemplateData{
+		LevelColor:  getLevelColor(payload.Level),
+		MethodColor: getMethodColor(payload.Method),
+		Level:       payload.Level,
+		ServiceName: payload.ServiceName,
+		Service:     payload.ServiceLabel(),
+		Environment: defaultIfEmpty(payload.Environment, "N/A"),
+		Hostname:    defaultIfEmpty(payload.Hostname, "N/A"),
+		Timestamp:   payload.Timestamp.Format("02 Jan 2006, 15:04:05"),
+		Error:       payload.Error,
+		Method:      payload.Method,
+		Path:        payload.Path,
+		IP:          defaultIfEmpty(payload.IP, "N/A"),
+		Source:      fmt.Sprintf("%s:%d", payload.File, payload.Line),
+		RequestID:   defaultIfEmpty(payload.RequestID, "N/A"),
+		UserAgent:   defaultIfEmpty(payload.UserAgent, "N/A"),
+		Fields:      payload.Fields,
+		Links:       payload.Links,
+		Stack:       payload.Stack,
+		Year:        payload.Timestamp.Year(),
+		Payload:     payload,
+	}
 }
@@ -0,0 +1,66 @@
+package alerts
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/**
+ * LinkTemplate is a deep link added to every alert, e.g. a Grafana Explore
+ * query, a Kibana search or a Sentry issue search. URL may contain
+ * {service}, {environment}, {request_id}, {timestamp} (RFC 3339, UTC),
+ * {timestamp_ms}, {from_ms} and {to_ms} (five minutes either side of the
+ * alert), which are replaced with query-escaped values.
+ */
+type LinkTemplate struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+/**
+ * Link is a rendered deep link that providers show as a button or hyperlink.
+ */
+type Link struct {
+	Name string
+	URL  string
+}
+
+const linkWindow = 5 * time.Minute
+
+/**
+ * ExpandLink fills the placeholders of a LinkTemplate URL from payload. It
+ * returns "" when the template needs a request ID the payload does not have,
+ * since such a link would not lead anywhere useful.
+ *
+ * @param template URL with placeholders
+ * @param payload Alert the link is for
+ * @return string Expanded URL, or "" if it cannot be built
+ */
+func ExpandLink(template string, payload Payload) string {
+	if payload.RequestID == "" && strings.Contains(template, "{request_id}") {
+		return ""
+	}
+
+	ts := payload.Timestamp
+	return strings.NewReplacer(
+		"{service}", url.QueryEscape(payload.ServiceName),
+		"{environment}", url.QueryEscape(payload.Environment),
+		"{request_id}", url.QueryEscape(payload.RequestID),
+		"{timestamp}", url.QueryEscape(ts.UTC().Format(time.RFC3339)),
+		"{timestamp_ms}", strconv.FormatInt(ts.UnixMilli(), 10),
+		"{from_ms}", strconv.FormatInt(ts.Add(-linkWindow).UnixMilli(), 10),
+		"{to_ms}", strconv.FormatInt(ts.Add(linkWindow).UnixMilli(), 10),
+	).Replace(template)
+}
+
+func (m *Manager) expandLinks(payload Payload) []Link {
+	links := payload.Links
+	for _, t := range m.config.Links {
+		if u := ExpandLink(t.URL, payload); u != "" {
+			links = append(links, Link{Name: t.Name, URL: u})
+		}
+	}
+	return links
+}
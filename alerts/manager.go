@@ -1,23 +1,77 @@
 package alerts
 
 import (
+	"context"
 	"crypto/md5"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Manager struct {
-	config    *Config
-	alerters  []Alerter
-	lastAlert map[string]time.Time
-	mu        sync.RWMutex
+	config      *Config
+	alerters    []Alerter
+	providers   map[string]*providerCounters
+	lastAlert   map[string]time.Time
+	mu          sync.RWMutex
+	sent        atomic.Uint64
+	failed      atomic.Uint64
+	rateLimited atomic.Uint64
+	onError     func(source string, err error)
+	ctx         context.Context
+	cancel      context.CancelFunc
+	inflight    sync.WaitGroup
+	pendingMu   sync.Mutex
+	pending     int
+	idle        chan struct{}
+	slots       chan struct{}
+	closed      bool
+	occurrences map[string]*occurrence
+	grouped     atomic.Uint64
+	stopSummary chan struct{}
+	summaryDone chan struct{}
+	summaryOnce sync.Once
+	stopCleanup chan struct{}
+	cleanupOnce sync.Once
+}
+
+/**
+ * Stats counts alert deliveries. Sent and Failed are per alerter, so one alert
+ * fanned out to Slack and Discord counts twice. Grouped counts repeats folded
+ * into summaries in first-occurrence mode.
+ *
+ * Providers breaks the counts down by lowercased alerter name ("slack",
+ * "email", ...); a rate-limited alert counts once for every provider it
+ * skipped. Alerters sharing a name share their counters.
+ */
+type Stats struct {
+	Sent        uint64                   `json:"sent"`
+	Failed      uint64                   `json:"failed"`
+	RateLimited uint64                   `json:"rate_limited"`
+	Grouped     uint64                   `json:"grouped"`
+	Providers   map[string]ProviderStats `json:"providers,omitempty"`
+}
+
+type ProviderStats struct {
+	Sent        uint64 `json:"sent"`
+	Failed      uint64 `json:"failed"`
+	RateLimited uint64 `json:"rate_limited"`
+}
+
+type providerCounters struct {
+	sent        atomic.Uint64
+	failed      atomic.Uint64
+	rateLimited atomic.Uint64
 }
 
 /**
  * NewManager creates a new alert manager instance.
  * The manager handles dispatching alerts to all registered providers
- * with built-in rate limiting to prevent alert spam.
+ * with built-in rate limiting to prevent alert spam. Expired rate limit
+ * entries are removed every RateLimitSec by a background goroutine until
+ * Close.
  *
  * @param config Configuration including min level and rate limit settings
  * @return *Manager A new manager instance ready for alerter registration
@@ -26,29 +80,58 @@ func NewManager(config *Config) *Manager {
 	if config.RateLimitSec <= 0 {
 		config.RateLimitSec = 300
 	}
+	if config.MaxConcurrentSends <= 0 {
+		config.MaxConcurrentSends = 10
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Manager{
+	m := &Manager{
 		config:    config,
 		alerters:  make([]Alerter, 0),
+		providers: make(map[string]*providerCounters),
 		lastAlert: make(map[string]time.Time),
+		ctx:       ctx,
+		cancel:    cancel,
+		slots:     make(chan struct{}, config.MaxConcurrentSends),
+	}
+
+	if config.FirstOccurrenceOnly {
+		m.occurrences = make(map[string]*occurrence)
+		m.stopSummary = make(chan struct{})
+		m.summaryDone = make(chan struct{})
+		go m.runSummaries()
+	} else {
+		// first-occurrence mode never fills lastAlert
+		m.stopCleanup = make(chan struct{})
+		go m.runCleanup()
 	}
+
+	return m
 }
 
 /**
  * Register adds an alerter to the manager.
  * Multiple alerters can be registered and all will receive alerts.
  * Registration is idempotent - registering the same alerter twice is safe.
+ * Alerters written against the old context-free interface go through Adapt.
  *
  * @param alerter The alerter implementation to register (Discord, Slack, etc.)
  */
 func (m *Manager) Register(alerter Alerter) {
 	m.alerters = append(m.alerters, alerter)
+
+	name := providerName(alerter)
+	if _, ok := m.providers[name]; !ok {
+		m.providers[name] = &providerCounters{}
+	}
 }
 
 /**
  * Alert sends notification to all registered alerters with rate limiting.
- * Duplicate alerts (same error, path, method) within the rate limit window
- * will be silently dropped to prevent spam.
+ * Duplicate alerts (same error, route or normalized path, method) within the rate limit window
+ * will be silently dropped to prevent spam, or counted for a summary in
+ * first-occurrence mode. Alerts after Close are dropped.
  *
  * @param payload The alert data containing error details and request metadata
  */
@@ -57,26 +140,211 @@ func (m *Manager) Alert(payload Payload) {
 		return
 	}
 
+	if m.config.FirstOccurrenceOnly {
+		if m.firstOccurrence(payload) {
+			m.dispatch(payload)
+		}
+		return
+	}
+
 	if m.isRateLimited(payload) {
+		m.rateLimited.Add(1)
+		for _, alerter := range m.alerters {
+			m.providers[providerName(alerter)].rateLimited.Add(1)
+		}
 		return
 	}
 
 	m.markAlerted(payload)
+	m.dispatch(payload)
+}
+
+/**
+ * Notify sends payload to all alerters regardless of min level, rate limiting
+ * and first-occurrence mode, for messages such as heartbeats that must always
+ * go out.
+ *
+ * @param payload The message to deliver
+ */
+func (m *Manager) Notify(payload Payload) {
+	if !m.config.Enabled {
+		return
+	}
+	m.dispatch(payload)
+}
+
+func (m *Manager) dispatch(payload Payload) {
+	payload.Links = m.expandLinks(payload)
+
+	m.mu.RLock()
+	if m.closed {
+		m.mu.RUnlock()
+		return
+	}
+	m.inflight.Add(len(m.alerters))
+	m.track(len(m.alerters))
+	m.mu.RUnlock()
 
 	for _, alerter := range m.alerters {
 		go func(a Alerter) {
-			if err := a.Send(payload); err != nil {
-				fmt.Printf("[AlertManager] failed to send %s alert: %v\n", a.Name(), err)
+			defer m.inflight.Done()
+			defer m.track(-1)
+			counters := m.providers[providerName(a)]
+			if err := m.send(m.ctx, a, payload); err != nil {
+				m.failed.Add(1)
+				counters.failed.Add(1)
+				if m.onError != nil {
+					m.onError("alerts."+providerName(a), fmt.Errorf("failed to send alert: %w", err))
+				} else {
+					fmt.Printf("[AlertManager] failed to send %s alert: %v\n", a.Name(), err)
+				}
+				return
 			}
+			m.sent.Add(1)
+			counters.sent.Add(1)
 		}(alerter)
 	}
 }
 
+/**
+ * track adjusts the number of sends in flight, opening a new idle channel
+ * when the first one starts and closing it when the last one returns.
+ */
+func (m *Manager) track(delta int) {
+	m.pendingMu.Lock()
+	defer m.pendingMu.Unlock()
+
+	if m.pending == 0 && delta > 0 {
+		m.idle = make(chan struct{})
+	}
+	m.pending += delta
+	if m.pending == 0 && m.idle != nil {
+		close(m.idle)
+		m.idle = nil
+	}
+}
+
+/**
+ * Wait blocks until no alert is being sent, without closing the manager, so
+ * short-lived runtimes (serverless handlers) can deliver alerts before they
+ * are frozen. Sends started while waiting are waited for too.
+ *
+ * @param ctx Bounds how long to wait
+ * @return error ctx.Err() if sends were still in flight when ctx was done
+ */
+func (m *Manager) Wait(ctx context.Context) error {
+	for {
+		m.pendingMu.Lock()
+		idle := m.idle
+		m.pendingMu.Unlock()
+		if idle == nil {
+			return nil
+		}
+
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+/**
+ * Close stops accepting alerts and the background cleanup, sends pending
+ * first-occurrence summaries and waits for in-flight sends until ctx is done,
+ * then cancels the remaining ones and waits for them to return. Alerts fired
+ * just before exit are delivered as long as ctx allows. Safe to call more
+ * than once.
+ *
+ * @param ctx Bounds how long pending alerts may still be delivered
+ * @return error ctx.Err() if sends had to be cancelled
+ */
+func (m *Manager) Close(ctx context.Context) error {
+	m.cleanupOnce.Do(func() {
+		if m.stopCleanup != nil {
+			close(m.stopCleanup)
+		}
+	})
+
+	if m.stopSummary != nil {
+		m.summaryOnce.Do(func() { close(m.stopSummary) })
+		<-m.summaryDone
+	}
+
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		m.cancel()
+		return nil
+	case <-ctx.Done():
+		m.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+/**
+ * Shutdown is the former name of Close.
+ *
+ * Deprecated: use Close.
+ */
+func (m *Manager) Shutdown(ctx context.Context) error {
+	return m.Close(ctx)
+}
+
+/**
+ * SetErrorHandler routes delivery failures to fn instead of printing them to
+ * stdout. Call before the first Alert.
+ *
+ * @param fn Receives the failing component ("alerts.<name>") and error
+ */
+func (m *Manager) SetErrorHandler(fn func(source string, err error)) {
+	m.onError = fn
+}
+
+/**
+ * Stats returns the delivery counters since the manager was created.
+ *
+ * @return Stats Sent, failed and rate-limited counts, in total and per provider
+ */
+func (m *Manager) Stats() Stats {
+	providers := make(map[string]ProviderStats, len(m.providers))
+	for name, c := range m.providers {
+		providers[name] = ProviderStats{
+			Sent:        c.sent.Load(),
+			Failed:      c.failed.Load(),
+			RateLimited: c.rateLimited.Load(),
+		}
+	}
+
+	return Stats{
+		Sent:        m.sent.Load(),
+		Failed:      m.failed.Load(),
+		RateLimited: m.rateLimited.Load(),
+		Grouped:     m.grouped.Load(),
+		Providers:   providers,
+	}
+}
+
+func providerName(a Alerter) string {
+	return strings.ToLower(a.Name())
+}
+
 func (m *Manager) shouldAlert(level string) bool {
 	levelPriority := map[string]int{
 		"WARN":     1,
 		"ERROR":    2,
 		"CRITICAL": 3,
+		"FATAL":    4,
 	}
 
 	minPriority := levelPriority[string(m.config.MinLevel)]
@@ -108,13 +376,37 @@ func (m *Manager) markAlerted(payload Payload) {
 }
 
 func (m *Manager) getAlertKey(payload Payload) string {
-	data := fmt.Sprintf("%s:%s:%s:%s", payload.ServiceName, payload.Error, payload.Path, payload.Method)
+	path := payload.Route
+	if path == "" {
+		normalize := m.config.PathNormalizer
+		if normalize == nil {
+			normalize = NormalizePath
+		}
+		path = normalize(payload.Path)
+	}
+
+	data := fmt.Sprintf("%s:%s:%s:%s", payload.ServiceName, payload.Error, path, payload.Method)
 	return fmt.Sprintf("%x", md5.Sum([]byte(data)))
 }
 
+func (m *Manager) runCleanup() {
+	ticker := time.NewTicker(time.Duration(m.config.RateLimitSec) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.Cleanup()
+		case <-m.stopCleanup:
+			return
+		}
+	}
+}
+
 /**
  * Cleanup removes expired rate limit entries from memory.
- * Should be called periodically to prevent memory leaks in long-running applications.
+ * The manager runs it periodically on its own; calling it directly is only
+ * needed to free memory at a specific point.
  */
 func (m *Manager) Cleanup() {
 	m.mu.Lock()
@@ -0,0 +1,211 @@
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+	"github.com/ahmadsaubani/go-logging-lib/outbound"
+)
+
+type Config struct {
+	Enabled       bool                `yaml:"enabled"`
+	HomeserverURL string              `yaml:"homeserver_url"`
+	AccessToken   string              `yaml:"access_token"`
+	RoomID        string              `yaml:"room_id"`
+	TLS           *outbound.TLSConfig `yaml:"tls,omitempty"`
+	Proxy         string              `yaml:"proxy"`
+	TimeoutSec    int                 `yaml:"timeout_sec"`
+	Retries       int                 `yaml:"retries"`
+}
+
+type Alerter struct {
+	config    *Config
+	client    *http.Client
+	clientErr error
+}
+
+/**
+ * New creates a new Matrix alerter instance.
+ * Uses the Matrix client-server API to post HTML-formatted messages to a room
+ * as the user owning AccessToken, which must have joined RoomID.
+ *
+ * @param config Homeserver URL, access token and room ID
+ * @return *Alerter Ready-to-use Matrix alerter
+ */
+func New(config *Config) *Alerter {
+	client, err := outbound.NewHTTPClient(outbound.ClientOptions{
+		TLS:     config.TLS,
+		Proxy:   config.Proxy,
+		Timeout: alerts.Timeout(config.TimeoutSec),
+	})
+
+	return &Alerter{
+		config:    config,
+		client:    client,
+		clientErr: err,
+	}
+}
+
+func (a *Alerter) Name() string {
+	return "Matrix"
+}
+
+func (a *Alerter) Retries() int {
+	return a.config.Retries
+}
+
+/**
+ * Send dispatches an alert to the Matrix room as an m.room.message event.
+ * The transaction ID is derived from the alert, so a retried send is
+ * deduplicated by the homeserver instead of posting the alert twice.
+ *
+ * @param ctx Cancels the send, e.g. on shutdown
+ * @param payload Alert data containing error details and request metadata
+ * @return error Returns nil on success, or error if the API call fails
+ */
+func (a *Alerter) Send(ctx context.Context, payload alerts.Payload) error {
+	if a.clientErr != nil {
+		return fmt.Errorf("invalid client config: %w", a.clientErr)
+	}
+	if a.config.HomeserverURL == "" || a.config.AccessToken == "" || a.config.RoomID == "" {
+		return fmt.Errorf("matrix homeserver URL, access token or room ID is empty")
+	}
+
+	jsonData, err := json.Marshal(map[string]string{
+		"msgtype":        "m.text",
+		"body":           a.buildText(payload),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": a.buildHTML(payload),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal matrix message: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimSuffix(a.config.HomeserverURL, "/"), url.PathEscape(a.config.RoomID), transactionID(payload))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+a.config.AccessToken)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send matrix message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("matrix API returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func transactionID(payload alerts.Payload) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s:%s:%s:%s",
+		payload.Timestamp.UnixNano(), payload.ServiceName, payload.Level, payload.RequestID, payload.Error)))
+	return "gologging-" + hex.EncodeToString(sum[:12])
+}
+
+/**
+ * buildHTML renders the formatted body. Element and most clients support
+ * this subset, including <details> for the collapsed log excerpt.
+ */
+func (a *Alerter) buildHTML(payload alerts.Payload) string {
+	esc := html.EscapeString
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf(`<h4>%s %s Alert</h4>`, a.getLevelEmoji(payload.Level), esc(payload.Level)))
+	sb.WriteString(fmt.Sprintf(`<p><b>%s</b></p>`, esc(payload.Error)))
+	sb.WriteString("<ul>")
+	for _, f := range a.details(payload) {
+		sb.WriteString(fmt.Sprintf("<li><b>%s:</b> %s</li>", esc(f.Key), esc(f.Value)))
+	}
+	sb.WriteString("</ul>")
+
+	if len(payload.Links) > 0 {
+		var links []string
+		for _, link := range payload.Links {
+			links = append(links, fmt.Sprintf(`<a href="%s">%s</a>`, esc(link.URL), esc(link.Name)))
+		}
+		sb.WriteString("<p>" + strings.Join(links, " | ") + "</p>")
+	}
+
+	if len(payload.Stack) > 0 {
+		sb.WriteString("<p><b>Stack Trace:</b></p><pre><code>" + esc(strings.Join(payload.Stack, "\n")) + "</code></pre>")
+	}
+	if payload.Excerpt != "" {
+		sb.WriteString("<details><summary>Log Excerpt</summary><pre><code>" + esc(payload.ExcerptTail(16000)) + "</code></pre></details>")
+	}
+
+	return sb.String()
+}
+
+// buildText renders the plain body shown by clients without HTML support and in notifications
+func (a *Alerter) buildText(payload alerts.Payload) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("%s %s Alert: %s\n", a.getLevelEmoji(payload.Level), payload.Level, payload.Error))
+	for _, f := range a.details(payload) {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", f.Key, f.Value))
+	}
+	for _, link := range payload.Links {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", link.Name, link.URL))
+	}
+	if len(payload.Stack) > 0 {
+		sb.WriteString("\nStack Trace:\n" + strings.Join(payload.Stack, "\n") + "\n")
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func (a *Alerter) details(payload alerts.Payload) []alerts.Field {
+	fields := []alerts.Field{
+		{Key: "Service", Value: payload.ServiceLabel()},
+		{Key: "Environment", Value: defaultIfEmpty(payload.Environment, "N/A")},
+		{Key: "Host", Value: defaultIfEmpty(payload.Hostname, "N/A")},
+		{Key: "Method", Value: payload.Method},
+		{Key: "Path", Value: payload.Path},
+		{Key: "Client IP", Value: defaultIfEmpty(payload.IP, "N/A")},
+		{Key: "Source", Value: fmt.Sprintf("%s:%d", payload.File, payload.Line)},
+		{Key: "Request ID", Value: defaultIfEmpty(payload.RequestID, "N/A")},
+		{Key: "Time", Value: payload.Timestamp.Format(time.RFC3339)},
+	}
+	for _, f := range payload.Fields {
+		fields = append(fields, alerts.Field{Key: f.Key, Value: defaultIfEmpty(f.Value, "N/A")})
+	}
+	return fields
+}
+
+func (a *Alerter) getLevelEmoji(level string) string {
+	emojis := map[string]string{
+		"FATAL":    "🟣",
+		"CRITICAL": "🔴",
+		"ERROR":    "🟠",
+		"WARN":     "🟡",
+	}
+	if emoji, ok := emojis[level]; ok {
+		return emoji
+	}
+	return "⚪"
+}
+
+func defaultIfEmpty(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,47 @@
+package alerts
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hexPattern  = regexp.MustCompile(`^[0-9a-fA-F]{16,}$`)
+)
+
+/**
+ * NormalizePath replaces path segments that look like identifiers with ":id",
+ * so /orders/123 and /orders/456 share one rate-limit key. Numbers, UUIDs, hex
+ * strings of 16+ characters (Mongo ObjectIDs, hashes) and 16+ character
+ * tokens mixing letters and digits (ULIDs, base62 IDs) are replaced.
+ *
+ * @param path Request path without query string
+ * @return string Path pattern, e.g. /orders/:id/items
+ */
+func NormalizePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if looksLikeID(seg) {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func looksLikeID(seg string) bool {
+	if seg == "" {
+		return false
+	}
+	if strings.Trim(seg, "0123456789") == "" || uuidPattern.MatchString(seg) || hexPattern.MatchString(seg) {
+		return true
+	}
+	if len(seg) < 16 {
+		return false
+	}
+	return strings.ContainsAny(seg, "0123456789") && strings.IndexFunc(seg, isLetter) >= 0
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
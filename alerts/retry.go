@@ -0,0 +1,62 @@
+package alerts
+
+import (
+	"context"
+	"time"
+)
+
+const defaultTimeout = 10 * time.Second
+
+/**
+ * Retrier is implemented by alerters that want failed sends retried. The
+ * manager retries up to Retries() more times with exponential backoff
+ * starting at 250ms.
+ */
+type Retrier interface {
+	Retries() int
+}
+
+/**
+ * Timeout converts a provider's timeout_sec setting into a duration.
+ *
+ * @param sec Configured seconds, 0 or negative for the 10s default
+ * @return time.Duration Per-send timeout
+ */
+func Timeout(sec int) time.Duration {
+	if sec <= 0 {
+		return defaultTimeout
+	}
+	return time.Duration(sec) * time.Second
+}
+
+func retriesOf(a Alerter) int {
+	if r, ok := a.(Retrier); ok && r.Retries() > 0 {
+		return r.Retries()
+	}
+	return 0
+}
+
+func (m *Manager) send(ctx context.Context, a Alerter, payload Payload) error {
+	err := m.sendOnce(ctx, a, payload)
+	for attempt := 0; err != nil && attempt < retriesOf(a); attempt++ {
+		select {
+		case <-time.After(250 * time.Millisecond << attempt):
+		case <-ctx.Done():
+			return err
+		}
+		err = m.sendOnce(ctx, a, payload)
+	}
+	return err
+}
+
+// sendOnce holds a concurrency slot for one attempt only, so backoff waits do not block other sends
+func (m *Manager) sendOnce(ctx context.Context, a Alerter, payload Payload) error {
+	select {
+	case m.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-m.slots }()
+
+	return a.Send(ctx, payload)
+}
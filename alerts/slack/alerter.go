@@ -1,39 +1,56 @@
 package slack
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ahmadsaubani/go-logging-lib/alerts"
+	"github.com/ahmadsaubani/go-logging-lib/outbound"
 )
 
 type Config struct {
-	Enabled    bool   `yaml:"enabled"`
-	WebhookURL string `yaml:"webhook_url"`
-	Channel    string `yaml:"channel"`
-	Username   string `yaml:"username"`
-	IconEmoji  string `yaml:"icon_emoji"`
+	Enabled    bool                `yaml:"enabled"`
+	WebhookURL string              `yaml:"webhook_url"`
+	Channel    string              `yaml:"channel"`
+	Username   string              `yaml:"username"`
+	IconEmoji  string              `yaml:"icon_emoji"`
+	GrafanaURL string              `yaml:"grafana_url"`
+	TLS        *outbound.TLSConfig `yaml:"tls,omitempty"`
+	Proxy      string              `yaml:"proxy"`
+	TimeoutSec int                 `yaml:"timeout_sec"`
+	Retries    int                 `yaml:"retries"`
 }
 
 type Alerter struct {
-	config *Config
-	client *http.Client
+	config    *Config
+	client    *http.Client
+	clientErr error
 }
 
 /**
  * New creates a new Slack alerter instance.
- * Uses Slack incoming webhooks to send attachment messages with error details.
+ * Uses Slack incoming webhooks to send Block Kit messages with error details.
+ * GrafanaURL, when set, adds a "View in Grafana" button ahead of the
+ * configured alert links; it takes the same placeholders as alerts.LinkTemplate.
  *
  * @param config Slack webhook configuration including URL and channel settings
  * @return *Alerter Ready-to-use Slack alerter
  */
 func New(config *Config) *Alerter {
+	client, err := outbound.NewHTTPClient(outbound.ClientOptions{
+		TLS:     config.TLS,
+		Proxy:   config.Proxy,
+		Timeout: alerts.Timeout(config.TimeoutSec),
+	})
+
 	return &Alerter{
-		config: config,
-		client: &http.Client{Timeout: 10 * time.Second},
+		config:    config,
+		client:    client,
+		clientErr: err,
 	}
 }
 
@@ -41,14 +58,22 @@ func (a *Alerter) Name() string {
 	return "Slack"
 }
 
+func (a *Alerter) Retries() int {
+	return a.config.Retries
+}
+
 /**
  * Send dispatches an alert to Slack via webhook.
- * Creates an attachment message with color-coded severity and detailed fields.
+ * Creates a Block Kit message with color-coded severity and detailed fields.
  *
+ * @param ctx Cancels the send, e.g. on shutdown
  * @param payload Alert data containing error details and request metadata
  * @return error Returns nil on success, or error if webhook fails
  */
-func (a *Alerter) Send(payload alerts.Payload) error {
+func (a *Alerter) Send(ctx context.Context, payload alerts.Payload) error {
+	if a.clientErr != nil {
+		return fmt.Errorf("invalid client config: %w", a.clientErr)
+	}
 	if a.config.WebhookURL == "" {
 		return fmt.Errorf("slack webhook URL is empty")
 	}
@@ -60,7 +85,7 @@ func (a *Alerter) Send(payload alerts.Payload) error {
 		return fmt.Errorf("failed to marshal slack message: %w", err)
 	}
 
-	resp, err := a.client.Post(a.config.WebhookURL, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := outbound.PostJSON(ctx, a.client, a.config.WebhookURL, jsonData)
 	if err != nil {
 		return fmt.Errorf("failed to send slack webhook: %w", err)
 	}
@@ -73,37 +98,81 @@ func (a *Alerter) Send(payload alerts.Payload) error {
 	return nil
 }
 
+/**
+ * buildMessage renders the alert as Block Kit blocks inside a colored
+ * attachment, which keeps the severity bar on the left. Text is the
+ * notification fallback shown where blocks are not rendered.
+ */
 func (a *Alerter) buildMessage(payload alerts.Payload) map[string]interface{} {
-	color := a.getLevelColor(payload.Level)
-
 	stackText := "No stack trace"
 	if len(payload.Stack) > 0 {
-		stackText = ""
-		for _, frame := range payload.Stack {
-			stackText += frame + "\n"
-		}
+		stackText = strings.Join(payload.Stack, "\n")
 	}
 
-	attachment := map[string]interface{}{
-		"color":  color,
-		"title":  fmt.Sprintf("🚨 %s Alert", payload.Level),
-		"text":   payload.Error,
-		"footer": "Go Logging Library",
-		"ts":     payload.Timestamp.Unix(),
-		"fields": []map[string]interface{}{
-			{"title": "Service", "value": payload.ServiceName, "short": true},
-			{"title": "Level", "value": payload.Level, "short": true},
-			{"title": "Method", "value": payload.Method, "short": true},
-			{"title": "Path", "value": payload.Path, "short": true},
-			{"title": "Client IP", "value": defaultIfEmpty(payload.IP, "N/A"), "short": true},
-			{"title": "Source", "value": fmt.Sprintf("%s:%d", payload.File, payload.Line), "short": true},
-			{"title": "Request ID", "value": defaultIfEmpty(payload.RequestID, "N/A"), "short": false},
-			{"title": "Stack Trace", "value": "```" + truncate(stackText, 500) + "```", "short": false},
+	details := []field{
+		{"Service", payload.ServiceLabel()},
+		{"Level", payload.Level},
+		{"Environment", defaultIfEmpty(payload.Environment, "N/A")},
+		{"Host", defaultIfEmpty(payload.Hostname, "N/A")},
+		{"Method", payload.Method},
+		{"Path", payload.Path},
+		{"Client IP", defaultIfEmpty(payload.IP, "N/A")},
+		{"Source", fmt.Sprintf("%s:%d", payload.File, payload.Line)},
+		{"Request ID", defaultIfEmpty(payload.RequestID, "N/A")},
+	}
+	var custom []field
+	for _, f := range payload.Fields {
+		custom = append(custom, field{f.Key, defaultIfEmpty(f.Value, "N/A")})
+	}
+
+	blocks := []map[string]interface{}{
+		{
+			"type": "header",
+			"text": map[string]interface{}{"type": "plain_text", "text": fmt.Sprintf("🚨 %s Alert", payload.Level), "emoji": true},
 		},
+		mrkdwnSection(truncate(escape(payload.Error), 3000)),
+	}
+	blocks = append(blocks, fieldSections(details)...)
+	blocks = append(blocks, fieldSections(custom)...)
+	blocks = append(blocks, mrkdwnSection("*Stack Trace*\n```"+truncate(escape(stackText), 2900)+"```"))
+	if payload.Excerpt != "" {
+		// Block Kit cannot collapse a section, so the excerpt is kept short
+		blocks = append(blocks, mrkdwnSection("*Log Excerpt*\n```"+truncate(escape(payload.ExcerptTail(2000)), 2900)+"```"))
+	}
+	blocks = append(blocks,
+		map[string]interface{}{
+			"type": "context",
+			"elements": []map[string]interface{}{
+				{"type": "mrkdwn", "text": fmt.Sprintf("Go Logging Library • <!date^%d^{date_short_pretty} {time_secs}|%s>",
+					payload.Timestamp.Unix(), payload.Timestamp.UTC().Format(time.RFC3339))},
+			},
+		},
+	)
+
+	links := payload.Links
+	if a.config.GrafanaURL != "" {
+		if u := alerts.ExpandLink(a.config.GrafanaURL, payload); u != "" {
+			links = append([]alerts.Link{{Name: "Grafana", URL: u}}, links...)
+		}
+	}
+	if len(links) > 0 {
+		var buttons []map[string]interface{}
+		// an actions block holds at most 25 elements
+		for _, link := range links[:min(len(links), 25)] {
+			buttons = append(buttons, map[string]interface{}{
+				"type": "button",
+				"text": map[string]interface{}{"type": "plain_text", "text": truncate("View in "+link.Name, 75)},
+				"url":  link.URL,
+			})
+		}
+		blocks = append(blocks, map[string]interface{}{"type": "actions", "elements": buttons})
 	}
 
 	message := map[string]interface{}{
-		"attachments": []map[string]interface{}{attachment},
+		"text": fmt.Sprintf("%s alert in %s: %s", payload.Level, payload.ServiceLabel(), truncate(payload.Error, 200)),
+		"attachments": []map[string]interface{}{
+			{"color": a.getLevelColor(payload.Level), "blocks": blocks},
+		},
 	}
 
 	if a.config.Channel != "" {
@@ -119,8 +188,43 @@ func (a *Alerter) buildMessage(payload alerts.Payload) map[string]interface{} {
 	return message
 }
 
+type field struct {
+	title string
+	value string
+}
+
+func mrkdwnSection(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "section",
+		"text": map[string]interface{}{"type": "mrkdwn", "text": text},
+	}
+}
+
+// fieldSections splits fields into sections of 10, the most Slack accepts per section
+func fieldSections(fields []field) []map[string]interface{} {
+	var sections []map[string]interface{}
+	for start := 0; start < len(fields); start += 10 {
+		end := min(start+10, len(fields))
+		var items []map[string]interface{}
+		for _, f := range fields[start:end] {
+			items = append(items, map[string]interface{}{
+				"type": "mrkdwn",
+				"text": truncate(fmt.Sprintf("*%s*\n%s", escape(f.title), escape(f.value)), 2000),
+			})
+		}
+		sections = append(sections, map[string]interface{}{"type": "section", "fields": items})
+	}
+	return sections
+}
+
+// escape encodes the characters Slack treats as control sequences in mrkdwn
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
 func (a *Alerter) getLevelColor(level string) string {
 	colors := map[string]string{
+		"FATAL":    "#6f42c1",
 		"CRITICAL": "#dc3545",
 		"ERROR":    "#fd7e14",
 		"WARN":     "#ffc107",
@@ -0,0 +1,89 @@
+package alerts
+
+import (
+	"fmt"
+	"time"
+)
+
+/**
+ * occurrence tracks one fingerprint in first-occurrence mode: how often it
+ * repeated since the last alert or summary, and the latest payload to report.
+ */
+type occurrence struct {
+	count  int
+	latest Payload
+}
+
+func (m *Manager) summaryInterval() time.Duration {
+	if m.config.SummaryIntervalSec > 0 {
+		return time.Duration(m.config.SummaryIntervalSec) * time.Second
+	}
+	return time.Duration(m.config.RateLimitSec) * time.Second
+}
+
+/**
+ * firstOccurrence reports whether payload is the first of its fingerprint and
+ * should alert now. Repeats are counted for the next summary instead.
+ */
+func (m *Manager) firstOccurrence(payload Payload) bool {
+	key := m.getAlertKey(payload)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if occ, ok := m.occurrences[key]; ok {
+		occ.count++
+		occ.latest = payload
+		m.grouped.Add(1)
+		return false
+	}
+	m.occurrences[key] = &occurrence{}
+	return true
+}
+
+/**
+ * summarize sends one summary per fingerprint that repeated since the last
+ * run, and forgets fingerprints that stayed quiet so their next occurrence
+ * alerts immediately again.
+ */
+func (m *Manager) summarize() {
+	interval := m.summaryInterval()
+
+	m.mu.Lock()
+	var summaries []Payload
+	for key, occ := range m.occurrences {
+		if occ.count == 0 {
+			delete(m.occurrences, key)
+			continue
+		}
+		summary := occ.latest
+		summary.Fields = append(append([]Field{}, summary.Fields...), Field{
+			Key:   "Repeated",
+			Value: fmt.Sprintf("%d more times in the last %s", occ.count, interval),
+		})
+		summaries = append(summaries, summary)
+		occ.count = 0
+	}
+	m.mu.Unlock()
+
+	for _, summary := range summaries {
+		m.dispatch(summary)
+	}
+}
+
+func (m *Manager) runSummaries() {
+	defer close(m.summaryDone)
+
+	ticker := time.NewTicker(m.summaryInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.summarize()
+		case <-m.stopSummary:
+			m.summarize()
+			return
+		}
+	}
+}
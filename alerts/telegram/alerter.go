@@ -1,38 +1,56 @@
 package telegram
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
+	"unicode/utf8"
 
 	"github.com/ahmadsaubani/go-logging-lib/alerts"
+	"github.com/ahmadsaubani/go-logging-lib/outbound"
 )
 
 type Config struct {
-	Enabled  bool   `yaml:"enabled"`
-	BotToken string `yaml:"bot_token"`
-	ChatID   string `yaml:"chat_id"`
+	Enabled    bool                `yaml:"enabled"`
+	BotToken   string              `yaml:"bot_token"`
+	ChatID     string              `yaml:"chat_id"`
+	ThreadID   int                 `yaml:"message_thread_id"`
+	ParseMode  string              `yaml:"parse_mode"`
+	APIURL     string              `yaml:"api_url"`
+	TLS        *outbound.TLSConfig `yaml:"tls,omitempty"`
+	Proxy      string              `yaml:"proxy"`
+	TimeoutSec int                 `yaml:"timeout_sec"`
+	Retries    int                 `yaml:"retries"`
 }
 
 type Alerter struct {
-	config *Config
-	client *http.Client
+	config    *Config
+	client    *http.Client
+	clientErr error
 }
 
 /**
  * New creates a new Telegram alerter instance.
  * Uses Telegram Bot API to send HTML-formatted messages to a chat/channel.
+ * ThreadID posts into a topic of a forum group, ParseMode "MarkdownV2"
+ * switches the formatting and APIURL points at a self-hosted Bot API server.
  *
  * @param config Telegram bot configuration including token and chat ID
  * @return *Alerter Ready-to-use Telegram alerter
  */
 func New(config *Config) *Alerter {
+	client, err := outbound.NewHTTPClient(outbound.ClientOptions{
+		TLS:     config.TLS,
+		Proxy:   config.Proxy,
+		Timeout: alerts.Timeout(config.TimeoutSec),
+	})
+
 	return &Alerter{
-		config: config,
-		client: &http.Client{Timeout: 10 * time.Second},
+		config:    config,
+		client:    client,
+		clientErr: err,
 	}
 }
 
@@ -40,34 +58,77 @@ func (a *Alerter) Name() string {
 	return "Telegram"
 }
 
+func (a *Alerter) Retries() int {
+	return a.config.Retries
+}
+
 /**
  * Send dispatches an alert to Telegram via Bot API.
- * Creates an HTML-formatted message with emoji indicators and monospace code blocks.
+ * Creates an HTML or MarkdownV2 message with emoji indicators and monospace code
+ * blocks, split into several messages when it exceeds the API limit.
  *
+ * @param ctx Cancels the send, e.g. on shutdown
  * @param payload Alert data containing error details and request metadata
  * @return error Returns nil on success, or error if API call fails
  */
-func (a *Alerter) Send(payload alerts.Payload) error {
+func (a *Alerter) Send(ctx context.Context, payload alerts.Payload) error {
+	if a.clientErr != nil {
+		return fmt.Errorf("invalid client config: %w", a.clientErr)
+	}
 	if a.config.BotToken == "" || a.config.ChatID == "" {
 		return fmt.Errorf("telegram bot token or chat ID is empty")
 	}
 
-	message := a.buildMessage(payload)
+	var f formatter
+	switch {
+	case a.config.ParseMode == "" || strings.EqualFold(a.config.ParseMode, "HTML"):
+		f = htmlFormatter{}
+	case strings.EqualFold(a.config.ParseMode, "MarkdownV2"):
+		f = markdownFormatter{}
+	default:
+		return fmt.Errorf("unsupported telegram parse mode %q", a.config.ParseMode)
+	}
+
+	apiURL := strings.TrimSuffix(a.config.APIURL, "/")
+	if apiURL == "" {
+		apiURL = defaultAPIURL
+	}
+	url := fmt.Sprintf("%s/bot%s/sendMessage", apiURL, a.config.BotToken)
+
+	for _, text := range split(a.buildMessage(payload, f), f, maxMessageLength) {
+		if err := a.post(ctx, url, a.messageBody(text, f)); err != nil {
+			return err
+		}
+	}
 
-	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", a.config.BotToken)
+	// the excerpt follows as its own collapsed message; the limit counts
+	// characters after parsing, so escaping does not eat into it
+	if payload.Excerpt != "" {
+		return a.post(ctx, url, a.messageBody(f.expandable("Log Excerpt", payload.ExcerptTail(3500)), f))
+	}
+
+	return nil
+}
 
+func (a *Alerter) messageBody(text string, f formatter) map[string]interface{} {
 	body := map[string]interface{}{
 		"chat_id":    a.config.ChatID,
-		"text":       message,
-		"parse_mode": "HTML",
+		"text":       text,
+		"parse_mode": f.parseMode(),
 	}
+	if a.config.ThreadID != 0 {
+		body["message_thread_id"] = a.config.ThreadID
+	}
+	return body
+}
 
+func (a *Alerter) post(ctx context.Context, url string, body map[string]interface{}) error {
 	jsonData, err := json.Marshal(body)
 	if err != nil {
 		return fmt.Errorf("failed to marshal telegram message: %w", err)
 	}
 
-	resp, err := a.client.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := outbound.PostJSON(ctx, a.client, url, jsonData)
 	if err != nil {
 		return fmt.Errorf("failed to send telegram message: %w", err)
 	}
@@ -80,34 +141,53 @@ func (a *Alerter) Send(payload alerts.Payload) error {
 	return nil
 }
 
-func (a *Alerter) buildMessage(payload alerts.Payload) string {
+func (a *Alerter) buildMessage(payload alerts.Payload, f formatter) []line {
 	emoji := a.getLevelEmoji(payload.Level)
 
-	var sb strings.Builder
-
-	sb.WriteString(fmt.Sprintf("%s <b>%s Alert</b>\n\n", emoji, payload.Level))
-	sb.WriteString(fmt.Sprintf("<b>Service:</b> %s\n", escapeHTML(payload.ServiceName)))
-	sb.WriteString(fmt.Sprintf("<b>Error:</b> %s\n\n", escapeHTML(payload.Error)))
-	sb.WriteString(fmt.Sprintf("<b>Method:</b> %s\n", escapeHTML(payload.Method)))
-	sb.WriteString(fmt.Sprintf("<b>Path:</b> <code>%s</code>\n", escapeHTML(payload.Path)))
-	sb.WriteString(fmt.Sprintf("<b>Client IP:</b> %s\n", escapeHTML(defaultIfEmpty(payload.IP, "N/A"))))
-	sb.WriteString(fmt.Sprintf("<b>Source:</b> <code>%s:%d</code>\n", escapeHTML(payload.File), payload.Line))
-	sb.WriteString(fmt.Sprintf("<b>Request ID:</b>\n<code>%s</code>\n\n", escapeHTML(defaultIfEmpty(payload.RequestID, "N/A"))))
-	sb.WriteString(fmt.Sprintf("<b>Time:</b> %s\n", payload.Timestamp.Format("02 Jan 2006 15:04:05")))
+	lines := []line{
+		{text: f.escape(emoji) + " " + f.bold(payload.Level+" Alert")},
+		{},
+		{text: f.bold("Service:") + " " + f.escape(payload.ServiceLabel())},
+		{text: f.bold("Environment:") + " " + f.escape(defaultIfEmpty(payload.Environment, "N/A"))},
+		{text: f.bold("Host:") + " " + f.escape(defaultIfEmpty(payload.Hostname, "N/A"))},
+		{text: f.bold("Error:") + " " + f.escape(truncate(payload.Error, maxValueLength))},
+		{},
+		{text: f.bold("Method:") + " " + f.escape(payload.Method)},
+		{text: f.bold("Path:") + " " + f.code(truncate(payload.Path, maxValueLength))},
+		{text: f.bold("Client IP:") + " " + f.escape(defaultIfEmpty(payload.IP, "N/A"))},
+		{text: f.bold("Source:") + " " + f.code(fmt.Sprintf("%s:%d", payload.File, payload.Line))},
+		{text: f.bold("Request ID:")},
+		{text: f.code(defaultIfEmpty(payload.RequestID, "N/A"))},
+		{},
+	}
+	for _, field := range payload.Fields {
+		lines = append(lines, line{text: f.bold(field.Key+":") + " " + f.escape(truncate(field.Value, maxValueLength))})
+	}
+	if len(payload.Fields) > 0 {
+		lines = append(lines, line{})
+	}
+	lines = append(lines, line{text: f.bold("Time:") + " " + f.escape(payload.Timestamp.Format("02 Jan 2006 15:04:05"))})
+	if len(payload.Links) > 0 {
+		var links []string
+		for _, link := range payload.Links {
+			links = append(links, f.link(link.Name, link.URL))
+		}
+		lines = append(lines, line{text: f.bold("Links:") + " " + strings.Join(links, f.escape(" | "))})
+	}
 
 	if len(payload.Stack) > 0 {
-		sb.WriteString("\n<b>Stack Trace:</b>\n<pre>")
+		lines = append(lines, line{}, line{text: f.bold("Stack Trace:")})
 		for _, frame := range payload.Stack {
-			sb.WriteString(escapeHTML(frame) + "\n")
+			lines = append(lines, line{text: f.escapeCode(truncate(frame, maxValueLength)), code: true})
 		}
-		sb.WriteString("</pre>")
 	}
 
-	return sb.String()
+	return lines
 }
 
 func (a *Alerter) getLevelEmoji(level string) string {
 	emojis := map[string]string{
+		"FATAL":    "🟣",
 		"CRITICAL": "🔴",
 		"ERROR":    "🟠",
 		"WARN":     "🟡",
@@ -118,16 +198,16 @@ func (a *Alerter) getLevelEmoji(level string) string {
 	return "⚪"
 }
 
-func escapeHTML(s string) string {
-	s = strings.ReplaceAll(s, "&", "&amp;")
-	s = strings.ReplaceAll(s, "<", "&lt;")
-	s = strings.ReplaceAll(s, ">", "&gt;")
-	return s
-}
-
 func defaultIfEmpty(s, def string) string {
 	if s == "" {
 		return def
 	}
 	return s
 }
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max-3]) + "..."
+}
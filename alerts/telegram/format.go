@@ -0,0 +1,145 @@
+package telegram
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultAPIURL = "https://api.telegram.org"
+
+	// maxMessageLength is the Bot API limit for the text of one message
+	maxMessageLength = 4096
+
+	// maxValueLength keeps a single value well below maxMessageLength so every
+	// line fits into a message of its own
+	maxValueLength = 1000
+)
+
+/**
+ * formatter renders message parts in one of the Bot API parse modes. escape
+ * is for plain text, escapeCode for the content of code blocks; preOpen
+ * includes the line break a MarkdownV2 block needs after its fence.
+ */
+type formatter interface {
+	parseMode() string
+	escape(s string) string
+	escapeCode(s string) string
+	bold(s string) string
+	code(s string) string
+	link(name, url string) string
+	preOpen() string
+	preClose() string
+	expandable(title, text string) string
+}
+
+type htmlFormatter struct{}
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+func (htmlFormatter) parseMode() string          { return "HTML" }
+func (htmlFormatter) escape(s string) string     { return htmlEscaper.Replace(s) }
+func (htmlFormatter) escapeCode(s string) string { return htmlEscaper.Replace(s) }
+func (htmlFormatter) bold(s string) string       { return "<b>" + htmlEscaper.Replace(s) + "</b>" }
+func (htmlFormatter) code(s string) string       { return "<code>" + htmlEscaper.Replace(s) + "</code>" }
+func (htmlFormatter) preOpen() string            { return "<pre>" }
+func (htmlFormatter) preClose() string           { return "</pre>" }
+
+func (htmlFormatter) expandable(title, text string) string {
+	return "<b>" + htmlEscaper.Replace(title) + "</b>\n<blockquote expandable>" + htmlEscaper.Replace(text) + "</blockquote>"
+}
+
+func (htmlFormatter) link(name, url string) string {
+	return `<a href="` + htmlEscaper.Replace(url) + `">` + htmlEscaper.Replace(name) + "</a>"
+}
+
+type markdownFormatter struct{}
+
+// MarkdownV2 requires these characters to be escaped anywhere outside code
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+	"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+// inside code blocks only backslash and backtick are special
+var markdownCodeEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+
+func (markdownFormatter) parseMode() string          { return "MarkdownV2" }
+func (markdownFormatter) escape(s string) string     { return markdownEscaper.Replace(s) }
+func (markdownFormatter) escapeCode(s string) string { return markdownCodeEscaper.Replace(s) }
+func (markdownFormatter) bold(s string) string       { return "*" + markdownEscaper.Replace(s) + "*" }
+func (markdownFormatter) code(s string) string       { return "`" + markdownCodeEscaper.Replace(s) + "`" }
+func (markdownFormatter) preOpen() string            { return "```\n" }
+func (markdownFormatter) preClose() string           { return "```" }
+
+func (markdownFormatter) expandable(title, text string) string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = markdownEscaper.Replace(l)
+	}
+	return "*" + markdownEscaper.Replace(title) + "*\n**>" + strings.Join(lines, "\n>") + "||"
+}
+
+func (markdownFormatter) link(name, url string) string {
+	return "[" + markdownEscaper.Replace(name) + "](" + strings.NewReplacer(`\`, `\\`, ")", `\)`).Replace(url) + ")"
+}
+
+/**
+ * line is one rendered line of a message; code lines belong to the stack
+ * trace block.
+ */
+type line struct {
+	text string
+	code bool
+}
+
+/**
+ * split joins lines into messages of at most limit characters, breaking only
+ * between lines. A code block cut by a break is closed at the end of one
+ * message and reopened in the next, so every message is valid markup.
+ */
+func split(lines []line, f formatter, limit int) []string {
+	var messages []string
+	var sb strings.Builder
+	size, inCode := 0, false
+
+	write := func(s string) {
+		sb.WriteString(s)
+		size += utf8.RuneCountInString(s)
+	}
+	flush := func() {
+		if inCode {
+			write(f.preClose())
+			inCode = false
+		}
+		if text := strings.TrimRight(sb.String(), "\n"); text != "" {
+			messages = append(messages, text)
+		}
+		sb.Reset()
+		size = 0
+	}
+
+	for _, l := range lines {
+		// room for the text, its newline and the markup to open and close a code block
+		need := utf8.RuneCountInString(l.text) + 1
+		if l.code {
+			need += utf8.RuneCountInString(f.preOpen()) + utf8.RuneCountInString(f.preClose())
+		}
+		if size > 0 && size+need > limit {
+			flush()
+		}
+
+		if l.code && !inCode {
+			write(f.preOpen())
+			inCode = true
+		} else if !l.code && inCode {
+			write(f.preClose() + "\n")
+			inCode = false
+		}
+		write(l.text + "\n")
+	}
+	flush()
+
+	return messages
+}
@@ -1,6 +1,11 @@
 package alerts
 
-import "time"
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
 
 type LogLevel string
 
@@ -8,40 +13,196 @@ const (
 	LevelWarn     LogLevel = "WARN"
 	LevelError    LogLevel = "ERROR"
 	LevelCritical LogLevel = "CRITICAL"
+	LevelFatal    LogLevel = "FATAL"
 )
 
 /**
  * Alerter defines the interface that all alert providers must implement.
  * This allows for dependency injection and easy addition of new alert channels.
+ * Send must give up when ctx is done; the manager cancels it on Close.
  *
  * Implementations:
  *   - discord.Alerter: Sends alerts via Discord webhooks
  *   - slack.Alerter: Sends alerts via Slack webhooks
  *   - telegram.Alerter: Sends alerts via Telegram Bot API
  *   - email.Alerter: Sends alerts via SMTP email
+ *   - matrix.Alerter: Sends alerts to a Matrix room
+ *   - webex.Alerter: Sends alerts via Webex webhooks or bots
  */
 type Alerter interface {
+	Name() string
+	Send(ctx context.Context, payload Payload) error
+}
+
+/**
+ * LegacyAlerter is the context-free Alerter of earlier releases. Wrap
+ * implementations with Adapt to register them.
+ */
+type LegacyAlerter interface {
 	Name() string
 	Send(payload Payload) error
 }
 
+type legacyAlerter struct {
+	LegacyAlerter
+}
+
+/**
+ * Adapt turns a LegacyAlerter into an Alerter. The wrapped Send cannot be
+ * interrupted, so a cancelled ctx only stops waiting for it. Retries() is
+ * forwarded when the legacy alerter implements Retrier.
+ *
+ * @param a Alerter written against the old interface
+ * @return Alerter Context-aware wrapper
+ */
+func Adapt(a LegacyAlerter) Alerter {
+	return legacyAlerter{a}
+}
+
+func (l legacyAlerter) Send(ctx context.Context, payload Payload) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- l.LegacyAlerter.Send(payload) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l legacyAlerter) Retries() int {
+	if r, ok := l.LegacyAlerter.(Retrier); ok {
+		return r.Retries()
+	}
+	return 0
+}
+
 type Payload struct {
-	ServiceName string
-	Level       string
-	Error       string
-	RequestID   string
-	Method      string
-	Path        string
-	IP          string
-	UserAgent   string
-	File        string
-	Line        int
-	Stack       []string
-	Timestamp   time.Time
+	ServiceName    string
+	ServiceVersion string
+	Build          Build
+	Environment    string
+	Hostname       string
+	Level          string
+	Error          string
+	RequestID      string
+	Method         string
+	Path           string
+	Route          string
+	IP             string
+	UserAgent      string
+	File           string
+	Line           int
+	Stack          []string
+	Fields         []Field
+	Links          []Link
+	Excerpt        string
+	Timestamp      time.Time
+}
+
+/**
+ * ExcerptTail returns at most max bytes from the end of Excerpt, cut at a line
+ * start where possible, since the latest log lines matter most.
+ *
+ * @param max Maximum length in bytes
+ * @return string Tail of the excerpt, prefixed with "..." when shortened
+ */
+func (p Payload) ExcerptTail(max int) string {
+	if len(p.Excerpt) <= max {
+		return p.Excerpt
+	}
+	tail := p.Excerpt[len(p.Excerpt)-max+4:]
+	if i := strings.IndexByte(tail, '\n'); i >= 0 && i < len(tail)-1 {
+		tail = tail[i+1:]
+	}
+	for len(tail) > 0 && !utf8.RuneStart(tail[0]) {
+		tail = tail[1:]
+	}
+	return "...\n" + tail
 }
 
+/**
+ * Field is an application-defined key/value pair rendered by every provider
+ * after the built-in fields, e.g. order_id or tenant.
+ */
+type Field struct {
+	Key   string
+	Value string
+}
+
+/**
+ * Build describes the running binary, from runtime/debug.ReadBuildInfo.
+ * Revision, Time and Modified are only set for binaries built from a VCS
+ * checkout with stamping enabled (the default for `go build`).
+ */
+type Build struct {
+	GoVersion string
+	Revision  string
+	Time      string
+	Modified  bool
+}
+
+/**
+ * ShortRevision returns the first 7 characters of the VCS revision, with a
+ * "-dirty" suffix for builds with uncommitted changes.
+ *
+ * @return string Short revision, empty when unknown
+ */
+func (b Build) ShortRevision() string {
+	rev := b.Revision
+	if len(rev) > 7 {
+		rev = rev[:7]
+	}
+	if rev != "" && b.Modified {
+		rev += "-dirty"
+	}
+	return rev
+}
+
+/**
+ * ServiceLabel returns the service name with its version and revision, e.g.
+ * "orders-api 1.4.2 (a1b2c3d)", for the Service field of provider messages.
+ *
+ * @return string Name, followed by the version and revision when known
+ */
+func (p Payload) ServiceLabel() string {
+	label := p.ServiceName
+	if p.ServiceVersion != "" {
+		label += " " + p.ServiceVersion
+	}
+	if rev := p.Build.ShortRevision(); rev != "" {
+		label += " (" + rev + ")"
+	}
+	return label
+}
+
+/**
+ * Config controls which alerts are sent. PathNormalizer maps a request path
+ * to the pattern used in the rate-limit key when no route is known; nil uses
+ * NormalizePath.
+ *
+ * With FirstOccurrenceOnly, only the first occurrence of an alert key is sent
+ * right away; repeats are counted and sent as one summary per key every
+ * SummaryIntervalSec (default: RateLimitSec). A key with no repeats until
+ * the next summary run is forgotten and alerts immediately the next time.
+ *
+ * Links are expanded for each alert and appended to Payload.Links.
+ *
+ * MaxConcurrentSends caps the Send calls in progress across all alerters
+ * (default 10); further sends wait for a free slot.
+ */
 type Config struct {
-	Enabled      bool
-	MinLevel     LogLevel
-	RateLimitSec int
+	Enabled             bool
+	MinLevel            LogLevel
+	RateLimitSec        int
+	PathNormalizer      func(path string) string
+	FirstOccurrenceOnly bool
+	SummaryIntervalSec  int
+	MaxConcurrentSends  int
+	Links               []LinkTemplate
 }
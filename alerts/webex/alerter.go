@@ -0,0 +1,257 @@
+package webex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+	"github.com/ahmadsaubani/go-logging-lib/outbound"
+)
+
+const (
+	defaultAPIURL = "https://webexapis.com/v1/messages"
+
+	// maxMarkdownLength stays below the 7439 byte message limit
+	maxMarkdownLength = 7000
+)
+
+type Config struct {
+	Enabled    bool                `yaml:"enabled"`
+	WebhookURL string              `yaml:"webhook_url"`
+	BotToken   string              `yaml:"bot_token"`
+	RoomID     string              `yaml:"room_id"`
+	APIURL     string              `yaml:"api_url"`
+	TLS        *outbound.TLSConfig `yaml:"tls,omitempty"`
+	Proxy      string              `yaml:"proxy"`
+	TimeoutSec int                 `yaml:"timeout_sec"`
+	Retries    int                 `yaml:"retries"`
+}
+
+type Alerter struct {
+	config    *Config
+	client    *http.Client
+	clientErr error
+}
+
+/**
+ * New creates a new Webex alerter instance.
+ * Posts markdown messages through an incoming webhook (WebhookURL) or, with
+ * BotToken and RoomID, through the messages API as a bot, which also attaches
+ * an Adaptive Card with the alert details.
+ *
+ * @param config Webex webhook or bot configuration
+ * @return *Alerter Ready-to-use Webex alerter
+ */
+func New(config *Config) *Alerter {
+	client, err := outbound.NewHTTPClient(outbound.ClientOptions{
+		TLS:     config.TLS,
+		Proxy:   config.Proxy,
+		Timeout: alerts.Timeout(config.TimeoutSec),
+	})
+
+	return &Alerter{
+		config:    config,
+		client:    client,
+		clientErr: err,
+	}
+}
+
+func (a *Alerter) Name() string {
+	return "Webex"
+}
+
+func (a *Alerter) Retries() int {
+	return a.config.Retries
+}
+
+/**
+ * Send dispatches an alert to Webex.
+ * Uses the bot API when BotToken is set, otherwise the incoming webhook.
+ *
+ * @param ctx Cancels the send, e.g. on shutdown
+ * @param payload Alert data containing error details and request metadata
+ * @return error Returns nil on success, or error if the request fails
+ */
+func (a *Alerter) Send(ctx context.Context, payload alerts.Payload) error {
+	if a.clientErr != nil {
+		return fmt.Errorf("invalid client config: %w", a.clientErr)
+	}
+
+	message := map[string]interface{}{
+		"markdown": a.buildMarkdown(payload),
+	}
+
+	var endpoint string
+	switch {
+	case a.config.BotToken != "":
+		if a.config.RoomID == "" {
+			return fmt.Errorf("webex room ID is empty")
+		}
+		endpoint = defaultIfEmpty(a.config.APIURL, defaultAPIURL)
+		message["roomId"] = a.config.RoomID
+		message["attachments"] = []map[string]interface{}{a.buildCard(payload)}
+	case a.config.WebhookURL != "":
+		endpoint = a.config.WebhookURL
+	default:
+		return fmt.Errorf("webex webhook URL or bot token is empty")
+	}
+
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webex message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.config.BotToken != "" {
+		req.Header.Set("Authorization", "Bearer "+a.config.BotToken)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webex message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("webex API returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (a *Alerter) buildMarkdown(payload alerts.Payload) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("### %s %s Alert\n", a.getLevelEmoji(payload.Level), payload.Level))
+	sb.WriteString(fmt.Sprintf("**%s**\n\n", escape(truncate(payload.Error, 1000))))
+	for _, f := range a.facts(payload) {
+		sb.WriteString(fmt.Sprintf("- **%s:** %s\n", escape(f.Key), escape(f.Value)))
+	}
+
+	if len(payload.Links) > 0 {
+		var links []string
+		for _, link := range payload.Links {
+			links = append(links, fmt.Sprintf("[%s](%s)", escape(link.Name), link.URL))
+		}
+		sb.WriteString("\n" + strings.Join(links, " | ") + "\n")
+	}
+
+	if len(payload.Stack) > 0 {
+		sb.WriteString("\n**Stack Trace**\n```\n" + truncate(strings.Join(payload.Stack, "\n"), 2000) + "\n```\n")
+	}
+	if payload.Excerpt != "" {
+		sb.WriteString("\n**Log Excerpt**\n```\n" + payload.ExcerptTail(2000) + "\n```\n")
+	}
+
+	return truncate(sb.String(), maxMarkdownLength)
+}
+
+/**
+ * buildCard renders the alert as an Adaptive Card. Bot messages show the card
+ * and fall back to the markdown in clients that cannot render it.
+ */
+func (a *Alerter) buildCard(payload alerts.Payload) map[string]interface{} {
+	var facts []map[string]string
+	for _, f := range a.facts(payload) {
+		facts = append(facts, map[string]string{"title": f.Key, "value": f.Value})
+	}
+
+	body := []map[string]interface{}{
+		{"type": "TextBlock", "text": fmt.Sprintf("%s %s Alert", a.getLevelEmoji(payload.Level), payload.Level), "weight": "Bolder", "size": "Medium", "color": a.getLevelColor(payload.Level)},
+		{"type": "TextBlock", "text": payload.Error, "wrap": true},
+		{"type": "FactSet", "facts": facts},
+	}
+
+	var actions []map[string]string
+	for _, link := range payload.Links {
+		actions = append(actions, map[string]string{"type": "Action.OpenUrl", "title": "View in " + link.Name, "url": link.URL})
+	}
+
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.3",
+		"body":    body,
+	}
+	if len(actions) > 0 {
+		card["actions"] = actions
+	}
+
+	return map[string]interface{}{
+		"contentType": "application/vnd.microsoft.card.adaptive",
+		"content":     card,
+	}
+}
+
+func (a *Alerter) facts(payload alerts.Payload) []alerts.Field {
+	fields := []alerts.Field{
+		{Key: "Service", Value: payload.ServiceLabel()},
+		{Key: "Environment", Value: defaultIfEmpty(payload.Environment, "N/A")},
+		{Key: "Host", Value: defaultIfEmpty(payload.Hostname, "N/A")},
+		{Key: "Method", Value: payload.Method},
+		{Key: "Path", Value: payload.Path},
+		{Key: "Client IP", Value: defaultIfEmpty(payload.IP, "N/A")},
+		{Key: "Source", Value: fmt.Sprintf("%s:%d", payload.File, payload.Line)},
+		{Key: "Request ID", Value: defaultIfEmpty(payload.RequestID, "N/A")},
+		{Key: "Time", Value: payload.Timestamp.Format(time.RFC3339)},
+	}
+	for _, f := range payload.Fields {
+		fields = append(fields, alerts.Field{Key: f.Key, Value: defaultIfEmpty(f.Value, "N/A")})
+	}
+	return fields
+}
+
+func (a *Alerter) getLevelEmoji(level string) string {
+	emojis := map[string]string{
+		"FATAL":    "🟣",
+		"CRITICAL": "🔴",
+		"ERROR":    "🟠",
+		"WARN":     "🟡",
+	}
+	if emoji, ok := emojis[level]; ok {
+		return emoji
+	}
+	return "⚪"
+}
+
+// getLevelColor maps the level to an Adaptive Card text color
+func (a *Alerter) getLevelColor(level string) string {
+	colors := map[string]string{
+		"FATAL":    "Attention",
+		"CRITICAL": "Attention",
+		"ERROR":    "Warning",
+		"WARN":     "Accent",
+	}
+	if color, ok := colors[level]; ok {
+		return color
+	}
+	return "Default"
+}
+
+// escape keeps markdown control characters in values from changing the formatting
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max-3] + "..."
+}
+
+func defaultIfEmpty(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
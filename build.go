@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+)
+
+/**
+ * readBuild collects the Go version and VCS stamp of the running binary. The
+ * version is the main module version for binaries installed with
+ * `go install module@version`, empty for local builds.
+ *
+ * @return string Main module version, if any
+ * @return alerts.Build Go version and VCS revision
+ */
+func readBuild() (string, alerts.Build) {
+	build := alerts.Build{GoVersion: runtime.Version()}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", build
+	}
+
+	version := info.Main.Version
+	if version == "(devel)" {
+		version = ""
+	}
+
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			build.Revision = s.Value
+		case "vcs.time":
+			build.Time = s.Value
+		case "vcs.modified":
+			build.Modified = s.Value == "true"
+		}
+	}
+
+	return version, build
+}
+
+/**
+ * buildField renders build info as the Loki "build" object; it is built once
+ * and shared by every entry.
+ */
+func buildField(build alerts.Build) map[string]interface{} {
+	field := map[string]interface{}{"go": build.GoVersion}
+	if build.Revision != "" {
+		field["revision"] = build.Revision
+		field["modified"] = build.Modified
+	}
+	if build.Time != "" {
+		field["time"] = build.Time
+	}
+	return field
+}
@@ -0,0 +1,227 @@
+// Command logquery collects everything logged for one request ID across the
+// access, error, debug and Loki files (including rotated ones) and prints it
+// as a single timeline.
+//
+//	logquery [-dir ./logs] [-prefix app] <request-id>
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	rfc3339Pattern   = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(Z|[+-]\d{2}:\d{2})`)
+	stdPrefixPattern = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}`)
+)
+
+var streams = []string{"error", "debug", "loki", "access"}
+
+/**
+ * event is one timeline item: a single line, or a whole boxed error block.
+ */
+type event struct {
+	ts     time.Time
+	stream string
+	file   string
+	lines  []string
+}
+
+func main() {
+	dir := flag.String("dir", "./logs", "directory containing the log files")
+	prefix := flag.String("prefix", "app", "file prefix (Config.FilePrefix)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: logquery [flags] <request-id>\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	requestID := flag.Arg(0)
+
+	var events []event
+	for _, stream := range streams {
+		files, err := streamFiles(*dir, *prefix, stream)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logquery: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, file := range files {
+			found, err := scanFile(file, stream, requestID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "logquery: %v\n", err)
+				os.Exit(1)
+			}
+			events = append(events, found...)
+		}
+	}
+
+	if len(events) == 0 {
+		fmt.Fprintf(os.Stderr, "logquery: no entries for request %s in %s\n", requestID, *dir)
+		os.Exit(1)
+	}
+
+	// stable, so entries within the same second keep the error, debug, loki, access order
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].ts.Before(events[j].ts)
+	})
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	for _, e := range events {
+		ts := "-"
+		if !e.ts.IsZero() {
+			ts = e.ts.Format(time.RFC3339)
+		}
+		fmt.Fprintf(out, "%-25s %-6s %s\n", ts, strings.ToUpper(e.stream), e.file)
+		for _, line := range e.lines {
+			fmt.Fprintf(out, "    %s\n", line)
+		}
+	}
+}
+
+/**
+ * streamFiles lists the current and rotated files of a stream, oldest name
+ * first. The undated symlink is skipped when its target is also in the list.
+ */
+func streamFiles(dir, prefix, stream string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, prefix+"."+stream+"*.log"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+
+	var files []string
+	var seen []os.FileInfo
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		duplicate := false
+		for _, s := range seen {
+			if os.SameFile(s, info) {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+
+		seen = append(seen, info)
+		files = append(files, m)
+	}
+
+	return files, nil
+}
+
+/**
+ * scanFile returns the entries of one file that belong to the request. Boxed
+ * error blocks (from the "[ERROR]" line to the closing separator) are kept
+ * together and match on their REQ line.
+ */
+func scanFile(file, stream, requestID string) ([]event, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	name := filepath.Base(file)
+	var events []event
+	var block []string
+	separators := 0
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if block != nil {
+			block = append(block, line)
+			if strings.HasPrefix(line, "=====") {
+				separators++
+			}
+			if separators == 2 {
+				if blockMatches(block, requestID) {
+					events = append(events, event{ts: lineTime(block[0]), stream: stream, file: name, lines: block})
+				}
+				block = nil
+			}
+			continue
+		}
+
+		if strings.HasSuffix(line, "[ERROR]") {
+			block = []string{line}
+			separators = 0
+			continue
+		}
+
+		if lineMatches(line, requestID) {
+			events = append(events, event{ts: lineTime(line), stream: stream, file: name, lines: []string{line}})
+		}
+	}
+
+	return events, scanner.Err()
+}
+
+func blockMatches(block []string, requestID string) bool {
+	for _, line := range block {
+		if id, ok := strings.CutPrefix(line, "REQ    :"); ok && strings.TrimSpace(id) == requestID {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * lineMatches compares JSON entries on their request_id field and every other
+ * format (text, logfmt, CSV, CEF) on a plain substring match.
+ */
+func lineMatches(line, requestID string) bool {
+	if strings.HasPrefix(line, "{") {
+		var entry struct {
+			RequestID string `json:"request_id"`
+		}
+		if json.Unmarshal([]byte(line), &entry) == nil {
+			return entry.RequestID == requestID
+		}
+	}
+	return strings.Contains(line, requestID)
+}
+
+/**
+ * lineTime takes the first RFC3339 timestamp in the line (Loki ts, access line,
+ * logfmt ts=) and falls back to the standard log prefix in local time.
+ */
+func lineTime(line string) time.Time {
+	if m := rfc3339Pattern.FindString(line); m != "" {
+		if t, err := time.Parse(time.RFC3339, m); err == nil {
+			return t
+		}
+	}
+
+	if m := stdPrefixPattern.FindString(line); m != "" {
+		if t, err := time.ParseInLocation("2006/01/02 15:04:05", m, time.Local); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
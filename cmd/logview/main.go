@@ -0,0 +1,191 @@
+// Command logview tails and pretty-prints the access, error and Loki files
+// written by go-logging-lib.
+//
+//	logview [-f] [-n 50] [-stack] [-no-color] logs/app.loki.log logs/app.error.log
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const pollInterval = 500 * time.Millisecond
+
+func main() {
+	follow := flag.Bool("f", false, "keep reading as the files grow (follows rotation)")
+	flag.BoolVar(follow, "follow", false, "alias for -f")
+	lines := flag.Int("n", 0, "only show the last n lines of each file (0 = all)")
+	stack := flag.Bool("stack", false, "show full stack traces instead of folding them")
+	noColor := flag.Bool("no-color", false, "disable ANSI colors")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: logview [flags] file...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	multi := flag.NArg() > 1
+	tails := make([]*tail, 0, flag.NArg())
+
+	for _, name := range flag.Args() {
+		p := newPrinter(out, !*noColor, *stack)
+		if multi {
+			p.prefix = filepath.Base(name)
+		}
+
+		t, err := openTail(name, *lines, p)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logview: %v\n", err)
+			os.Exit(1)
+		}
+		tails = append(tails, t)
+	}
+
+	for _, t := range tails {
+		t.drain()
+	}
+
+	if !*follow {
+		for _, t := range tails {
+			t.printer.endStack()
+		}
+		return
+	}
+	out.Flush()
+
+	for {
+		time.Sleep(pollInterval)
+		for _, t := range tails {
+			t.poll()
+		}
+		out.Flush()
+	}
+}
+
+/**
+ * tail reads a log file from an offset and re-opens it when the path starts
+ * pointing at a new file (rotation, symlink switch) or the file shrinks.
+ */
+type tail struct {
+	name    string
+	file    *os.File
+	reader  *bufio.Reader
+	printer *printer
+	partial string
+}
+
+func openTail(name string, last int, p *printer) (*tail, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &tail{name: name, file: f, printer: p}
+
+	if last > 0 {
+		if err := t.seekLastLines(last); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	t.reader = bufio.NewReader(t.file)
+
+	return t, nil
+}
+
+/**
+ * seekLastLines positions the file so that the last n lines remain to be read.
+ */
+func (t *tail) seekLastLines(n int) error {
+	info, err := t.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	const chunk = 4096
+	size := info.Size()
+	offset := size
+	newlines := 0
+	buf := make([]byte, chunk)
+
+	for offset > 0 {
+		read := int64(chunk)
+		if offset < read {
+			read = offset
+		}
+		offset -= read
+
+		if _, err := t.file.ReadAt(buf[:read], offset); err != nil && err != io.EOF {
+			return err
+		}
+
+		for i := read - 1; i >= 0; i-- {
+			if buf[i] != '\n' || offset+i == size-1 {
+				continue
+			}
+			newlines++
+			if newlines == n {
+				_, err := t.file.Seek(offset+i+1, io.SeekStart)
+				return err
+			}
+		}
+	}
+
+	_, err = t.file.Seek(0, io.SeekStart)
+	return err
+}
+
+func (t *tail) drain() {
+	for {
+		line, err := t.reader.ReadString('\n')
+		if err != nil {
+			// keep an unterminated last line until the writer finishes it
+			t.partial += line
+			return
+		}
+		t.printer.line(t.partial + line[:len(line)-1])
+		t.partial = ""
+	}
+}
+
+func (t *tail) poll() {
+	t.drain()
+
+	info, err := os.Stat(t.name)
+	if err != nil {
+		return
+	}
+
+	current, err := t.file.Stat()
+	if err != nil {
+		return
+	}
+
+	pos, _ := t.file.Seek(0, io.SeekCurrent)
+	if os.SameFile(info, current) && info.Size() >= pos-int64(t.reader.Buffered()) {
+		return
+	}
+
+	f, err := os.Open(t.name)
+	if err != nil {
+		return
+	}
+
+	t.file.Close()
+	t.file = f
+	t.reader = bufio.NewReader(f)
+	t.partial = ""
+	t.drain()
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	colorReset  = "\033[0m"
+	colorGray   = "\033[90m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorBlue   = "\033[34m"
+	colorPurple = "\033[35m"
+	colorCyan   = "\033[36m"
+)
+
+var (
+	levelPattern  = regexp.MustCompile(`\b(TRACE|DEBUG|INFO|WARN|ERROR|CRITICAL|FATAL)\b`)
+	statusPattern = regexp.MustCompile(`\| ([1-5]\d\d) \|`)
+)
+
+/**
+ * printer renders one file's lines. Loki JSON entries are condensed to a single
+ * summary line; text lines (access lines, error blocks, logfmt) are passed
+ * through with levels and statuses colorized and stack traces folded.
+ */
+type printer struct {
+	out     io.Writer
+	color   bool
+	stack   bool
+	prefix  string
+	inStack bool
+	frames  int
+}
+
+func newPrinter(out io.Writer, color, stack bool) *printer {
+	return &printer{out: out, color: color, stack: stack}
+}
+
+func (p *printer) line(s string) {
+	if p.inStack {
+		if strings.HasPrefix(s, "- ") {
+			p.frames++
+			if p.stack {
+				p.write(p.paint(colorGray, s))
+			}
+			return
+		}
+		p.endStack()
+	}
+
+	switch {
+	case strings.HasPrefix(s, "{"):
+		if !p.lokiEntry(s) {
+			p.write(s)
+		}
+	case strings.HasPrefix(s, "STACK  :"):
+		p.write(s)
+		p.inStack = true
+		p.frames = 0
+	case strings.HasPrefix(s, "=====") && strings.HasSuffix(s, "====="):
+		p.write(p.paint(colorRed, s))
+	default:
+		p.write(p.colorize(s))
+	}
+}
+
+/**
+ * endStack closes a folded stack with a frame count.
+ */
+func (p *printer) endStack() {
+	if !p.stack && p.frames > 0 {
+		p.write(p.paint(colorGray, fmt.Sprintf("  ... %d frames folded (use -stack)", p.frames)))
+	}
+	p.inStack = false
+	p.frames = 0
+}
+
+type lokiEntry struct {
+	TS         string `json:"ts"`
+	Level      string `json:"level"`
+	Service    string `json:"service"`
+	RequestID  string `json:"request_id"`
+	StatusCode *int   `json:"status_code"`
+	LatencyMs  int64  `json:"latency_ms"`
+	LatencyUs  *int64 `json:"latency_us"`
+	Message    string `json:"message"`
+	HTTP       struct {
+		Method string `json:"method"`
+		Path   string `json:"path"`
+	} `json:"http"`
+	Errors *struct {
+		Error   string                 `json:"error"`
+		Message string                 `json:"message"`
+		Source  map[string]interface{} `json:"source"`
+		Stack   []string               `json:"stack"`
+	} `json:"errors"`
+}
+
+func (p *printer) lokiEntry(s string) bool {
+	var e lokiEntry
+	if err := json.Unmarshal([]byte(s), &e); err != nil {
+		return false
+	}
+
+	var b strings.Builder
+	b.WriteString(p.paint(colorGray, e.TS))
+	b.WriteString(" ")
+	b.WriteString(p.level(fmt.Sprintf("%-8s", e.Level)))
+
+	if e.StatusCode != nil {
+		b.WriteString(" ")
+		b.WriteString(p.status(*e.StatusCode))
+	}
+
+	if e.HTTP.Method != "" || e.HTTP.Path != "" {
+		fmt.Fprintf(&b, " %-7s %s", e.HTTP.Method, e.HTTP.Path)
+	}
+
+	if e.StatusCode != nil {
+		if e.LatencyUs != nil {
+			b.WriteString(" " + strconv.FormatFloat(float64(*e.LatencyUs)/1000, 'f', -1, 64) + "ms")
+		} else {
+			// entries written before latency_us existed
+			fmt.Fprintf(&b, " %dms", e.LatencyMs)
+		}
+	}
+
+	if e.RequestID != "" {
+		b.WriteString(p.paint(colorGray, " req="+e.RequestID))
+	}
+
+	if e.Message != "" {
+		b.WriteString(" | ")
+		b.WriteString(e.Message)
+	}
+
+	p.write(b.String())
+
+	if e.Errors == nil {
+		return true
+	}
+
+	errLine := e.Errors.Error
+	if e.Errors.Message != "" {
+		errLine = e.Errors.Message + ": " + errLine
+	}
+	if e.Errors.Source != nil {
+		errLine += p.paint(colorGray, fmt.Sprintf(" (%v:%v)", e.Errors.Source["file"], e.Errors.Source["line"]))
+	}
+	p.write("    " + p.paint(colorRed, "error: ") + errLine)
+
+	switch {
+	case p.stack:
+		for _, frame := range e.Errors.Stack {
+			p.write(p.paint(colorGray, "      - "+frame))
+		}
+	case len(e.Errors.Stack) > 0:
+		p.write(p.paint(colorGray, fmt.Sprintf("      ... %d frames folded (use -stack)", len(e.Errors.Stack))))
+	}
+
+	return true
+}
+
+func (p *printer) colorize(s string) string {
+	if !p.color {
+		return s
+	}
+
+	s = statusPattern.ReplaceAllStringFunc(s, func(m string) string {
+		code := 0
+		fmt.Sscanf(m, "| %d |", &code)
+		return "| " + p.status(code) + " |"
+	})
+
+	return levelPattern.ReplaceAllStringFunc(s, p.level)
+}
+
+func (p *printer) level(s string) string {
+	switch strings.TrimSpace(s) {
+	case "TRACE":
+		return p.paint(colorGray, s)
+	case "DEBUG":
+		return p.paint(colorCyan, s)
+	case "INFO":
+		return p.paint(colorGreen, s)
+	case "WARN":
+		return p.paint(colorYellow, s)
+	case "ERROR":
+		return p.paint(colorRed, s)
+	case "CRITICAL", "FATAL":
+		return p.paint(colorPurple, s)
+	default:
+		return s
+	}
+}
+
+func (p *printer) status(code int) string {
+	s := fmt.Sprintf("%3d", code)
+	switch {
+	case code >= 500:
+		return p.paint(colorPurple, s)
+	case code >= 400:
+		return p.paint(colorRed, s)
+	case code >= 300:
+		return p.paint(colorBlue, s)
+	default:
+		return p.paint(colorGreen, s)
+	}
+}
+
+func (p *printer) paint(color, s string) string {
+	if !p.color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func (p *printer) write(s string) {
+	if p.prefix != "" {
+		s = p.paint(colorGray, p.prefix+": ") + s
+	}
+	fmt.Fprintln(p.out, s)
+}
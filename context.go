@@ -2,23 +2,34 @@ package logging
 
 import (
 	"context"
+	"crypto/tls"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type ctxKey struct{}
 type errorKey struct{}
+type responseKey struct{}
 
 var metaKey = ctxKey{}
 var loggedErrorKey = errorKey{}
+var responseStatsKey = responseKey{}
 
 type Meta struct {
-	RequestID string
-	IP        string
-	Method    string
-	Path      string
-	UserAgent string
+	RequestID     string
+	CorrelationID string
+	IP            string
+	Method        string
+	Path          string
+	Route         string
+	UserAgent     string
+	Body          string
+	Protocol      string
+	TLSVersion    string
+	TLSCipher     string
+	Trace         map[string]string
 }
 
 func WithMeta(ctx context.Context, meta Meta) context.Context {
@@ -39,6 +50,57 @@ func ErrorFromContext(ctx context.Context) (error, bool) {
 	return err, ok
 }
 
+/**
+ * WithConnInfo returns meta with the HTTP protocol version and, for TLS
+ * connections, the negotiated TLS version and cipher suite filled in.
+ *
+ * @param meta Request metadata to extend
+ * @param r HTTP request to read connection state from
+ * @return Meta Metadata including protocol and TLS details
+ */
+func WithConnInfo(meta Meta, r *http.Request) Meta {
+	meta.Protocol = r.Proto
+	if r.TLS != nil {
+		meta.TLSVersion = tls.VersionName(r.TLS.Version)
+		meta.TLSCipher = tls.CipherSuiteName(r.TLS.CipherSuite)
+	}
+	return meta
+}
+
+/**
+ * ResponseStats describes the response body as seen by the logging middleware.
+ * FirstByte is the time from the start of the request to the first body write or flush.
+ * ClientDisconnected marks requests whose client went away before the handler
+ * finished; the Loki entry gets client_disconnected=true. ErrorBody is the
+ * (capped) body of an error response, emitted as errors.response_body.
+ * ContentType and CacheStatus go to http.content_type and http.cache_status.
+ */
+type ResponseStats struct {
+	Bytes              int64
+	FirstByte          time.Duration
+	ClientDisconnected bool
+	ErrorBody          string
+	ContentType        string
+	CacheStatus        string
+}
+
+/**
+ * WithResponseStats attaches response statistics that are emitted in the Loki
+ * entry's response object.
+ *
+ * @param ctx Request context
+ * @param stats Response statistics
+ * @return context.Context Context carrying the statistics
+ */
+func WithResponseStats(ctx context.Context, stats ResponseStats) context.Context {
+	return context.WithValue(ctx, responseStatsKey, stats)
+}
+
+func ResponseStatsFromContext(ctx context.Context) (ResponseStats, bool) {
+	stats, ok := ctx.Value(responseStatsKey).(ResponseStats)
+	return stats, ok
+}
+
 /**
  * NewRequestContext creates a context with request metadata from http.Request.
  * This is the framework-agnostic alternative to Gin middleware.
@@ -52,12 +114,19 @@ func NewRequestContext(r *http.Request) context.Context {
 		reqID = uuid.NewString()
 	}
 
+	correlationID := r.Header.Get("X-Correlation-ID")
+	if correlationID == "" {
+		correlationID = uuid.NewString()
+	}
+
 	meta := Meta{
-		RequestID: reqID,
-		IP:        getClientIP(r),
-		Method:    r.Method,
-		Path:      r.URL.Path,
-		UserAgent: r.UserAgent(),
+		RequestID:     reqID,
+		CorrelationID: correlationID,
+		IP:            getClientIP(r),
+		Method:        r.Method,
+		Path:          r.URL.Path,
+		UserAgent:     r.UserAgent(),
+		Trace:         TraceFromHeader(r.Header),
 	}
 
 	return WithMeta(r.Context(), meta)
@@ -71,4 +140,4 @@ func getClientIP(r *http.Request) string {
 		return xri
 	}
 	return r.RemoteAddr
-}
\ No newline at end of file
+}
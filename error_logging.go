@@ -6,59 +6,168 @@ import (
 	"io"
 	"log"
 	"path"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
 )
 
+const defaultStackDepth = 6
+
+/**
+ * captureOptions controls the runtime.Caller and stack walking done per entry.
+ */
+type captureOptions struct {
+	caller     bool
+	stackDepth int
+	stackSkip  int
+	skip       int
+	fullPaths  bool
+	sourceRoot string
+}
+
+var defaultCapture = captureOptions{caller: true, stackDepth: defaultStackDepth}
+
+/**
+ * filePath formats a source file for output. By default only the base name is
+ * kept; with fullPaths the path is trimmed to the source root, the module cache
+ * (pkg/mod/) or the GOROOT/GOPATH src/ directory, whichever matches first.
+ *
+ * @param file Absolute path as reported by runtime.Caller
+ * @return string Formatted path
+ */
+func (c captureOptions) filePath(file string) string {
+	if !c.fullPaths {
+		return path.Base(file)
+	}
+
+	if c.sourceRoot != "" {
+		if rel, ok := strings.CutPrefix(file, strings.TrimSuffix(filepath.ToSlash(c.sourceRoot), "/")+"/"); ok {
+			return rel
+		}
+	}
+
+	if i := strings.LastIndex(file, "/pkg/mod/"); i >= 0 {
+		return file[i+len("/pkg/mod/"):]
+	}
+
+	if i := strings.LastIndex(file, "/src/"); i >= 0 {
+		return file[i+len("/src/"):]
+	}
+
+	return file
+}
+
 func LogError(ctx context.Context, err error, errorLogger *log.Logger) {
+	logError(ctx, err, LevelError, errorLogger, defaultCapture, FormatDefault, 3)
+}
+
+/**
+ * logError writes an error to the error log at level (ERROR, or FATAL from
+ * Logger.Fatal). The default format is the boxed multi-line block;
+ * FormatLogfmt and FormatJSON emit the same detail as a single line for
+ * line-based collectors (docker, Kubernetes).
+ */
+func logError(ctx context.Context, err error, level LogLevel, errorLogger *log.Logger, capture captureOptions, format Format, skip int) {
 	if err == nil {
 		return
 	}
 
+	skip += capture.skip
+
 	file := "unknown"
 	line := 0
 
-	if _, f, l, ok := runtime.Caller(2); ok {
-		file = path.Base(f)
-		line = l
+	if capture.caller {
+		if _, f, l, ok := runtime.Caller(skip); ok {
+			file = capture.filePath(f)
+			line = l
+		}
 	}
 
 	meta, ok := FromContext(ctx)
+	dump := requestDumpFrom(err)
+
+	if format == FormatLogfmt || format == FormatJSON {
+		msg := ""
+		if me, isMsg := err.(*messageError); isMsg {
+			msg = me.msg
+			err = me.err
+		}
+
+		source := ""
+		if capture.caller {
+			source = fmt.Sprintf("%s:%d", file, line)
+		}
+
+		fields := []logfmtField{
+			{"ts", time.Now().Format(time.RFC3339)},
+			{"level", string(level)},
+			{"request_id", meta.RequestID},
+			{"correlation_id", meta.CorrelationID},
+			{"source", source},
+			{"method", meta.Method},
+			{"path", meta.Path},
+			{"ip", meta.IP},
+			{"ua", meta.UserAgent},
+			{"msg", msg},
+			{"error", err.Error()},
+		}
+		if capture.stackDepth > 0 {
+			fields = append(fields, logfmtField{"stack", stackFrames(skip+1, capture)})
+		}
+		if dump != "" {
+			fields = append(fields, logfmtField{"request", dump})
+		}
+
+		printSingleLine(errorLogger, format, fields)
+		return
+	}
 
 	if ok {
+		msgLine := ""
+		if me, isMsg := err.(*messageError); isMsg {
+			msgLine = "MSG    : " + me.msg + "\n"
+			err = me.err
+		}
+
 		ts := time.Now().Format("15:04:05")
 		sep := fmt.Sprintf(
 			"==============================CRITICAL[%s]==================================",
 			ts,
 		)
 
-		errorLogger.Printf("[%s]", "ERROR")
-		printRaw(errorLogger, sep)
-
-		printRaw(
-			errorLogger,
-			fmt.Sprintf(
-				`ERROR  : %v
+		// one Output call per block: concurrent errors never interleave and the
+		// shared logger's flags are never touched
+		var b strings.Builder
+		b.WriteString("[" + string(level) + "]\n")
+		b.WriteString(sep + "\n")
+		fmt.Fprintf(&b,
+			`%sERROR  : %v
 REQ    : %s
 FROM   : %s:%d
 HTTP   : %s %s (%s)
 UA     : %s
 STACK  :
-%s`,
-				err,
-				meta.RequestID,
-				path.Base(file),
-				line,
-				meta.Method,
-				meta.Path,
-				meta.IP,
-				meta.UserAgent,
-				prettyStackList(3, 6),
-			),
+%s
+`,
+			msgLine,
+			err,
+			meta.RequestID,
+			file,
+			line,
+			meta.Method,
+			meta.Path,
+			meta.IP,
+			meta.UserAgent,
+			prettyStackList(skip+1, capture),
 		)
+		if dump != "" {
+			b.WriteString("REQUEST:\n" + dump + "\n")
+		}
+		b.WriteString("\n" + sep)
 
-		printRaw(errorLogger, "\n"+sep)
+		errorLogger.Output(1, b.String())
 		return
 	}
 
@@ -68,31 +177,68 @@ STACK  :
 	)
 }
 
-func printRaw(l *log.Logger, s string) {
-	oldFlags := l.Flags()
-	l.SetFlags(0)
-	l.Println(s)
-	l.SetFlags(oldFlags)
+/**
+ * printSingleLine writes fields as one logfmt or JSON line, leaving out empty
+ * values.
+ */
+func printSingleLine(l *log.Logger, format Format, fields []logfmtField) {
+	set := make([]logfmtField, 0, len(fields))
+	for _, f := range fields {
+		if s, isStr := f.value.(string); isStr && s == "" {
+			continue
+		}
+		set = append(set, f)
+	}
+
+	if format != FormatJSON {
+		l.Print(encodeLogfmt(set))
+		return
+	}
+
+	ev := make(map[string]interface{}, len(set))
+	for _, f := range set {
+		ev[f.key] = f.value
+	}
+	b, _ := jsonMarshal(ev)
+	l.Print(string(b))
+}
+
+/**
+ * stdLogLine turns lines written through a standard library logger (see
+ * RedirectStdLog) into single-line error entries, so the error stream stays
+ * parseable in logfmt and JSON mode.
+ */
+type stdLogLine struct {
+	out    *log.Logger
+	format Format
+	name   string
+}
+
+func (w *stdLogLine) Write(p []byte) (int, error) {
+	printSingleLine(w.out, w.format, []logfmtField{
+		{"ts", time.Now().Format(time.RFC3339)},
+		{"level", string(LevelError)},
+		{"logger", w.name},
+		{"msg", strings.TrimRight(string(p), "\n")},
+	})
+	return len(p), nil
 }
 
-func prettyStackList(skip, max int) string {
+func prettyStackList(skip int, capture captureOptions) string {
 	var b strings.Builder
 
-	for i := skip; i < skip+max; i++ {
+	skip += capture.stackSkip
+	for i := skip; i < skip+capture.stackDepth; i++ {
 		pc, file, line, ok := runtime.Caller(i)
 		if !ok {
 			break
 		}
 
-		fn := runtime.FuncForPC(pc)
-		name := "unknown"
-		if fn != nil {
-			name = path.Base(fn.Name())
-		}
+		name := funcName(pc)
 
 		b.WriteString(fmt.Sprintf(
 			"- %-28s %s\n",
-			fmt.Sprintf("%s:%d", path.Base(file), line),
+			fmt.Sprintf("%s:%d", capture.filePath(file), line),
 			name,
 		))
 	}
@@ -104,24 +250,29 @@ func LogErrorLoki(ctx context.Context, service string, level string, err error,
 	LogLoki(ctx, service, level, 500, 0, err, writer)
 }
 
-func stackFrames(skip, max int) []string {
+// funcName returns the package-qualified function name for pc, e.g. "handlers.(*Orders).Create"
+func funcName(pc uintptr) string {
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		return path.Base(fn.Name())
+	}
+	return "unknown"
+}
+
+func stackFrames(skip int, capture captureOptions) []string {
 	var frames []string
 
-	for i := skip; i < skip+max; i++ {
+	skip += capture.stackSkip
+	for i := skip; i < skip+capture.stackDepth; i++ {
 		pc, file, line, ok := runtime.Caller(i)
 		if !ok {
 			break
 		}
 
-		fn := runtime.FuncForPC(pc)
-		name := "unknown"
-		if fn != nil {
-			name = path.Base(fn.Name())
-		}
+		name := funcName(pc)
 
 		frames = append(
 			frames,
-			fmt.Sprintf("%s:%d %s", path.Base(file), line, name),
+			fmt.Sprintf("%s:%d %s", capture.filePath(file), line, name),
 		)
 	}
 
@@ -145,36 +296,171 @@ func LogAccessLoki(ctx context.Context, service string, level string, statusCode
  * @param writer Output writer for log entry
  */
 func LogLoki(ctx context.Context, service string, level string, statusCode int, latency time.Duration, err error, writer io.Writer) {
+	ev := buildLokiEvent(ctx, service, level, statusCode, latency, err, 4, defaultCapture)
+	if werr := writeLokiEvent(ev, writer); werr != nil {
+		defaultSelfLog.report("loki.write", werr)
+	}
+}
+
+func buildLokiEvent(ctx context.Context, service string, level string, statusCode int, latency time.Duration, err error, skip int, capture captureOptions) map[string]interface{} {
 	meta, _ := FromContext(ctx)
 
 	ev := map[string]interface{}{
-		"ts":          time.Now().Format(time.RFC3339),
-		"level":       strings.ToUpper(level),
-		"service":     service,
-		"request_id":  meta.RequestID,
-		"status_code": statusCode,
-		"latency_ms":  latency.Milliseconds(),
+		"ts":             time.Now().Format(time.RFC3339),
+		"level":          strings.ToUpper(level),
+		"service":        service,
+		"request_id":     meta.RequestID,
+		"correlation_id": meta.CorrelationID,
+		"status_code":    statusCode,
+		"latency_ms":     latency.Milliseconds(),
+		"latency_us":     latency.Microseconds(),
 		"http": map[string]string{
 			"method": meta.Method,
 			"path":   meta.Path,
+			"route":  meta.Route,
 			"ip":     meta.IP,
 			"ua":     meta.UserAgent,
 		},
 		"errors": nil,
 	}
 
+	// messages logged outside a response (Warn, Debug) have no status
+	if statusCode == 0 {
+		delete(ev, "status_code")
+	}
+
+	if meta.Body != "" {
+		ev["http"].(map[string]string)["body"] = meta.Body
+	}
+
+	if meta.Protocol != "" {
+		ev["http"].(map[string]string)["protocol"] = meta.Protocol
+	}
+
+	if meta.TLSVersion != "" {
+		ev["http"].(map[string]string)["tls_version"] = meta.TLSVersion
+		ev["http"].(map[string]string)["tls_cipher"] = meta.TLSCipher
+	}
+
+	if stats, ok := ResponseStatsFromContext(ctx); ok {
+		ev["response"] = map[string]int64{
+			"bytes":         stats.Bytes,
+			"first_byte_ms": stats.FirstByte.Milliseconds(),
+		}
+		if stats.ClientDisconnected {
+			ev["client_disconnected"] = true
+		}
+		if stats.ContentType != "" {
+			ev["http"].(map[string]string)["content_type"] = stats.ContentType
+		}
+		if stats.CacheStatus != "" {
+			ev["http"].(map[string]string)["cache_status"] = stats.CacheStatus
+		}
+	}
+
 	if err != nil {
-		_, file, line, _ := runtime.Caller(3)
-		ev["errors"] = map[string]interface{}{
-			"error": err.Error(),
-			"source": map[string]interface{}{
-				"file": path.Base(file),
-				"line": line,
-			},
-			"stack": stackFrames(4, 6),
+		skip += capture.skip
+		errs := map[string]interface{}{}
+
+		if me, ok := err.(*messageError); ok {
+			errs["message"] = me.msg
+			err = me.err
+		}
+
+		messages := errorMessages(err)
+		errs["error"] = strings.Join(messages, "; ")
+		errs["messages"] = messages
+
+		if capture.caller {
+			pc, file, line, _ := runtime.Caller(skip)
+			errs["source"] = map[string]interface{}{
+				"file":     capture.filePath(file),
+				"line":     line,
+				"function": funcName(pc),
+			}
 		}
+
+		if capture.stackDepth > 0 {
+			errs["stack"] = stackFrames(skip+1, capture)
+		}
+
+		if fields := ValidationDetails(err); fields != nil {
+			errs["validation"] = fields
+		}
+
+		ev["errors"] = errs
 	}
 
-	b, _ := jsonMarshal(ev)
-	writer.Write(append(b, '\n'))
-}
\ No newline at end of file
+	if stats, ok := ResponseStatsFromContext(ctx); ok && stats.ErrorBody != "" {
+		errs, _ := ev["errors"].(map[string]interface{})
+		if errs == nil {
+			errs = map[string]interface{}{}
+			ev["errors"] = errs
+		}
+		errs["response_body"] = stats.ErrorBody
+	}
+
+	return ev
+}
+
+/**
+ * errorMessages flattens errors combined with errors.Join (including nested joins)
+ * into individual messages. Other errors yield a single message.
+ *
+ * @param err Error to flatten
+ * @return []string One message per accumulated error
+ */
+func errorMessages(err error) []string {
+	multi, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []string{err.Error()}
+	}
+
+	children := multi.Unwrap()
+	direct := make([]string, 0, len(children))
+	for _, e := range children {
+		direct = append(direct, e.Error())
+	}
+
+	// fmt.Errorf with several %w verbs also unwraps to a slice but has its own message
+	if err.Error() != strings.Join(direct, "\n") {
+		return []string{err.Error()}
+	}
+
+	var messages []string
+	for _, e := range children {
+		messages = append(messages, errorMessages(e)...)
+	}
+	return messages
+}
+
+/**
+ * writeLokiEvent encodes ev as one JSON line. Fields added by hooks can be
+ * anything, so a marshal failure is returned instead of writing a broken line.
+ */
+func writeLokiEvent(ev map[string]interface{}, writer io.Writer) error {
+	b, err := jsonMarshal(ev)
+	if err != nil {
+		return fmt.Errorf("failed to encode entry: %w", err)
+	}
+	_, err = writer.Write(append(b, '\n'))
+	return err
+}
+
+/**
+ * messageError pairs an error with a description of what was being attempted.
+ * Error() reads "msg: err" so alerts stay self-explanatory, while the error log
+ * and Loki entry show the description and the raw error separately.
+ */
+type messageError struct {
+	msg string
+	err error
+}
+
+func (e *messageError) Error() string {
+	return e.msg + ": " + e.err.Error()
+}
+
+func (e *messageError) Unwrap() error {
+	return e.err
+}
@@ -50,12 +50,8 @@ func main() {
 		w.Write([]byte("Internal Server Error"))
 	})
 
-	// Apply middleware chain (order matters: Recovery -> Middleware -> Logger)
-	handler := middleware.HTTPRecovery(logger)(
-		middleware.HTTPMiddleware(logger)(
-			middleware.HTTPLogger(logger)(mux),
-		),
-	)
+	// Apply middleware chain: context, logger, recovery
+	handler := middleware.WrapHTTP(logger, mux)
 
 	fmt.Println("Server starting on :8080")
 	fmt.Println("Try: curl http://localhost:8080/ping")
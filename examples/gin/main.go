@@ -29,11 +29,8 @@ func main() {
 	// Setup Gin
 	r := gin.New()
 
-	// Add logging middleware (order matters!)
-	r.Use(middleware.GinMiddleware(logger))      // 1. Setup request context
-	r.Use(middleware.GinLogger(logger))          // 2. Log requests (with consistent Loki format)
-	r.Use(middleware.GinHTTPErrorLogger(logger)) // 3. Log errors to error.log
-	r.Use(middleware.GinRecovery(logger))        // 4. Catch panics last (so logger runs after)
+	// Add logging middleware: context, logger, error logger, recovery
+	middleware.UseGin(r, logger)
 
 	// Routes
 	// Success endpoint - Loki JSON will have errors=null
@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+)
+
+/**
+ * validateFallbacks checks that every key of Config.Fallbacks names a stream
+ * and every path is set.
+ */
+func validateFallbacks(fallbacks map[string]string) error {
+	return validateStreamMap("fallback", fallbacks)
+}
+
+/**
+ * validateStreamMap checks that every key of a per-stream path map names a
+ * stream and every path is set.
+ *
+ * @param kind Map name used in error messages (e.g. "fallback")
+ * @param paths Paths keyed by stream name
+ * @return error First unknown stream or empty path, if any
+ */
+func validateStreamMap(kind string, paths map[string]string) error {
+	for stream, path := range paths {
+		known := false
+		for _, s := range statsStreams {
+			if s == stream {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown %s stream %q (want access, error, loki or debug)", kind, stream)
+		}
+		if path == "" {
+			return fmt.Errorf("%s path for stream %q is empty", kind, stream)
+		}
+	}
+	return nil
+}
+
+/**
+ * fallbackFor returns the secondary sink declared for a stream, opening it on
+ * first use so the file and Loki outputs of one stream share it. It returns nil
+ * when the stream has no fallback, leaving the outputs' defaults (stdout for
+ * files, drop for Loki) in place.
+ *
+ * @param stream Stream name (access, error, loki, debug)
+ * @return io.Writer Fallback writer, or nil
+ * @return error Error if the fallback file cannot be opened
+ */
+func (l *Logger) fallbackFor(stream string) (io.Writer, error) {
+	path, ok := l.config.Fallbacks[stream]
+	if !ok {
+		return nil, nil
+	}
+
+	if w, ok := l.fallbacks[stream]; ok {
+		return w, nil
+	}
+
+	w, err := NewWriter(WriterOptions{
+		BasePath:       path,
+		EnableRotation: l.config.EnableRotation,
+		Interval:       l.config.RotationInterval,
+		MaxSizeBytes:   int64(l.config.MaxSizeMB) * 1024 * 1024,
+		CheckInterval:  l.fileCheckInterval(),
+		OnError:        l.selfLog.report,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s fallback: %w", stream, err)
+	}
+
+	if l.fallbacks == nil {
+		l.fallbacks = make(map[string]*DailyWriter)
+	}
+	l.fallbacks[stream] = w
+	return w, nil
+}
@@ -0,0 +1,122 @@
+package logging
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/**
+ * Format selects how a stream encodes its lines. The empty value keeps the
+ * stream's default: plaintext for the access log, JSON for the Loki stream.
+ */
+type Format string
+
+const (
+	FormatDefault Format = ""
+	FormatJSON    Format = "json"
+	FormatLogfmt  Format = "logfmt"
+	FormatCEF     Format = "cef"
+	FormatLEEF    Format = "leef"
+	FormatCSV     Format = "csv"
+)
+
+type logfmtField struct {
+	key   string
+	value interface{}
+}
+
+/**
+ * encodeLogfmt renders fields as key=value pairs in the given order. Values
+ * containing spaces, quotes, '=' or control characters are quoted.
+ *
+ * @param fields Ordered fields
+ * @return string Single logfmt line without trailing newline
+ */
+func encodeLogfmt(fields []logfmtField) string {
+	var b strings.Builder
+
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(f.key)
+		b.WriteByte('=')
+		b.WriteString(logfmtValue(f.value))
+	}
+
+	return b.String()
+}
+
+func logfmtValue(v interface{}) string {
+	var s string
+	switch val := v.(type) {
+	case string:
+		s = val
+	case float64:
+		s = strconv.FormatFloat(val, 'f', -1, 64)
+	case []string:
+		s = strings.Join(val, "; ")
+	default:
+		s = fmt.Sprint(val)
+	}
+
+	if s == "" || strings.ContainsAny(s, " =\"\\") || strings.IndexFunc(s, func(r rune) bool { return r < 0x20 }) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+/**
+ * flattenEvent turns a Loki event into logfmt fields. Nested maps become dotted
+ * keys (http.method, errors.error), slices are joined with "; " and nil values
+ * (errors on success) are left out. The ts, level and service keys come first,
+ * the rest follow in alphabetical order.
+ *
+ * @param ev Loki event as built by buildLokiEvent
+ * @return []logfmtField Ordered fields
+ */
+func flattenEvent(ev map[string]interface{}) []logfmtField {
+	var fields []logfmtField
+	flattenInto(&fields, "", ev)
+
+	rank := map[string]int{"ts": 0, "level": 1, "service": 2}
+	sort.SliceStable(fields, func(i, j int) bool {
+		ri, iok := rank[fields[i].key]
+		rj, jok := rank[fields[j].key]
+		switch {
+		case iok && jok:
+			return ri < rj
+		case iok != jok:
+			return iok
+		default:
+			return fields[i].key < fields[j].key
+		}
+	})
+
+	return fields
+}
+
+func flattenInto(fields *[]logfmtField, prefix string, v interface{}) {
+	switch val := v.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		for k, child := range val {
+			flattenInto(fields, prefix+k+".", child)
+		}
+	case map[string]string:
+		for k, child := range val {
+			*fields = append(*fields, logfmtField{prefix + k, child})
+		}
+	case map[string]int64:
+		for k, child := range val {
+			*fields = append(*fields, logfmtField{prefix + k, child})
+		}
+	case []string:
+		*fields = append(*fields, logfmtField{strings.TrimSuffix(prefix, "."), strings.Join(val, "; ")})
+	default:
+		*fields = append(*fields, logfmtField{strings.TrimSuffix(prefix, "."), val})
+	}
+}
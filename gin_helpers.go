@@ -1,6 +1,8 @@
 package logging
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,6 +19,25 @@ func SetLoggedError(c *gin.Context, err error) {
 	c.Set("logged_error", err)
 }
 
+/**
+ * AddLoggedError appends an error to the request instead of replacing it.
+ * All accumulated errors are emitted in the Loki entry's errors.messages list.
+ *
+ * @param c Gin context
+ * @param err Error to append
+ */
+func AddLoggedError(c *gin.Context, err error) {
+	if err == nil {
+		return
+	}
+	if prev, exists := c.Get("logged_error"); exists {
+		if prevErr, ok := prev.(error); ok && prevErr != nil {
+			err = errors.Join(prevErr, err)
+		}
+	}
+	c.Set("logged_error", err)
+}
+
 /**
  * LogErrorWithMark logs an error and marks it as logged to prevent duplication.
  * Use this when manually handling errors to avoid double logging in middleware.
@@ -28,4 +49,15 @@ func (l *Logger) LogErrorWithMark(c *gin.Context, err error) {
 	l.Error(c.Request.Context(), err)
 	SetLoggedError(c, err)
 	MarkErrorLogged(c)
-}
\ No newline at end of file
+}
+
+/**
+ * FromGin returns a logger bound to the request context of c, which must have
+ * passed GinMiddleware.
+ *
+ * @param c Gin context
+ * @return *RequestLogger Request-scoped logger, nil outside the middleware
+ */
+func FromGin(c *gin.Context) *RequestLogger {
+	return FromRequest(c.Request)
+}
@@ -0,0 +1,194 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+	"github.com/ahmadsaubani/go-logging-lib/outbound"
+)
+
+const defaultHeartbeatIntervalSec = 60
+
+/**
+ * HeartbeatConfig enables a dead man's switch. Every IntervalSec the logger
+ * checks its own pipeline and, while healthy, requests URL (a healthchecks.io
+ * style ping endpoint that alerts when pings stop). When unhealthy it requests
+ * FailURL instead, if set, and raises a CRITICAL alert through the configured
+ * alerters.
+ *
+ * The pipeline is unhealthy when the Loki sink runs on its fallback, when
+ * writes failed during the interval, or, with RequireActivity, when nothing
+ * was logged. Notify additionally sends an INFO "service alive" message to the
+ * alerters on every beat, bypassing min_level and rate limiting.
+ */
+type HeartbeatConfig struct {
+	URL             string              `yaml:"url"`
+	FailURL         string              `yaml:"fail_url"`
+	IntervalSec     int                 `yaml:"interval_sec"`
+	RequireActivity bool                `yaml:"require_activity"`
+	Notify          bool                `yaml:"notify"`
+	TLS             *outbound.TLSConfig `yaml:"tls,omitempty"`
+	Proxy           string              `yaml:"proxy"`
+}
+
+type heartbeat struct {
+	logger   *Logger
+	config   *HeartbeatConfig
+	client   *http.Client
+	interval time.Duration
+	last     Stats
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+func (c *HeartbeatConfig) interval() time.Duration {
+	if c.IntervalSec <= 0 {
+		return defaultHeartbeatIntervalSec * time.Second
+	}
+	return time.Duration(c.IntervalSec) * time.Second
+}
+
+// startHeartbeat expects TLS and proxy settings already checked by validateOutbound
+func startHeartbeat(l *Logger, config *HeartbeatConfig) *heartbeat {
+	client, _ := outbound.NewHTTPClient(outbound.ClientOptions{
+		TLS:     config.TLS,
+		Proxy:   config.Proxy,
+		Timeout: 10 * time.Second,
+	})
+
+	h := &heartbeat{
+		logger:   l,
+		config:   config,
+		client:   client,
+		interval: config.interval(),
+		last:     l.Stats(),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+func (h *heartbeat) run() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.beat()
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+/**
+ * beat compares the pipeline counters with the previous beat and reports the
+ * result to the ping endpoint and, when unhealthy or Notify is set, the alerters.
+ */
+func (h *heartbeat) beat() {
+	now := h.logger.Stats()
+	problems := h.problems(h.last, now)
+	h.last = now
+
+	if len(problems) == 0 {
+		h.ping(h.config.URL, "")
+		if h.config.Notify && h.logger.alertManager != nil {
+			h.logger.alertManager.Notify(h.payload(string(LevelInfo), "service alive"))
+		}
+		return
+	}
+
+	reason := "logging pipeline unhealthy: " + strings.Join(problems, "; ")
+	h.ping(h.config.FailURL, reason)
+	if h.logger.alertManager != nil {
+		h.logger.alertManager.Alert(h.payload(string(LevelCritical), reason))
+	}
+}
+
+func (h *heartbeat) problems(prev, now Stats) []string {
+	var problems []string
+	if h.logger.lokiSink != nil && h.logger.lokiSink.Stats().OnFallback {
+		problems = append(problems, "loki sink is on its fallback")
+	}
+	if now.WriteErrors > prev.WriteErrors {
+		problems = append(problems, "writes failed")
+	}
+	if h.config.RequireActivity && totalEntries(now) == totalEntries(prev) {
+		problems = append(problems, fmt.Sprintf("nothing logged in the last %s", h.interval))
+	}
+	return problems
+}
+
+func totalEntries(s Stats) uint64 {
+	var total uint64
+	for _, n := range s.Entries {
+		total += n
+	}
+	return total
+}
+
+func (h *heartbeat) payload(level, message string) alerts.Payload {
+	l := h.logger
+	return alerts.Payload{
+		ServiceName:    l.config.ServiceName,
+		ServiceVersion: l.version,
+		Build:          l.build,
+		Environment:    l.config.Environment,
+		Hostname:       l.hostname,
+		Level:          level,
+		Error:          message,
+		Path:           "heartbeat",
+		Timestamp:      time.Now(),
+	}
+}
+
+// ping requests url, as POST with the reason as body when one is given
+func (h *heartbeat) ping(url, reason string) {
+	if url == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.interval)
+	defer cancel()
+
+	method := http.MethodGet
+	if reason != "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(reason))
+	if err != nil {
+		h.logger.selfLog.report("heartbeat", err)
+		return
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		h.logger.selfLog.report("heartbeat", fmt.Errorf("ping failed: %w", err))
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		h.logger.selfLog.report("heartbeat", fmt.Errorf("ping returned status %d", resp.StatusCode))
+	}
+}
+
+/**
+ * Close stops the heartbeat. Pings simply cease, which is what a dead man's
+ * switch reports after its grace period; pause the check before planned
+ * downtime.
+ */
+func (h *heartbeat) Close() error {
+	h.once.Do(func() { close(h.stop) })
+	<-h.done
+	return nil
+}
@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"sync"
+	"time"
+)
+
+/**
+ * Entry is a structured log entry passed through hooks before it is written.
+ * Fields holds the Loki JSON object and is what ends up in the Loki stream;
+ * the other fields describe the request and are informational.
+ */
+type Entry struct {
+	Level      LogLevel
+	StatusCode int
+	Latency    time.Duration
+	Meta       Meta
+	Err        error
+	Fields     map[string]interface{}
+}
+
+/**
+ * Hook inspects or mutates an entry. Returning false drops the entry: it is not
+ * written to the access log or Loki, and no alert is sent for it.
+ */
+type Hook func(*Entry) bool
+
+type hookChain struct {
+	mu      sync.RWMutex
+	hooks   []Hook
+	onError []func(*Entry)
+}
+
+/**
+ * AddHook appends a hook to the pipeline run before every request and Loki entry
+ * is written. Hooks run in registration order and stop at the first that drops
+ * the entry. Loggers obtained from a Registry share the base logger's hooks.
+ *
+ * @param hook Hook to register
+ */
+func (l *Logger) AddHook(hook Hook) {
+	l.hooks.mu.Lock()
+	defer l.hooks.mu.Unlock()
+
+	l.hooks.hooks = append(l.hooks.hooks, hook)
+}
+
+/**
+ * OnError registers a callback fired after an ERROR or CRITICAL entry has been
+ * written, for side effects such as business metrics or kill switches. Callbacks
+ * run synchronously on the logging goroutine, so slow work should be handed off.
+ * Entries dropped by a hook do not fire callbacks.
+ *
+ * @param fn Callback receiving the written entry (must not modify it)
+ */
+func (l *Logger) OnError(fn func(*Entry)) {
+	l.hooks.mu.Lock()
+	defer l.hooks.mu.Unlock()
+
+	l.hooks.onError = append(l.hooks.onError, fn)
+}
+
+func (c *hookChain) run(e *Entry) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, hook := range c.hooks {
+		if !hook(e) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *hookChain) written(e *Entry) {
+	if levelPriority(e.Level) < levelPriority(LevelError) {
+		return
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, fn := range c.onError {
+		fn(e)
+	}
+}
@@ -2,57 +2,148 @@ package logging
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"path"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/ahmadsaubani/go-logging-lib/alerts"
 	"github.com/ahmadsaubani/go-logging-lib/alerts/discord"
 	"github.com/ahmadsaubani/go-logging-lib/alerts/email"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/matrix"
 	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
 	"github.com/ahmadsaubani/go-logging-lib/alerts/telegram"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/webex"
+	"github.com/ahmadsaubani/go-logging-lib/outbound"
+	"github.com/ahmadsaubani/go-logging-lib/sinks/loki"
 )
 
 type LogLevel string
 
 const (
+	LevelTrace    LogLevel = "TRACE"
 	LevelDebug    LogLevel = "DEBUG"
 	LevelInfo     LogLevel = "INFO"
 	LevelWarn     LogLevel = "WARN"
 	LevelError    LogLevel = "ERROR"
 	LevelCritical LogLevel = "CRITICAL"
+	LevelFatal    LogLevel = "FATAL"
 )
 
 type Logger struct {
 	accessLogger *log.Logger
 	errorLogger  *log.Logger
+	debugLogger  *log.Logger
 	lokiWriter   io.Writer
 	config       *Config
 	alertManager *alerts.Manager
+	suppressor   *errorSuppressor
+	hooks        *hookChain
+	callerSkip   int
+	stackDepth   int
+	level        *atomic.Value
+	stats        *pipelineStats
+	selfLog      *selfLog
+	hostname     string
+	version      string
+	build        alerts.Build
+	buildField   map[string]interface{}
+	alertFields  []alerts.Field
+	alertIgnore  *alertIgnore
+	reporter     *reporter
+	excerpt      *excerptBuffer
+	lokiSink     *loki.Writer
+	lokiOutputs  []io.Writer
+	fallbacks    map[string]*DailyWriter
+	files        []*DailyWriter
+	durable      []*DailyWriter
+	debugStream  *lazyStream
+	closers      []io.Closer
+	parent       *Logger // registry loggers flush and close through the base logger
 }
 
 type Config struct {
-	ServiceName    string        `yaml:"service_name"`
-	LogPath        string        `yaml:"log_path"`
-	FilePrefix     string        `yaml:"file_prefix"`
-	EnableStdout   bool          `yaml:"enable_stdout"`
-	EnableFile     bool          `yaml:"enable_file"`
-	EnableLoki     bool          `yaml:"enable_loki"`
-	EnableRotation bool          `yaml:"enable_rotation"`
-	Alerts         *AlertsConfig `yaml:"alerts,omitempty"`
+	ServiceName        string                    `yaml:"service_name"`
+	ServiceVersion     string                    `yaml:"service_version"`
+	Environment        string                    `yaml:"environment"`
+	EnvironmentPrefix  bool                      `yaml:"environment_prefix"`
+	LogPath            string                    `yaml:"log_path"`
+	StreamPaths        map[string]string         `yaml:"stream_paths,omitempty"`
+	FilePrefix         string                    `yaml:"file_prefix"`
+	EnableStdout       bool                      `yaml:"enable_stdout"`
+	EnableFile         bool                      `yaml:"enable_file"`
+	EnableLoki         bool                      `yaml:"enable_loki"`
+	EnableRotation     bool                      `yaml:"enable_rotation"`
+	Serverless         bool                      `yaml:"serverless"`
+	MinLevel           LogLevel                  `yaml:"min_level"`
+	RotationInterval   RotationInterval          `yaml:"rotation_interval"`
+	FileNameTemplate   string                    `yaml:"file_name_template"`
+	FileDateLayout     string                    `yaml:"file_date_layout"`
+	MaxSizeMB          int                       `yaml:"max_size_mb"`
+	SyncOnCritical     bool                      `yaml:"sync_on_critical"`
+	SyncEvery          int                       `yaml:"sync_every"`
+	FileCheckSec       int                       `yaml:"file_check_sec"`
+	DisableFileCheck   bool                      `yaml:"disable_file_check"`
+	ErrorRepeatSec     int                       `yaml:"error_repeat_sec"`
+	FatalExitCode      int                       `yaml:"fatal_exit_code"`
+	EnableConnInfo     bool                      `yaml:"enable_conn_info"`
+	DumpRequestOnPanic bool                      `yaml:"dump_request_on_panic"`
+	RedactHeaders      []string                  `yaml:"redact_headers,omitempty"`
+	AccessLogMinStatus int                       `yaml:"access_log_min_status"`
+	DisableCaller      bool                      `yaml:"disable_caller"`
+	FullPaths          bool                      `yaml:"full_paths"`
+	SourceRoot         string                    `yaml:"source_root"`
+	DisableStack       bool                      `yaml:"disable_stack"`
+	LokiIncludeStack   *bool                     `yaml:"loki_include_stack"`
+	StackDepth         int                       `yaml:"stack_depth"`
+	StackSkip          int                       `yaml:"stack_skip"`
+	StackMinLevel      LogLevel                  `yaml:"stack_min_level"`
+	LevelMapper        func(status int) LogLevel `yaml:"-"`
+	AccessLogFormat    Format                    `yaml:"access_log_format"`
+	AccessLogColumns   []string                  `yaml:"access_log_columns,omitempty"`
+	ErrorLogFormat     Format                    `yaml:"error_log_format"`
+	LokiFormat         Format                    `yaml:"loki_format"`
+	Fallbacks          map[string]string         `yaml:"fallbacks,omitempty"`
+	InternalLog        io.Writer                 `yaml:"-"`
+	InternalLogRateSec int                       `yaml:"internal_log_rate_sec"`
+	Labels             map[string]string         `yaml:"labels,omitempty"`
+	Loki               *loki.Config              `yaml:"loki,omitempty"`
+	Alerts             *AlertsConfig             `yaml:"alerts,omitempty"`
+	Heartbeat          *HeartbeatConfig          `yaml:"heartbeat,omitempty"`
+	Report             *ReportConfig             `yaml:"report,omitempty"`
 }
 
 type AlertsConfig struct {
-	Enabled      bool             `yaml:"enabled"`
-	MinLevel     string           `yaml:"min_level"`
-	RateLimitSec int              `yaml:"rate_limit_sec"`
-	Discord      *discord.Config  `yaml:"discord,omitempty"`
-	Slack        *slack.Config    `yaml:"slack,omitempty"`
-	Telegram     *telegram.Config `yaml:"telegram,omitempty"`
-	Email        *email.Config    `yaml:"email,omitempty"`
+	Enabled             bool                     `yaml:"enabled"`
+	MinLevel            string                   `yaml:"min_level"`
+	RateLimitSec        int                      `yaml:"rate_limit_sec"`
+	FirstOccurrenceOnly bool                     `yaml:"first_occurrence_only"`
+	SummaryIntervalSec  int                      `yaml:"summary_interval_sec"`
+	MaxConcurrentSends  int                      `yaml:"max_concurrent_sends"`
+	DrainTimeoutSec     int                      `yaml:"drain_timeout_sec"`
+	AlwaysAlertPanics   bool                     `yaml:"always_alert_panics"`
+	PathNormalizer      func(path string) string `yaml:"-"`
+	IgnorePatterns      []string                 `yaml:"ignore_patterns,omitempty"`
+	IgnoreErrors        []error                  `yaml:"-"`
+	NoDefaultIgnores    bool                     `yaml:"no_default_ignores"`
+	Links               []alerts.LinkTemplate    `yaml:"links,omitempty"`
+	Excerpt             *ExcerptConfig           `yaml:"excerpt,omitempty"`
+	Discord             *discord.Config          `yaml:"discord,omitempty"`
+	Slack               *slack.Config            `yaml:"slack,omitempty"`
+	Telegram            *telegram.Config         `yaml:"telegram,omitempty"`
+	Matrix              *matrix.Config           `yaml:"matrix,omitempty"`
+	Webex               *webex.Config            `yaml:"webex,omitempty"`
+	Email               *email.Config            `yaml:"email,omitempty"`
 }
 
 /**
@@ -76,27 +167,163 @@ func New(config *Config) (*Logger, error) {
 		}
 	}
 
+	if config.Serverless {
+		config = serverlessConfig(config)
+	}
+
+	if err := validateAccessColumns(config.AccessLogColumns); err != nil {
+		return nil, err
+	}
+	if err := validateFallbacks(config.Fallbacks); err != nil {
+		return nil, err
+	}
+	if err := validateStreamMap("stream_paths", config.StreamPaths); err != nil {
+		return nil, err
+	}
+	if config.FileNameTemplate != "" {
+		if err := validateNameTemplate(config.FileNameTemplate, config.EnableRotation, config.MaxSizeMB > 0); err != nil {
+			return nil, err
+		}
+	}
+	if config.Loki != nil && !config.Loki.Encoding.Valid() {
+		return nil, fmt.Errorf("unknown loki encoding %q", config.Loki.Encoding)
+	}
+	if err := validateOutbound(config); err != nil {
+		return nil, err
+	}
+	if err := validateReport(config); err != nil {
+		return nil, err
+	}
+	if config.Alerts != nil && config.Alerts.Email != nil {
+		if err := email.CheckTemplates(config.Alerts.Email); err != nil {
+			return nil, err
+		}
+	}
+	ignore, err := newAlertIgnore(config.Alerts)
+	if err != nil {
+		return nil, err
+	}
+
 	logger := &Logger{
 		config:       config,
 		alertManager: setupAlertManager(config.Alerts),
+		hooks:        &hookChain{},
+		level:        &atomic.Value{},
+		stats:        newPipelineStats(),
+		alertIgnore:  ignore,
+	}
+	if logger.alertManager != nil && config.Alerts.Excerpt != nil {
+		logger.excerpt = newExcerptBuffer(config.Alerts.Excerpt)
+	}
+
+	minLevel := LogLevel(strings.ToUpper(string(config.MinLevel)))
+	if minLevel == "" {
+		minLevel = LevelInfo
+	}
+	if !validLevel(minLevel) {
+		return nil, fmt.Errorf("unknown min level %q", config.MinLevel)
+	}
+	logger.level.Store(minLevel)
+	logger.hostname, _ = os.Hostname()
+	logger.version, logger.build = readBuild()
+	if config.ServiceVersion != "" {
+		logger.version = config.ServiceVersion
+	}
+	logger.buildField = buildField(logger.build)
+
+	internalLog := config.InternalLog
+	if internalLog == nil {
+		internalLog = os.Stderr
+	}
+	rateSec := config.InternalLogRateSec
+	if rateSec <= 0 {
+		rateSec = defaultSelfLogRateSec
+	}
+	logger.selfLog = newSelfLog(internalLog, time.Duration(rateSec)*time.Second)
+
+	if logger.alertManager != nil {
+		logger.alertManager.SetErrorHandler(logger.selfLog.report)
 	}
 
 	if err := logger.setupWriters(); err != nil {
 		return nil, err
 	}
 
+	if config.Report != nil {
+		logger.reporter = startReporter(logger, config.Report)
+		logger.closers = append([]io.Closer{logger.reporter}, logger.closers...)
+	}
+
+	if config.Heartbeat != nil {
+		logger.closers = append([]io.Closer{startHeartbeat(logger, config.Heartbeat)}, logger.closers...)
+	}
+
 	return logger, nil
 }
 
+/**
+ * validateOutbound loads every configured TLS block and parses every proxy
+ * once, so a missing CA file or a typo fails New instead of each push or
+ * alert later.
+ */
+func validateOutbound(config *Config) error {
+	blocks := map[string]outbound.ClientOptions{}
+	if config.Heartbeat != nil {
+		blocks["heartbeat"] = outbound.ClientOptions{TLS: config.Heartbeat.TLS, Proxy: config.Heartbeat.Proxy}
+	}
+	if config.Loki != nil {
+		blocks["loki"] = outbound.ClientOptions{TLS: config.Loki.TLS, Proxy: config.Loki.Proxy}
+	}
+	if a := config.Alerts; a != nil {
+		if a.Discord != nil {
+			blocks["discord"] = outbound.ClientOptions{TLS: a.Discord.TLS, Proxy: a.Discord.Proxy}
+		}
+		if a.Slack != nil {
+			blocks["slack"] = outbound.ClientOptions{TLS: a.Slack.TLS, Proxy: a.Slack.Proxy}
+		}
+		if a.Telegram != nil {
+			blocks["telegram"] = outbound.ClientOptions{TLS: a.Telegram.TLS, Proxy: a.Telegram.Proxy}
+		}
+		if a.Matrix != nil {
+			blocks["matrix"] = outbound.ClientOptions{TLS: a.Matrix.TLS, Proxy: a.Matrix.Proxy}
+		}
+		if a.Webex != nil {
+			blocks["webex"] = outbound.ClientOptions{TLS: a.Webex.TLS, Proxy: a.Webex.Proxy}
+		}
+		if a.Email != nil {
+			blocks["email"] = outbound.ClientOptions{TLS: a.Email.TLS, Proxy: a.Email.Proxy}
+		}
+	}
+
+	for name, opts := range blocks {
+		if _, err := opts.TLS.Build(); err != nil {
+			return fmt.Errorf("invalid %s TLS config: %w", name, err)
+		}
+		// TLS was checked above; clear it so a TLS error is not reported as a proxy error
+		opts.TLS = nil
+		if opts.Proxy != "" {
+			if _, err := outbound.NewHTTPClient(opts); err != nil {
+				return fmt.Errorf("invalid %s proxy: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
 func setupAlertManager(cfg *AlertsConfig) *alerts.Manager {
 	if cfg == nil || !cfg.Enabled {
 		return nil
 	}
 
 	manager := alerts.NewManager(&alerts.Config{
-		Enabled:      cfg.Enabled,
-		MinLevel:     alerts.LogLevel(cfg.MinLevel),
-		RateLimitSec: cfg.RateLimitSec,
+		Enabled:             cfg.Enabled,
+		MinLevel:            alerts.LogLevel(cfg.MinLevel),
+		RateLimitSec:        cfg.RateLimitSec,
+		PathNormalizer:      cfg.PathNormalizer,
+		FirstOccurrenceOnly: cfg.FirstOccurrenceOnly,
+		SummaryIntervalSec:  cfg.SummaryIntervalSec,
+		MaxConcurrentSends:  cfg.MaxConcurrentSends,
+		Links:               cfg.Links,
 	})
 
 	if cfg.Discord != nil && cfg.Discord.Enabled {
@@ -111,6 +338,14 @@ func setupAlertManager(cfg *AlertsConfig) *alerts.Manager {
 		manager.Register(telegram.New(cfg.Telegram))
 	}
 
+	if cfg.Matrix != nil && cfg.Matrix.Enabled {
+		manager.Register(matrix.New(cfg.Matrix))
+	}
+
+	if cfg.Webex != nil && cfg.Webex.Enabled {
+		manager.Register(webex.New(cfg.Webex))
+	}
+
 	if cfg.Email != nil && cfg.Email.Enabled {
 		manager.Register(email.New(cfg.Email))
 	}
@@ -122,32 +357,33 @@ func (l *Logger) setupWriters() error {
 	var accessWriters []io.Writer
 	var errorWriters []io.Writer
 	var lokiWriters []io.Writer
-
-	filePrefix := l.config.FilePrefix
-	if filePrefix == "" {
-		filePrefix = "app"
-	}
-
-	basePath := l.config.LogPath + "/" + filePrefix
+	var debugWriters []io.Writer
 
 	if l.config.EnableStdout {
 		accessWriters = append(accessWriters, log.Writer())
 		errorWriters = append(errorWriters, log.Writer())
 		lokiWriters = append(lokiWriters, log.Writer())
+		debugWriters = append(debugWriters, log.Writer())
+	}
+
+	if l.config.Serverless {
+		// access and debug lines repeat what the JSON entries already carry
+		errorWriters = append(errorWriters, os.Stdout)
+		lokiWriters = append(lokiWriters, os.Stdout)
 	}
 
 	if l.config.EnableFile {
-		accessWriter, err := NewDailyWriter(basePath+".access", l.config.EnableRotation)
+		accessWriter, err := l.openStream("access", l.accessHeader())
 		if err != nil {
 			return err
 		}
 
-		errorWriter, err := NewDailyWriter(basePath+".error", l.config.EnableRotation)
+		errorWriter, err := l.openStream("error", nil)
 		if err != nil {
 			return err
 		}
 
-		errorLokiWriter, err := NewDailyWriter(basePath+".loki", l.config.EnableRotation)
+		errorLokiWriter, err := l.openStream("loki", nil)
 		if err != nil {
 			return err
 		}
@@ -155,15 +391,327 @@ func (l *Logger) setupWriters() error {
 		accessWriters = append(accessWriters, accessWriter)
 		errorWriters = append(errorWriters, errorWriter)
 		lokiWriters = append(lokiWriters, errorLokiWriter)
+		l.files = append(l.files, accessWriter, errorWriter, errorLokiWriter)
+		l.durable = []*DailyWriter{errorWriter, errorLokiWriter}
+		l.closers = append(l.closers, accessWriter, errorWriter, errorLokiWriter)
+
+		// opened on first write, so the file only appears once DEBUG is enabled
+		l.debugStream = &lazyStream{
+			open: func() (*DailyWriter, error) {
+				return l.openStream("debug", nil)
+			},
+			onError: l.selfLog.report,
+		}
+		debugWriters = append(debugWriters, l.debugStream)
+		l.closers = append(l.closers, l.debugStream)
+	}
+
+	l.lokiOutputs = lokiWriters
+
+	if l.config.EnableLoki && l.config.Loki != nil && l.config.Loki.Enabled {
+		l.lokiSink = loki.New(l.config.Loki)
+		l.lokiSink.SetErrorHandler(l.selfLog.report)
+		fallback, err := l.fallbackFor("loki")
+		if err != nil {
+			return err
+		}
+		if fallback != nil {
+			l.lokiSink.SetFallback(fallback)
+		}
+		lokiWriters = append(lokiWriters, l.lokiSink)
+		l.closers = append(l.closers, l.lokiSink)
+	}
+
+	// closed last: the Loki sink writes its final failed batch here on Close
+	for _, stream := range statsStreams {
+		if _, err := l.fallbackFor(stream); err != nil {
+			return err
+		}
+		if f, ok := l.fallbacks[stream]; ok {
+			l.files = append(l.files, f)
+			l.closers = append(l.closers, f)
+		}
+	}
+
+	accessFlags := log.LstdFlags | log.Lshortfile
+	accessPrefix := ""
+	if l.config.AccessLogFormat == FormatLogfmt || l.config.AccessLogFormat == FormatCSV {
+		accessFlags = 0
+	} else if l.config.EnvironmentPrefix && l.config.Environment != "" {
+		// a prefix would break logfmt and CSV parsing, so only plaintext lines get one
+		accessPrefix = "[" + l.config.Environment + "] "
+		accessFlags |= log.Lmsgprefix
+	}
+
+	l.accessLogger = log.New(l.stats.counting("access", io.MultiWriter(accessWriters...)), accessPrefix, accessFlags)
+	errorFlags := log.LstdFlags | log.Lshortfile
+	if l.singleLineErrors() {
+		errorFlags = 0
+	}
+
+	if l.excerpt != nil {
+		errorWriters = append(errorWriters, l.excerpt)
 	}
+	l.errorLogger = log.New(l.stats.counting("error", io.MultiWriter(errorWriters...)), "", errorFlags)
+	l.debugLogger = log.New(l.stats.counting("debug", io.MultiWriter(debugWriters...)), "", log.LstdFlags|log.Lshortfile)
+	l.lokiWriter = l.stats.counting("loki", io.MultiWriter(lokiWriters...))
 
-	l.accessLogger = log.New(io.MultiWriter(accessWriters...), "", log.LstdFlags|log.Lshortfile)
-	l.errorLogger = log.New(io.MultiWriter(errorWriters...), "", log.LstdFlags|log.Lshortfile)
-	l.lokiWriter = io.MultiWriter(lokiWriters...)
+	if l.config.ErrorRepeatSec > 0 {
+		l.suppressor = newErrorSuppressor(time.Duration(l.config.ErrorRepeatSec)*time.Second, l.errorLogger, l.config.ErrorLogFormat)
+		l.closers = append([]io.Closer{l.suppressor}, l.closers...)
+	}
 
 	return nil
 }
 
+/**
+ * fileCheckInterval is how often log files are checked for having been moved
+ * or deleted: FileCheckSec (default 10s), or never with DisableFileCheck.
+ */
+func (l *Logger) fileCheckInterval() time.Duration {
+	if l.config.DisableFileCheck {
+		return 0
+	}
+	if l.config.FileCheckSec > 0 {
+		return time.Duration(l.config.FileCheckSec) * time.Second
+	}
+	return 10 * time.Second
+}
+
+/**
+ * streamDir returns the directory a stream's files are written to:
+ * its StreamPaths entry, or LogPath when it has none.
+ *
+ * @param stream Stream name (access, error, loki, debug)
+ * @return string Directory for the stream
+ */
+func (c *Config) streamDir(stream string) string {
+	if dir, ok := c.StreamPaths[stream]; ok {
+		return dir
+	}
+	return c.LogPath
+}
+
+func (l *Logger) openStream(stream string, header []byte) (*DailyWriter, error) {
+	fallback, err := l.fallbackFor(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	filePrefix := l.config.FilePrefix
+	if filePrefix == "" {
+		filePrefix = "app"
+	}
+
+	// access and debug lines are high-volume and cheap to lose, not worth an fsync
+	syncEvery := 0
+	if stream == "error" || stream == "loki" {
+		syncEvery = l.config.SyncEvery
+	}
+
+	return NewWriter(WriterOptions{
+		BasePath:       filepath.Join(l.config.streamDir(stream), filePrefix+"."+stream),
+		Fallback:       fallback,
+		EnableRotation: l.config.EnableRotation,
+		Interval:       l.config.RotationInterval,
+		NameTemplate:   l.config.FileNameTemplate,
+		Stream:         stream,
+		DateLayout:     l.config.FileDateLayout,
+		MaxSizeBytes:   int64(l.config.MaxSizeMB) * 1024 * 1024,
+		SyncEvery:      syncEvery,
+		CheckInterval:  l.fileCheckInterval(),
+		Header:         header,
+		OnError:        l.selfLog.report,
+	})
+}
+
+/**
+ * Close gives in-flight alerts up to Alerts.DrainTimeoutSec (default 5) seconds
+ * to be delivered, cancels the rest, then flushes remote sinks and closes all
+ * log files opened by the logger. On a Registry logger it does nothing: the
+ * writers and alert manager belong to the registry, see Registry.Close.
+ *
+ * @return error First error encountered while closing, if any
+ */
+func (l *Logger) Close() error {
+	if l.parent != nil {
+		return nil
+	}
+
+	var firstErr error
+
+	if l.alertManager != nil {
+		timeout := l.drainTimeout()
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		if err := l.alertManager.Close(ctx); err != nil {
+			l.selfLog.report("alerts", fmt.Errorf("alerts still in flight after %s were cancelled: %w", timeout, err))
+		}
+		cancel()
+	}
+
+	for _, c := range l.closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+/**
+ * drainTimeout is how long in-flight alerts may still be delivered on Close
+ * and Drain: Alerts.DrainTimeoutSec, 5 seconds by default.
+ */
+func (l *Logger) drainTimeout() time.Duration {
+	if l.config.Alerts != nil && l.config.Alerts.DrainTimeoutSec > 0 {
+		return time.Duration(l.config.Alerts.DrainTimeoutSec) * time.Second
+	}
+	return 5 * time.Second
+}
+
+/**
+ * Reopen re-opens all log files at their current paths.
+ * Intended for logrotate setups that move files away instead of truncating them.
+ *
+ * @return error First error encountered while re-opening, if any
+ */
+func (l *Logger) Reopen() error {
+	var firstErr error
+
+	for _, f := range l.files {
+		if err := f.Reopen(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if l.debugStream != nil {
+		if err := l.debugStream.Reopen(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+/**
+ * Flush pushes entries queued for remote sinks and syncs log files to disk.
+ * Useful before a planned shutdown step or when inspecting files live.
+ *
+ * @return error First error encountered while flushing, if any
+ */
+func (l *Logger) Flush() error {
+	if l.parent != nil {
+		return l.parent.Flush()
+	}
+
+	var firstErr error
+
+	for _, c := range l.closers {
+		f, ok := c.(interface{ Flush() error })
+		if !ok {
+			continue
+		}
+		if err := f.Flush(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+/**
+ * ReopenOnSignal re-opens log files whenever one of the given signals arrives.
+ * Defaults to SIGHUP, the signal logrotate's postrotate scripts usually send.
+ *
+ * @param sigs Signals that trigger a reopen (SIGHUP if empty)
+ * @return func() Stop function that unregisters the handler
+ */
+func (l *Logger) ReopenOnSignal(sigs ...os.Signal) func() {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				if err := l.Reopen(); err != nil {
+					l.errorLogger.Printf("[CRITICAL] err=failed to reopen log files: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+/**
+ * WithCallerSkip returns a logger that skips n additional stack frames when
+ * resolving the error source, so helpers wrapping the logger report their
+ * caller in errors.source, the error log and alert File:Line instead of
+ * themselves. The returned logger shares writers with l; close only l.
+ *
+ * @param n Number of wrapper frames to skip
+ * @return *Logger Logger with adjusted caller resolution
+ */
+func (l *Logger) WithCallerSkip(n int) *Logger {
+	clone := *l
+	clone.callerSkip += n
+	return &clone
+}
+
+/**
+ * WithStackDepth returns a logger capturing n stack frames per error, for call
+ * sites where the configured depth cuts off the interesting application frames.
+ * The returned logger shares writers with l; close only l.
+ *
+ * @param n Number of frames to capture
+ * @return *Logger Logger with adjusted stack depth
+ */
+func (l *Logger) WithStackDepth(n int) *Logger {
+	clone := *l
+	clone.stackDepth = n
+	return &clone
+}
+
+/**
+ * WithTenant returns a logger whose Loki pushes carry tenant as X-Scope-OrgID,
+ * overriding Loki.TenantID. Files and stdout are unaffected. The returned
+ * logger shares writers with l; close only l.
+ *
+ * @param tenant Loki tenant ID
+ * @return *Logger Logger pushing to the given tenant
+ */
+func (l *Logger) WithTenant(tenant string) *Logger {
+	clone := *l
+	if l.lokiSink != nil {
+		writers := append(append([]io.Writer{}, l.lokiOutputs...), tenantWriter{sink: l.lokiSink, tenant: tenant})
+		clone.lokiWriter = l.stats.counting("loki", io.MultiWriter(writers...))
+	}
+	return &clone
+}
+
+type tenantWriter struct {
+	sink   *loki.Writer
+	tenant string
+}
+
+func (t tenantWriter) Write(p []byte) (int, error) {
+	return t.sink.WriteTenant(t.tenant, p)
+}
+
 func (l *Logger) GetAccessLogger() *log.Logger {
 	return l.accessLogger
 }
@@ -176,14 +724,180 @@ func (l *Logger) GetLokiWriter() io.Writer {
 	return l.lokiWriter
 }
 
+func (l *Logger) GetDebugLogger() *log.Logger {
+	return l.debugLogger
+}
+
 func (l *Logger) GetServiceName() string {
 	return l.config.ServiceName
 }
 
+/**
+ * ConnInfoEnabled reports whether middleware should record the HTTP protocol
+ * and TLS details of each request (Config.EnableConnInfo).
+ *
+ * @return bool True if connection metadata is recorded
+ */
+func (l *Logger) ConnInfoEnabled() bool {
+	return l.config.EnableConnInfo
+}
+
+/**
+ * Info writes a message to the access stream. Dropped if Config.MinLevel is
+ * above INFO.
+ *
+ * @param msg Message to log
+ */
 func (l *Logger) Info(msg string) {
+	if !l.Enabled(LevelInfo) {
+		return
+	}
+	l.stats.entry(LevelInfo)
+	if l.config.AccessLogFormat == FormatCSV {
+		l.accessLogger.Print(l.csvRow(accessRecord{level: LevelInfo, msg: msg}))
+		return
+	}
+	if l.config.AccessLogFormat == FormatLogfmt {
+		l.accessLogger.Print(encodeLogfmt([]logfmtField{
+			{"ts", time.Now().Format(time.RFC3339)},
+			{"level", LevelInfo},
+			{"msg", msg},
+		}))
+		return
+	}
 	l.accessLogger.Printf("[INFO] %s", msg)
 }
 
+/**
+ * Warn writes a request-scoped warning to the access stream and Loki.
+ * Dropped if Config.MinLevel is above WARN.
+ *
+ * @param ctx Context containing request metadata (may carry none)
+ * @param msg Message to log
+ */
+func (l *Logger) Warn(ctx context.Context, msg string) {
+	l.logMessage(ctx, LevelWarn, msg, l.accessLogger)
+}
+
+/**
+ * Debug writes a request-scoped diagnostic message to the debug stream and Loki.
+ * Messages are dropped unless Config.MinLevel is DEBUG or TRACE.
+ *
+ * @param ctx Context containing request metadata (may carry none)
+ * @param msg Message to log
+ */
+func (l *Logger) Debug(ctx context.Context, msg string) {
+	l.logMessage(ctx, LevelDebug, msg, l.debugLogger)
+}
+
+/**
+ * Trace writes a fine-grained diagnostic message to the debug stream and Loki.
+ * Messages are dropped unless Config.MinLevel is TRACE.
+ *
+ * @param ctx Context containing request metadata (may carry none)
+ * @param msg Message to log
+ */
+func (l *Logger) Trace(ctx context.Context, msg string) {
+	l.logMessage(ctx, LevelTrace, msg, l.debugLogger)
+}
+
+/**
+ * logMessage writes a leveled message that is not tied to a response: the Loki
+ * entry carries the message and no status_code, and the text line is prefixed
+ * with the request ID, method and path when ctx has Meta.
+ */
+func (l *Logger) logMessage(ctx context.Context, level LogLevel, msg string, out *log.Logger) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	if !l.writeLokiFields(ctx, string(level), 0, 0, nil, 3, map[string]interface{}{"message": msg}) {
+		return
+	}
+
+	meta, ok := FromContext(ctx)
+
+	if out == l.accessLogger && l.config.AccessLogFormat == FormatCSV {
+		out.Print(l.csvRow(accessRecord{level: level, meta: meta, msg: msg}))
+		return
+	}
+
+	if out == l.accessLogger && l.config.AccessLogFormat == FormatLogfmt {
+		out.Print(encodeLogfmt([]logfmtField{
+			{"ts", time.Now().Format(time.RFC3339)},
+			{"level", level},
+			{"request_id", meta.RequestID},
+			{"method", meta.Method},
+			{"path", meta.Path},
+			{"msg", msg},
+		}))
+		return
+	}
+
+	line := fmt.Sprintf("[%s] %s", level, msg)
+	if ok {
+		line = fmt.Sprintf("[%s] [REQ:%s] %-7s %s | %s", level, meta.RequestID, meta.Method, meta.Path, msg)
+	}
+	out.Output(3, line)
+}
+
+/**
+ * Enabled reports whether entries at the given level pass the minimum level
+ * (Config.MinLevel, or the last SetLevel). An empty MinLevel defaults to INFO.
+ *
+ * @param level Level to check
+ * @return bool True if the level is at or above the configured minimum
+ */
+func (l *Logger) Enabled(level LogLevel) bool {
+	return levelPriority(level) >= levelPriority(l.Level())
+}
+
+/**
+ * Level returns the current minimum level.
+ *
+ * @return LogLevel Minimum level
+ */
+func (l *Logger) Level() LogLevel {
+	return l.level.Load().(LogLevel)
+}
+
+/**
+ * SetLevel changes the minimum level at runtime, e.g. to switch on DEBUG while
+ * investigating an incident. Loggers from the same Registry share the level.
+ *
+ * @param level New minimum level (case-insensitive)
+ * @return error Error if the level is unknown
+ */
+func (l *Logger) SetLevel(level LogLevel) error {
+	level = LogLevel(strings.ToUpper(string(level)))
+	if !validLevel(level) {
+		return fmt.Errorf("unknown level %q", level)
+	}
+	l.level.Store(level)
+	return nil
+}
+
+func validLevel(level LogLevel) bool {
+	switch level {
+	case LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelCritical, LevelFatal:
+		return true
+	}
+	return false
+}
+
+func levelPriority(level LogLevel) int {
+	priorities := map[LogLevel]int{
+		LevelTrace:    0,
+		LevelDebug:    1,
+		LevelInfo:     2,
+		LevelWarn:     3,
+		LevelError:    4,
+		LevelCritical: 5,
+		LevelFatal:    6,
+	}
+	return priorities[LogLevel(strings.ToUpper(string(level)))]
+}
+
 func (l *Logger) Access(msg string) {
 	l.accessLogger.Printf("%s", msg)
 }
@@ -202,7 +916,7 @@ func (l *Logger) LogRequest(ctx context.Context, statusCode int, latency time.Du
 
 /**
  * LogRequestWithError logs an HTTP request with optional error for non-Gin usage.
- * Automatically determines log level based on status code and triggers alerts.
+ * Determines the log level from the status code via LevelForStatus and triggers alerts.
  *
  * @param ctx Context containing request metadata
  * @param statusCode HTTP response status code
@@ -210,41 +924,305 @@ func (l *Logger) LogRequest(ctx context.Context, statusCode int, latency time.Du
  * @param err Optional error to include in log
  */
 func (l *Logger) LogRequestWithError(ctx context.Context, statusCode int, latency time.Duration, err error) {
+	l.logRequest(ctx, l.LevelForStatus(statusCode), statusCode, latency, err, 3, nil)
+}
+
+/**
+ * DefaultLevelMapper maps 5xx to CRITICAL, 4xx to ERROR, 3xx to WARN and
+ * everything else to INFO.
+ *
+ * @param status HTTP response status code
+ * @return LogLevel Level for the request entry
+ */
+func DefaultLevelMapper(status int) LogLevel {
+	switch {
+	case status >= 500:
+		return LevelCritical
+	case status >= 400:
+		return LevelError
+	case status >= 300:
+		return LevelWarn
+	}
+	return LevelInfo
+}
+
+/**
+ * LevelForStatus returns the level for request entries with this status. It
+ * uses Config.LevelMapper when set, falling back to DefaultLevelMapper when
+ * there is none or it returns an unknown level. The level also decides
+ * whether a request error raises an alert.
+ *
+ * @param status HTTP response status code
+ * @return LogLevel Level for the request entry
+ */
+func (l *Logger) LevelForStatus(status int) LogLevel {
+	if l.config.LevelMapper != nil {
+		if level := l.config.LevelMapper(status); validLevel(level) {
+			return level
+		}
+	}
+	return DefaultLevelMapper(status)
+}
+
+/**
+ * LogRequestWithLevel logs an HTTP request like LogRequestWithError but with an
+ * explicit level instead of one derived from the status code.
+ *
+ * @param ctx Context containing request metadata
+ * @param level Log severity level to record
+ * @param statusCode HTTP response status code
+ * @param latency Request processing duration
+ * @param err Optional error to include in log
+ */
+func (l *Logger) LogRequestWithLevel(ctx context.Context, level LogLevel, statusCode int, latency time.Duration, err error) {
+	l.logRequest(ctx, level, statusCode, latency, err, 3, nil)
+}
+
+/**
+ * RequestLogOptions describes a request for LogRequestWithOptions. Only
+ * Status is required; zero values leave the rest out.
+ *
+ * Level defaults to LevelForStatus(Status). Route overrides Meta.Route, e.g.
+ * for routers the middleware cannot read templates from. BytesIn is emitted
+ * as request.bytes and BytesOut as response.bytes. Fields are added to the
+ * Loki entry as top-level keys.
+ */
+type RequestLogOptions struct {
+	Status   int
+	Latency  time.Duration
+	Err      error
+	Level    LogLevel
+	Route    string
+	BytesIn  int64
+	BytesOut int64
+	Fields   map[string]interface{}
+}
+
+/**
+ * LogRequestWithOptions logs an HTTP request like LogRequestWithError, taking
+ * its details as a struct so new ones can be added without new signatures.
+ *
+ * @param ctx Context containing request metadata
+ * @param opts Status, latency, error and optional extras
+ */
+func (l *Logger) LogRequestWithOptions(ctx context.Context, opts RequestLogOptions) {
+	level := opts.Level
+	if level == "" {
+		level = l.LevelForStatus(opts.Status)
+	}
+
+	if meta, ok := FromContext(ctx); ok && opts.Route != "" {
+		meta.Route = opts.Route
+		ctx = WithMeta(ctx, meta)
+	}
+
+	if opts.BytesOut > 0 {
+		stats, _ := ResponseStatsFromContext(ctx)
+		stats.Bytes = opts.BytesOut
+		ctx = WithResponseStats(ctx, stats)
+	}
+
+	fields := make(map[string]interface{}, len(opts.Fields)+1)
+	for k, v := range opts.Fields {
+		fields[k] = v
+	}
+	if opts.BytesIn > 0 {
+		fields["request"] = map[string]int64{"bytes": opts.BytesIn}
+	}
+
+	l.logRequest(ctx, level, opts.Status, opts.Latency, opts.Err, 3, fields)
+}
+
+func (l *Logger) logRequest(ctx context.Context, level LogLevel, statusCode int, latency time.Duration, err error, skip int, fields map[string]interface{}) {
 	meta, ok := FromContext(ctx)
 	if !ok {
 		return
 	}
 
-	logLine := fmt.Sprintf(
-		"[REQ:%s] %s | %3d | %13v | %15s | %-7s %s",
-		meta.RequestID,
-		time.Now().Format(time.RFC3339),
-		statusCode,
-		latency,
-		meta.IP,
-		meta.Method,
-		meta.Path,
-	)
-	l.accessLogger.Printf("%s", logLine)
-
-	level := LevelInfo
-	if statusCode >= 500 {
+	if l.alwaysAlert(err) {
 		level = LevelCritical
-	} else if statusCode >= 400 {
-		level = LevelError
-	} else if statusCode >= 300 {
-		level = LevelWarn
 	}
 
-	LogLoki(ctx, l.config.ServiceName, string(level), statusCode, latency, err, l.lokiWriter)
+	if !l.writeLokiFields(ctx, string(level), statusCode, latency, err, skip+1, fields) {
+		return
+	}
+	if l.reporter != nil {
+		l.reporter.request(level, latency)
+	}
+
+	if statusCode >= l.config.AccessLogMinStatus {
+		switch l.config.AccessLogFormat {
+		case FormatCSV:
+			record := accessRecord{level: level, meta: meta, status: statusCode, latency: latency, err: err}
+			if stats, ok := ResponseStatsFromContext(ctx); ok {
+				record.bytes = stats.Bytes
+			}
+			l.accessLogger.Print(l.csvRow(record))
+		case FormatLogfmt:
+			l.accessLogger.Print(encodeLogfmt([]logfmtField{
+				{"ts", time.Now().Format(time.RFC3339)},
+				{"level", level},
+				{"request_id", meta.RequestID},
+				{"status", statusCode},
+				{"latency_ms", latency.Milliseconds()},
+				{"latency_us", latency.Microseconds()},
+				{"ip", meta.IP},
+				{"method", meta.Method},
+				{"path", meta.Path},
+			}))
+		default:
+			logLine := fmt.Sprintf(
+				"[REQ:%s] %s | %3d | %13v | %15s | %-7s %s",
+				meta.RequestID,
+				time.Now().Format(time.RFC3339),
+				statusCode,
+				latency,
+				meta.IP,
+				meta.Method,
+				meta.Path,
+			)
+			l.accessLogger.Printf("%s", logLine)
+		}
+	}
 
 	if err != nil {
-		l.sendAlert(ctx, string(level), err)
+		l.sendAlert(ctx, string(level), err, 3)
 	}
 }
 
+/**
+ * Error writes a detailed error block to the error log.
+ * When ErrorRepeatSec is set, repeats of the same error on the same route
+ * within the window are collapsed into a single summary line.
+ *
+ * @param ctx Context containing request metadata
+ * @param err Error to log
+ */
 func (l *Logger) Error(ctx context.Context, err error) {
-	LogError(ctx, err, l.errorLogger)
+	if err == nil {
+		return
+	}
+
+	if l.suppressor != nil && !l.suppressor.allow(errorFingerprint(ctx, err), err.Error()) {
+		l.stats.suppressed.Add(1)
+		return
+	}
+
+	l.stats.entry(LevelError)
+	logError(ctx, err, LevelError, l.errorLogger, l.capture(LevelError), l.config.ErrorLogFormat, 2)
+}
+
+/**
+ * ErrorMsg logs an error together with a description of what was being attempted.
+ * Writes the error block (with a MSG line) and a Loki entry carrying
+ * errors.message, and triggers an alert.
+ *
+ * @param ctx Context containing request metadata
+ * @param msg Human description, e.g. "failed to charge card"
+ * @param err Error to log
+ */
+func (l *Logger) ErrorMsg(ctx context.Context, msg string, err error) {
+	l.errorMsg(ctx, msg, err, 2)
+}
+
+/**
+ * Errorf is ErrorMsg with a formatted description. The error is taken from the
+ * last argument: logger.Errorf(ctx, "failed to charge card %s", cardID, err).
+ * If the last argument is not an error, the formatted message is logged as one.
+ *
+ * @param ctx Context containing request metadata
+ * @param format Format of the description
+ * @param args Format arguments followed by the error
+ */
+func (l *Logger) Errorf(ctx context.Context, format string, args ...interface{}) {
+	// the trailing error is not a format operand: only the arguments before it are formatted
+	fmtArgs := args
+	if n := len(args); n > 0 {
+		if err, ok := args[n-1].(error); ok {
+			fmtArgs = args[:n-1]
+			l.errorMsg(ctx, fmt.Sprintf(format, fmtArgs...), err, 2)
+			return
+		}
+	}
+
+	l.errorMsg(ctx, "", errors.New(fmt.Sprintf(format, fmtArgs...)), 2)
+}
+
+/**
+ * errorMsg is shared by ErrorMsg and Errorf; skip counts the frames from here
+ * to the application call site.
+ */
+func (l *Logger) errorMsg(ctx context.Context, msg string, err error, skip int) {
+	if err == nil {
+		return
+	}
+
+	if msg != "" {
+		err = &messageError{msg: msg, err: err}
+	}
+
+	if l.suppressor != nil && !l.suppressor.allow(errorFingerprint(ctx, err), err.Error()) {
+		l.stats.suppressed.Add(1)
+	} else {
+		logError(ctx, err, LevelError, l.errorLogger, l.capture(LevelError), l.config.ErrorLogFormat, skip+1)
+	}
+
+	if !l.writeLoki(ctx, string(LevelError), 500, 0, err, skip+2) {
+		return
+	}
+
+	l.sendAlert(ctx, string(LevelError), err, skip+1)
+}
+
+/**
+ * capture returns the caller and stack settings derived from the config.
+ * StackDepth defaults to 6 frames (WithStackDepth overrides it per logger);
+ * StackSkip drops frames at the top of the stack; DisableStack turns stack
+ * walking off entirely. Entries below StackMinLevel (default ERROR) get no stack.
+ *
+ * @param level Level of the entry being written
+ * @return captureOptions Capture settings for the entry
+ */
+func (l *Logger) capture(level LogLevel) captureOptions {
+	depth := l.config.StackDepth
+	if l.stackDepth > 0 {
+		depth = l.stackDepth
+	}
+
+	opts := captureOptions{
+		caller:     !l.config.DisableCaller,
+		stackDepth: depth,
+		stackSkip:  l.config.StackSkip,
+		skip:       l.callerSkip,
+		fullPaths:  l.config.FullPaths,
+		sourceRoot: l.config.SourceRoot,
+	}
+
+	if opts.stackDepth <= 0 {
+		opts.stackDepth = defaultStackDepth
+	}
+
+	stackMinLevel := l.config.StackMinLevel
+	if stackMinLevel == "" {
+		stackMinLevel = LevelError
+	}
+
+	if l.config.DisableStack || levelPriority(level) < levelPriority(stackMinLevel) {
+		opts.stackDepth = 0
+	}
+
+	return opts
+}
+
+func errorFingerprint(ctx context.Context, err error) string {
+	meta, _ := FromContext(ctx)
+
+	route := meta.Path
+	if meta.Route != "" {
+		route = meta.Route
+	}
+
+	return meta.Method + " " + route + " " + err.Error()
 }
 
 /**
@@ -255,13 +1233,48 @@ func (l *Logger) Error(ctx context.Context, err error) {
  * @param err Error to log
  */
 func (l *Logger) ErrorLoki(ctx context.Context, level LogLevel, err error) {
-	LogErrorLoki(ctx, l.config.ServiceName, string(level), err, l.lokiWriter)
+	if !l.writeLoki(ctx, string(level), 500, 0, err, 3) {
+		return
+	}
+
+	l.sendAlert(ctx, string(level), err, 2)
+}
+
+/**
+ * Fatal logs err at FATAL level (error block, Loki entry and an alert that
+ * bypasses rate limiting), then flushes and closes the logger, which gives
+ * in-flight alerts up to Alerts.DrainTimeoutSec to be delivered, and exits
+ * the process with Config.FatalExitCode (default 1). Deferred functions do not
+ * run. A nil err skips the logging but still exits.
+ *
+ * @param ctx Context containing request metadata (may carry none)
+ * @param err Error that ends the process
+ */
+func (l *Logger) Fatal(ctx context.Context, err error) {
+	if err != nil {
+		logError(ctx, err, LevelFatal, l.errorLogger, l.capture(LevelFatal), l.config.ErrorLogFormat, 2)
+		if l.writeLoki(ctx, string(LevelFatal), 500, 0, err, 3) {
+			l.sendAlert(ctx, string(LevelFatal), err, 2)
+		}
+	}
+
+	owner := l
+	if l.parent != nil {
+		// the process exits, so the shared writers go with it
+		owner = l.parent
+	}
+	_ = owner.Flush()
+	_ = owner.Close()
 
-	l.sendAlert(ctx, string(level), err)
+	code := l.config.FatalExitCode
+	if code == 0 {
+		code = 1
+	}
+	os.Exit(code)
 }
 
 func (l *Logger) AccessLoki(ctx context.Context, level LogLevel, statusCode int, latency time.Duration) {
-	LogAccessLoki(ctx, l.config.ServiceName, string(level), statusCode, latency, l.lokiWriter)
+	l.writeLoki(ctx, string(level), statusCode, latency, nil, 4)
 }
 
 /**
@@ -275,60 +1288,215 @@ func (l *Logger) AccessLoki(ctx context.Context, level LogLevel, statusCode int,
  * @param err Optional error to include
  */
 func (l *Logger) Loki(ctx context.Context, level LogLevel, statusCode int, latency time.Duration, err error) {
-	LogLoki(ctx, l.config.ServiceName, string(level), statusCode, latency, err, l.lokiWriter)
+	if l.writeLoki(ctx, string(level), statusCode, latency, err, 3) && err != nil {
+		l.sendAlert(ctx, string(level), err, 2)
+	}
+}
 
-	if err != nil {
-		l.sendAlert(ctx, string(level), err)
+/**
+ * writeLoki builds a Loki entry, enriches it with the configured static labels and
+ * runs the hook pipeline. The skip value is passed to runtime.Caller to resolve the
+ * error source frame. Returns false if a hook dropped the entry.
+ */
+func (l *Logger) writeLoki(ctx context.Context, level string, statusCode int, latency time.Duration, err error, skip int) bool {
+	return l.writeLokiFields(ctx, level, statusCode, latency, err, skip+1, nil)
+}
+
+/**
+ * writeLokiFields is writeLoki with extra top-level fields merged into the entry.
+ */
+func (l *Logger) writeLokiFields(ctx context.Context, level string, statusCode int, latency time.Duration, err error, skip int, fields map[string]interface{}) bool {
+	capture := l.capture(LogLevel(level))
+	if !l.lokiIncludeStack() {
+		capture.stackDepth = 0
+	}
+	ev := buildLokiEvent(ctx, l.config.ServiceName, level, statusCode, latency, err, skip, capture)
+
+	if len(l.config.Labels) > 0 {
+		ev["labels"] = l.config.Labels
+	}
+	if l.config.Environment != "" {
+		ev["environment"] = l.config.Environment
+	}
+	if l.version != "" {
+		ev["version"] = l.version
+	}
+	ev["build"] = l.buildField
+
+	for k, v := range fields {
+		ev[k] = v
+	}
+
+	meta, _ := FromContext(ctx)
+	entry := &Entry{
+		Level:      LogLevel(strings.ToUpper(level)),
+		StatusCode: statusCode,
+		Latency:    latency,
+		Meta:       meta,
+		Err:        err,
+		Fields:     ev,
+	}
+	if !l.hooks.run(entry) {
+		l.stats.dropped.Add(1)
+		return false
+	}
+	l.stats.entry(entry.Level)
+	if l.reporter != nil && err != nil && levelPriority(entry.Level) >= levelPriority(LevelError) {
+		l.reporter.error(err)
+	}
+
+	switch l.config.LokiFormat {
+	case FormatLogfmt:
+		l.lokiWriter.Write([]byte(encodeLogfmt(flattenEvent(entry.Fields)) + "\n"))
+	case FormatCEF:
+		l.lokiWriter.Write([]byte(encodeCEF(entry.Fields) + "\n"))
+	case FormatLEEF:
+		l.lokiWriter.Write([]byte(encodeLEEF(entry.Fields) + "\n"))
+	default:
+		if err := writeLokiEvent(entry.Fields, l.lokiWriter); err != nil {
+			l.selfLog.report("loki.encode", err)
+		}
+	}
+	if levelPriority(entry.Level) >= levelPriority(LevelCritical) && l.config.SyncOnCritical {
+		l.syncDurable()
 	}
+	l.hooks.written(entry)
+	return true
 }
 
-func (l *Logger) sendAlert(ctx context.Context, level string, err error) {
+/**
+ * lokiIncludeStack reports whether Loki entries carry errors.stack:
+ * Config.LokiIncludeStack, true when unset.
+ */
+func (l *Logger) lokiIncludeStack() bool {
+	return l.config.LokiIncludeStack == nil || *l.config.LokiIncludeStack
+}
+
+/**
+ * syncDurable fsyncs the error and Loki files, so a CRITICAL entry and the
+ * error block written before it survive a crash right after.
+ */
+func (l *Logger) syncDurable() {
+	for _, f := range l.durable {
+		if err := f.Flush(); err != nil {
+			l.selfLog.report("writer", fmt.Errorf("sync %s: %w", f.basePath, err))
+		}
+	}
+}
+
+func (l *Logger) sendAlert(ctx context.Context, level string, err error, skip int) {
 	if l.alertManager == nil || err == nil {
 		return
 	}
+	if l.alertIgnore.match(err) {
+		l.stats.alertsIgnored.Add(1)
+		return
+	}
 
 	meta, _ := FromContext(ctx)
+	capture := l.capture(LogLevel(level))
+	skip += capture.skip
+
+	file, line := "", 0
+	if capture.caller {
+		_, f, ln, _ := runtime.Caller(skip)
+		file, line = capture.filePath(f), ln
+	}
 
-	_, file, line, _ := runtime.Caller(2)
+	var stack []string
+	if capture.stackDepth > 0 {
+		stack = stackFrames(skip+1, capture)
+	}
 
 	payload := alerts.Payload{
-		ServiceName: l.config.ServiceName,
-		Level:       level,
-		Error:       err.Error(),
-		RequestID:   meta.RequestID,
-		Method:      meta.Method,
-		Path:        meta.Path,
-		IP:          meta.IP,
-		UserAgent:   meta.UserAgent,
-		File:        path.Base(file),
-		Line:        line,
-		Stack:       getStackFrames(3, 6),
-		Timestamp:   time.Now(),
+		ServiceName:    l.config.ServiceName,
+		ServiceVersion: l.version,
+		Build:          l.build,
+		Environment:    l.config.Environment,
+		Hostname:       l.hostname,
+		Level:          level,
+		Error:          err.Error(),
+		RequestID:      meta.RequestID,
+		Method:         meta.Method,
+		Path:           meta.Path,
+		Route:          meta.Route,
+		IP:             meta.IP,
+		UserAgent:      meta.UserAgent,
+		File:           file,
+		Line:           line,
+		Stack:          stack,
+		Fields:         mergeAlertFields(l.alertFields, AlertFieldsFromContext(ctx)),
+		Timestamp:      time.Now(),
+	}
+	if dump := requestDumpFrom(err); dump != "" {
+		payload.Fields = mergeAlertFields(payload.Fields, []alerts.Field{{Key: "Request", Value: dump}})
+	}
+	if l.excerpt != nil {
+		payload.Excerpt = l.excerpt.excerpt(meta.RequestID)
 	}
 
+	// the last alert before exit must not be rate limited away
+	if l.alwaysAlert(err) || LogLevel(level) == LevelFatal {
+		l.alertManager.Notify(payload)
+		return
+	}
 	l.alertManager.Alert(payload)
 }
 
-func getStackFrames(skip, max int) []string {
-	var frames []string
+/**
+ * RedirectStdLog points the standard library log package at the error stream.
+ * Stray log output from dependencies ends up in the same files as library errors.
+ * Any provided http.Server instances get their ErrorLog wired to the error stream too.
+ *
+ * @param servers Optional HTTP servers whose ErrorLog should be redirected
+ * @return func() Restore function that resets the global log output and flags
+ */
+func (l *Logger) RedirectStdLog(servers ...*http.Server) func() {
+	oldWriter := log.Writer()
+	oldFlags := log.Flags()
+	oldPrefix := log.Prefix()
 
-	for i := skip; i < skip+max; i++ {
-		pc, file, line, ok := runtime.Caller(i)
-		if !ok {
-			break
-		}
+	if l.singleLineErrors() {
+		log.SetOutput(&stdLogLine{out: l.errorLogger, format: l.config.ErrorLogFormat, name: "STDLOG"})
+		log.SetFlags(0)
+		log.SetPrefix("")
+	} else {
+		log.SetOutput(l.errorLogger.Writer())
+		log.SetFlags(log.LstdFlags)
+		log.SetPrefix("[STDLOG] ")
+	}
 
-		fn := runtime.FuncForPC(pc)
-		name := "unknown"
-		if fn != nil {
-			name = path.Base(fn.Name())
+	for _, srv := range servers {
+		if srv != nil {
+			srv.ErrorLog = l.StdErrorLogger("[HTTP] ")
 		}
+	}
 
-		frames = append(
-			frames,
-			fmt.Sprintf("%s:%d %s", path.Base(file), line, name),
-		)
+	return func() {
+		log.SetOutput(oldWriter)
+		log.SetFlags(oldFlags)
+		log.SetPrefix(oldPrefix)
 	}
+}
 
-	return frames
-}
\ No newline at end of file
+/**
+ * StdErrorLogger returns a standard *log.Logger writing to the error stream.
+ * Useful for libraries that accept a *log.Logger such as http.Server.ErrorLog.
+ * With a logfmt or JSON ErrorLogFormat each line becomes one entry whose
+ * logger field is the prefix without brackets.
+ *
+ * @param prefix Prefix prepended to every line
+ * @return *log.Logger Logger backed by the error stream writers
+ */
+func (l *Logger) StdErrorLogger(prefix string) *log.Logger {
+	if l.singleLineErrors() {
+		name := strings.Trim(strings.TrimSpace(prefix), "[]")
+		return log.New(&stdLogLine{out: l.errorLogger, format: l.config.ErrorLogFormat, name: name}, "", 0)
+	}
+	return log.New(l.errorLogger.Writer(), prefix, log.LstdFlags)
+}
+
+// singleLineErrors reports whether the error stream holds one logfmt or JSON entry per line
+func (l *Logger) singleLineErrors() bool {
+	return l.config.ErrorLogFormat == FormatLogfmt || l.config.ErrorLogFormat == FormatJSON
+}
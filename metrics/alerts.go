@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+)
+
+type AlertCollector struct {
+	source func() alerts.Stats
+}
+
+/**
+ * NewAlertCollector exports alert delivery counters per provider, so a
+ * failing alert channel shows up on dashboards. Pass a function rather than
+ * the manager so the logger's counters can be used directly:
+ *
+ *	collector := metrics.NewAlertCollector(func() alerts.Stats { return logger.Stats().Alerts })
+ *
+ * @param source Returns the current counters on every scrape
+ * @return *AlertCollector Collector ready for Handler or Recorder.Register
+ */
+func NewAlertCollector(source func() alerts.Stats) *AlertCollector {
+	return &AlertCollector{source: source}
+}
+
+/**
+ * Handler serves the alert counters in the Prometheus text exposition format.
+ *
+ * @return http.Handler Handler to mount on the scrape path
+ */
+func (c *AlertCollector) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		bw := bufio.NewWriter(w)
+		c.writeTo(bw)
+		bw.Flush()
+	})
+}
+
+func (c *AlertCollector) writeTo(w *bufio.Writer) {
+	stats := c.source()
+
+	names := make([]string, 0, len(stats.Providers))
+	for name := range stats.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	counters := []struct {
+		name  string
+		help  string
+		value func(alerts.ProviderStats) uint64
+	}{
+		{"alerts_sent_total", "Alerts delivered by provider.", func(p alerts.ProviderStats) uint64 { return p.Sent }},
+		{"alerts_failed_total", "Alert deliveries that failed after all retries, by provider.", func(p alerts.ProviderStats) uint64 { return p.Failed }},
+		{"alerts_rate_limited_total", "Alerts not sent to a provider because of rate limiting.", func(p alerts.ProviderStats) uint64 { return p.RateLimited }},
+	}
+	for _, counter := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n", counter.name, counter.help)
+		fmt.Fprintf(w, "# TYPE %s counter\n", counter.name)
+		for _, name := range names {
+			fmt.Fprintf(w, "%s{provider=\"%s\"} %d\n", counter.name, escapeLabel(name), counter.value(stats.Providers[name]))
+		}
+	}
+
+	fmt.Fprintf(w, "# HELP alerts_grouped_total Repeated alerts folded into summaries in first-occurrence mode.\n")
+	fmt.Fprintf(w, "# TYPE alerts_grouped_total counter\n")
+	fmt.Fprintf(w, "alerts_grouped_total %d\n", stats.Grouped)
+}
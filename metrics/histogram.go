@@ -0,0 +1,167 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const metricName = "http_request_duration_seconds"
+
+// DefaultBuckets matches the Prometheus client default latency buckets (seconds)
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type seriesKey struct {
+	method      string
+	route       string
+	statusClass string
+}
+
+type series struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+type Recorder struct {
+	mu         sync.Mutex
+	buckets    []float64
+	series     map[seriesKey]*series
+	collectors []*AlertCollector
+}
+
+/**
+ * NewRecorder creates a latency histogram keyed by method, route and status class
+ * (2xx, 4xx, ...), giving basic RED metrics: request rate and errors from the
+ * counts, duration from the buckets.
+ *
+ * @param buckets Upper bounds in seconds (DefaultBuckets if empty)
+ * @return *Recorder Recorder ready for Observe and Handler
+ */
+func NewRecorder(buckets ...float64) *Recorder {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	return &Recorder{
+		buckets: sorted,
+		series:  make(map[seriesKey]*series),
+	}
+}
+
+/**
+ * Observe records one request.
+ * Use route templates rather than raw paths to keep the number of series bounded.
+ *
+ * @param method HTTP method
+ * @param route Route template
+ * @param statusCode HTTP response status code
+ * @param latency Request processing duration
+ */
+func (r *Recorder) Observe(method, route string, statusCode int, latency time.Duration) {
+	key := seriesKey{
+		method:      method,
+		route:       route,
+		statusClass: strconv.Itoa(statusCode/100) + "xx",
+	}
+	seconds := latency.Seconds()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.series[key]
+	if !ok {
+		s = &series{counts: make([]uint64, len(r.buckets))}
+		r.series[key] = s
+	}
+
+	for i, upper := range r.buckets {
+		if seconds <= upper {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += seconds
+}
+
+/**
+ * Handler serves the histogram in the Prometheus text exposition format.
+ *
+ * @return http.Handler Handler to mount on the scrape path (e.g. /metrics)
+ */
+func (r *Recorder) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		bw := bufio.NewWriter(w)
+		r.writeTo(bw)
+		for _, c := range r.collectors {
+			c.writeTo(bw)
+		}
+		bw.Flush()
+	})
+}
+
+/**
+ * Register adds the alert counters to the output of Handler, so one scrape
+ * path serves both. Call before serving.
+ *
+ * @param c Alert collector to include
+ */
+func (r *Recorder) Register(c *AlertCollector) {
+	r.collectors = append(r.collectors, c)
+}
+
+func (r *Recorder) writeTo(w *bufio.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]seriesKey, 0, len(r.series))
+	for key := range r.series {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].statusClass < keys[j].statusClass
+	})
+
+	fmt.Fprintf(w, "# HELP %s HTTP request latency by method, route and status class.\n", metricName)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", metricName)
+
+	for _, key := range keys {
+		s := r.series[key]
+		labels := fmt.Sprintf(
+			`method="%s",route="%s",status_class="%s"`,
+			escapeLabel(key.method),
+			escapeLabel(key.route),
+			key.statusClass,
+		)
+
+		for i, upper := range r.buckets {
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", metricName, labels, formatFloat(upper), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", metricName, labels, s.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", metricName, labels, formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", metricName, labels, s.count)
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/google/uuid"
+)
+
+type connectInterceptor struct {
+	logger *logging.Logger
+}
+
+/**
+ * ConnectInterceptor returns a connect-go handler interceptor for request logging.
+ * The procedure name (/pkg.Service/Method) is recorded as path and route, the peer
+ * address as IP, and the RPC code is mapped to its HTTP status so levels and alerts
+ * follow the same rules as HTTPLogger. Client-side calls pass through untouched.
+ *
+ * @param logger Logger instance
+ * @return connect.Interceptor Interceptor for connect.WithInterceptors
+ */
+func ConnectInterceptor(logger *logging.Logger) connect.Interceptor {
+	return &connectInterceptor{logger: logger}
+}
+
+func (i *connectInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+
+		ctx, reqID := connectContext(ctx, i.logger, req.Spec(), req.Peer(), req.Header(), req.HTTPMethod())
+
+		start := time.Now()
+		resp, err := next(ctx, req)
+		latency := time.Since(start)
+
+		if resp != nil {
+			resp.Header().Set("X-Request-ID", reqID)
+		}
+
+		i.logger.LogRequestWithError(ctx, connectStatus(err), latency, err)
+		drainServerless(i.logger)
+
+		return resp, err
+	}
+}
+
+func (i *connectInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *connectInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, reqID := connectContext(ctx, i.logger, conn.Spec(), conn.Peer(), conn.RequestHeader(), http.MethodPost)
+		conn.ResponseHeader().Set("X-Request-ID", reqID)
+
+		start := time.Now()
+		err := next(ctx, conn)
+
+		i.logger.LogRequestWithError(ctx, connectStatus(err), time.Since(start), err)
+		drainServerless(i.logger)
+
+		return err
+	}
+}
+
+func connectContext(ctx context.Context, logger *logging.Logger, spec connect.Spec, peer connect.Peer, header http.Header, method string) (context.Context, string) {
+	reqID := logger.RequestIDFromHeader(header)
+
+	correlationID := header.Get("X-Correlation-ID")
+	if correlationID == "" {
+		correlationID = uuid.NewString()
+	}
+
+	ip := peer.Addr
+	if host, _, err := net.SplitHostPort(peer.Addr); err == nil {
+		ip = host
+	}
+	if xff := header.Get("X-Forwarded-For"); xff != "" {
+		ip = xff
+	}
+
+	meta := logging.Meta{
+		RequestID:     reqID,
+		CorrelationID: correlationID,
+		IP:            ip,
+		Method:        method,
+		Path:          spec.Procedure,
+		Route:         spec.Procedure,
+		UserAgent:     header.Get("User-Agent"),
+		Trace:         logging.TraceFromHeader(header),
+	}
+
+	return logging.WithLogger(logging.WithMeta(ctx, meta), logger), reqID
+}
+
+/**
+ * connectStatus maps an RPC error to the HTTP status used by the Connect protocol.
+ * A nil error maps to 200; errors without a connect code count as unknown (500).
+ */
+func connectStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) {
+		return http.StatusInternalServerError
+	}
+
+	switch connectErr.Code() {
+	case connect.CodeCanceled:
+		return StatusClientClosedRequest
+	case connect.CodeInvalidArgument, connect.CodeOutOfRange:
+		return http.StatusBadRequest
+	case connect.CodeDeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case connect.CodeNotFound:
+		return http.StatusNotFound
+	case connect.CodeAlreadyExists, connect.CodeAborted:
+		return http.StatusConflict
+	case connect.CodePermissionDenied:
+		return http.StatusForbidden
+	case connect.CodeResourceExhausted:
+		return http.StatusTooManyRequests
+	case connect.CodeFailedPrecondition:
+		return http.StatusPreconditionFailed
+	case connect.CodeUnimplemented:
+		return http.StatusNotImplemented
+	case connect.CodeUnavailable:
+		return http.StatusServiceUnavailable
+	case connect.CodeUnauthenticated:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
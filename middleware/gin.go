@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -12,33 +13,61 @@ import (
 
 /**
  * GinMiddleware returns Gin middleware for request logging.
- * Attaches request metadata (ID, IP, method, path) to context.
+ * Attaches request metadata (request and correlation IDs, IP, method, path,
+ * route template) to context.
  *
  * @param logger Logger instance
  * @return gin.HandlerFunc Middleware handler
  */
 func GinMiddleware(logger *logging.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		reqID := c.GetHeader("X-Request-ID")
-		if reqID == "" {
-			reqID = uuid.NewString()
+		reqID := logger.RequestIDFromHeader(c.Request.Header)
+
+		correlationID := c.GetHeader("X-Correlation-ID")
+		if correlationID == "" {
+			correlationID = uuid.NewString()
 		}
 
 		meta := logging.Meta{
-			RequestID: reqID,
-			IP:        c.ClientIP(),
-			Method:    c.Request.Method,
-			Path:      c.Request.URL.Path,
-			UserAgent: c.Request.UserAgent(),
+			RequestID:     reqID,
+			CorrelationID: correlationID,
+			IP:            c.ClientIP(),
+			Method:        c.Request.Method,
+			Path:          c.Request.URL.Path,
+			Route:         c.FullPath(),
+			UserAgent:     c.Request.UserAgent(),
+			Trace:         logging.TraceFromHeader(c.Request.Header),
+		}
+
+		if logger.ConnInfoEnabled() {
+			meta = logging.WithConnInfo(meta, c.Request)
 		}
 
-		ctx := logging.WithMeta(c.Request.Context(), meta)
+		ctx := logging.WithLogger(logging.WithMeta(c.Request.Context(), meta), logger)
 		c.Request = c.Request.WithContext(ctx)
 		c.Header("X-Request-ID", reqID)
+		c.Header("X-Correlation-ID", correlationID)
 		c.Next()
 	}
 }
 
+/**
+ * UseGin installs the full middleware chain on r in the order it needs:
+ * request context, request logging, error logging, then panic recovery last
+ * so the loggers still run for requests that panicked.
+ *
+ * @param r Engine or router group
+ * @param logger Logger instance
+ */
+func UseGin(r gin.IRoutes, logger *logging.Logger) {
+	r.Use(
+		GinMiddleware(logger),
+		GinLogger(logger),
+		GinHTTPErrorLogger(logger),
+		GinRecovery(logger),
+	)
+}
+
 /**
  * GinLogger returns Gin middleware that logs all requests.
  * Logs to access log and Loki with consistent JSON format.
@@ -47,45 +76,94 @@ func GinMiddleware(logger *logging.Logger) gin.HandlerFunc {
  * @return gin.HandlerFunc Middleware handler
  */
 func GinLogger(logger *logging.Logger) gin.HandlerFunc {
+	return GinLoggerWithConfig(logger, LoggerConfig{})
+}
+
+/**
+ * GinLoggerWithConfig returns GinLogger with per-route overrides.
+ * Rules can skip routes entirely, override the level of successful requests,
+ * sample successful requests, or attach the request body to the Loki entry.
+ *
+ * @param logger Logger instance
+ * @param config Route rules evaluated per request
+ * @return gin.HandlerFunc Middleware handler
+ */
+func GinLoggerWithConfig(logger *logging.Logger, config LoggerConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		defer drainServerless(logger)
+
+		rule := config.match(c.FullPath(), c.Request.URL.Path)
+		if rule != nil && rule.Skip {
+			c.Next()
+			return
+		}
+
+		if rule != nil && rule.LogBody {
+			if meta, ok := logging.FromContext(c.Request.Context()); ok {
+				meta.Body = peekBody(c.Request)
+				c.Request = c.Request.WithContext(logging.WithMeta(c.Request.Context(), meta))
+			}
+		}
+
 		start := time.Now()
+		if config.TimingHeader != "" {
+			c.Writer = &ginTimingWriter{ResponseWriter: c.Writer, header: config.TimingHeader, start: start}
+		}
+		body := newErrorBody(config.ErrorBodyBytes)
+		if body != nil {
+			c.Writer = &ginErrorBodyWriter{ResponseWriter: c.Writer, body: body}
+		}
+
 		c.Next()
 		latency := time.Since(start)
 
-		meta, ok := logging.FromContext(c.Request.Context())
-		if !ok {
+		if config.TimingHeader != "" && !c.Writer.Written() {
+			setTimingHeader(c.Writer.Header(), config.TimingHeader, latency)
+		}
+
+		if _, ok := logging.FromContext(c.Request.Context()); !ok {
 			return
 		}
 
+		ctx := c.Request.Context()
 		statusCode := c.Writer.Status()
+		disconnected := clientDisconnected(c.Request)
+		if disconnected {
+			statusCode = StatusClientClosedRequest
+		}
+		contentType, cacheStatus := config.responseHeaders(c.Writer.Header())
+		if disconnected || body.String() != "" || contentType != "" || cacheStatus != "" {
+			ctx = logging.WithResponseStats(ctx, logging.ResponseStats{
+				Bytes:              int64(max(c.Writer.Size(), 0)),
+				ClientDisconnected: disconnected,
+				ErrorBody:          body.String(),
+				ContentType:        contentType,
+				CacheStatus:        cacheStatus,
+			})
+		}
+
+		if config.Metrics != nil {
+			config.Metrics.Observe(c.Request.Method, c.FullPath(), statusCode, latency)
+		}
 
-		logLine := fmt.Sprintf(
-			"[REQ:%s] %s | %3d | %13v | %15s | %-7s %s",
-			meta.RequestID,
-			time.Now().Format(time.RFC3339),
-			statusCode,
-			latency,
-			meta.IP,
-			meta.Method,
-			meta.Path,
-		)
-		logger.Access(logLine)
-
-		level := logging.LevelInfo
-		if statusCode >= 500 {
-			level = logging.LevelCritical
-		} else if statusCode >= 400 {
-			level = logging.LevelError
-		} else if statusCode >= 300 {
+		if rule.sampledOut(statusCode) {
+			return
+		}
+
+		level := rule.levelFor(statusCode, logger.LevelForStatus(statusCode))
+		if disconnected {
 			level = logging.LevelWarn
 		}
 
 		var err error
 		if statusCode >= 400 {
 			if panicInfo, exists := c.Get("panic_info"); exists {
-				err = fmt.Errorf("%s", panicInfo.(string))
+				err = logging.MarkPanic(fmt.Errorf("%s", panicInfo.(string)))
+				if request, exists := c.Get("panic_request"); exists {
+					err = logging.WithRequestDump(err, request.(string))
+				}
 			} else if len(c.Errors) > 0 {
-				err = fmt.Errorf("%s", c.Errors.String())
+				err = ginErrors{msg: c.Errors.String(), errs: c.Errors}
 			} else if errVal, exists := c.Get("logged_error"); exists {
 				if e, ok := errVal.(error); ok {
 					err = e
@@ -93,10 +171,51 @@ func GinLogger(logger *logging.Logger) gin.HandlerFunc {
 			}
 		}
 
-		logger.Loki(c.Request.Context(), level, statusCode, latency, err)
+		logger.LogRequestWithLevel(ctx, level, statusCode, latency, err)
 	}
 }
 
+/**
+ * ginErrors keeps the c.Errors message while leaving the original errors
+ * reachable for errors.As, so binding errors get errors.validation details.
+ */
+type ginErrors struct {
+	msg  string
+	errs []*gin.Error
+}
+
+func (e ginErrors) Error() string {
+	return e.msg
+}
+
+func (e ginErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e.errs))
+	for _, ge := range e.errs {
+		errs = append(errs, ge.Err)
+	}
+	return errs
+}
+
+/**
+ * ginErrorBodyWriter records the body of error responses for GinLoggerWithConfig.
+ */
+type ginErrorBodyWriter struct {
+	gin.ResponseWriter
+	body *errorBody
+}
+
+func (w *ginErrorBodyWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.body.record(w.Status(), p[:n])
+	return n, err
+}
+
+func (w *ginErrorBodyWriter) WriteString(s string) (int, error) {
+	n, err := w.ResponseWriter.WriteString(s)
+	w.body.record(w.Status(), []byte(s[:n]))
+	return n, err
+}
+
 func GinHTTPErrorLogger(logger *logging.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
@@ -126,20 +245,61 @@ func GinHTTPErrorLogger(logger *logging.Logger) gin.HandlerFunc {
 
 /**
  * GinRecovery handles panic recovery and stores panic info for logging.
- * Should be used with GinLogger to capture panic errors in Loki.
+ * Should be used with GinLogger to capture panic errors in Loki. With
+ * Config.DumpRequestOnPanic the panic is also written to the error log and
+ * alerted with a redacted request dump.
  *
  * @param logger Logger instance
  * @return gin.HandlerFunc Recovery middleware handler
  */
 func GinRecovery(logger *logging.Logger) gin.HandlerFunc {
+	return GinRecoveryWithHandler(logger, nil)
+}
+
+/**
+ * GinPanicHandler renders the response for a recovered panic,
+ * e.g. with c.JSON. The context is aborted afterwards.
+ */
+type GinPanicHandler func(c *gin.Context, err error)
+
+/**
+ * GinRecoveryWithHandler is GinRecovery with a custom panic response,
+ * e.g. a JSON error body containing the request ID or a different status.
+ * The panic info is stored for GinLogger either way.
+ *
+ * @param logger Logger instance
+ * @param handler Renders the response (empty 500 if nil)
+ * @return gin.HandlerFunc Recovery middleware handler
+ */
+func GinRecoveryWithHandler(logger *logging.Logger, handler GinPanicHandler) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		dump := logger.RequestDumpEnabled()
+		body := ""
+		if dump {
+			body = peekBody(c.Request)
+		}
+
 		defer func() {
 			if r := recover(); r != nil {
-				c.Set("panic_info", fmt.Sprintf("PANIC: %v", r))
-				c.AbortWithStatus(http.StatusInternalServerError)
+				panicInfo := fmt.Sprintf("PANIC: %v", r)
+				c.Set("panic_info", panicInfo)
+
+				if dump {
+					request := logger.DumpRequest(c.Request, body)
+					c.Set("panic_request", request)
+					logger.LogErrorWithMark(c, logging.WithRequestDump(errors.New(panicInfo), request))
+				}
+
+				if handler == nil {
+					c.AbortWithStatus(http.StatusInternalServerError)
+					return
+				}
+
+				handler(c, errors.New(panicInfo))
+				c.Abort()
 			}
 		}()
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
@@ -1,7 +1,11 @@
 package middleware
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"sync"
 	"time"
@@ -10,16 +14,120 @@ import (
 	"github.com/google/uuid"
 )
 
+/**
+ * responseWriter records the status code, bytes written and time to first byte,
+ * and sets the optional timing header just before the headers are sent.
+ * Flush, Hijack, Push and ReadFrom pass through to the underlying writer so
+ * streaming (SSE), websocket upgrades, HTTP/2 server push and sendfile keep
+ * working behind the middleware.
+ */
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	start      time.Time
+	firstByte  time.Duration
+	bytes      int64
+	timing     string
+	headerSent bool
+	errorBody  *errorBody
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
+	rw.beforeHeaders()
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	rw.beforeHeaders()
+	rw.markFirstByte()
+	n, err := rw.ResponseWriter.Write(p)
+	rw.bytes += int64(n)
+	rw.errorBody.record(rw.statusCode, p[:n])
+	return n, err
+}
+
+func (rw *responseWriter) Flush() {
+	rw.beforeHeaders()
+	rw.markFirstByte()
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("middleware: underlying ResponseWriter does not implement http.Hijacker")
+	}
+
+	conn, buf, err := hj.Hijack()
+	if err == nil {
+		rw.statusCode = http.StatusSwitchingProtocols
+	}
+	return conn, buf, err
+}
+
+func (rw *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := rw.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+func (rw *responseWriter) ReadFrom(src io.Reader) (int64, error) {
+	if rw.errorBody != nil && rw.statusCode >= 400 {
+		// go through Write so the error body is recorded
+		return io.Copy(struct{ io.Writer }{rw}, src)
+	}
+
+	rw.beforeHeaders()
+	rw.markFirstByte()
+
+	var n int64
+	var err error
+	if rf, ok := rw.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(src)
+	} else {
+		n, err = io.Copy(rw.ResponseWriter, src)
+	}
+	rw.bytes += n
+	return n, err
+}
+
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func (rw *responseWriter) beforeHeaders() {
+	if rw.headerSent {
+		return
+	}
+	rw.headerSent = true
+	setTimingHeader(rw.Header(), rw.timing, time.Since(rw.start))
+}
+
+func (rw *responseWriter) markFirstByte() {
+	if rw.firstByte == 0 {
+		rw.firstByte = time.Since(rw.start)
+	}
+}
+
+/**
+ * StatusClientClosedRequest is logged for requests the client abandoned before
+ * the handler finished, following the nginx convention.
+ */
+const StatusClientClosedRequest = 499
+
+/**
+ * clientDisconnected reports whether the client went away while the handler
+ * ran. The server cancels the request context when the connection closes; it
+ * is not yet cancelled for completed requests when the middleware resumes.
+ */
+func clientDisconnected(r *http.Request) bool {
+	return errors.Is(r.Context().Err(), context.Canceled)
+}
+
 type requestState struct {
 	mu  sync.Mutex
 	err error
@@ -31,6 +139,16 @@ func (s *requestState) SetError(err error) {
 	s.err = err
 }
 
+func (s *requestState) AddError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+		return
+	}
+	s.err = errors.Join(s.err, err)
+}
+
 func (s *requestState) GetError() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -51,27 +169,63 @@ var reqStateKey = stateKey{}
 func HTTPMiddleware(logger *logging.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			reqID := r.Header.Get("X-Request-ID")
-			if reqID == "" {
-				reqID = uuid.NewString()
-			}
+			next.ServeHTTP(w, withRequestMeta(logger, w, r, ""))
+		})
+	}
+}
 
-			meta := logging.Meta{
-				RequestID: reqID,
-				IP:        getClientIP(r),
-				Method:    r.Method,
-				Path:      r.URL.Path,
-				UserAgent: r.UserAgent(),
-			}
+/**
+ * withRequestMeta attaches request metadata and error state to the request context
+ * and echoes the request and correlation IDs in the response headers.
+ *
+ * @param logger Logger instance, consulted for optional connection metadata
+ * @param w Response writer receiving the ID headers
+ * @param r Incoming request
+ * @param route Route template, empty if unknown
+ * @return *http.Request Request carrying the new context
+ */
+func withRequestMeta(logger *logging.Logger, w http.ResponseWriter, r *http.Request, route string) *http.Request {
+	reqID := logger.RequestIDFromHeader(r.Header)
+
+	correlationID := r.Header.Get("X-Correlation-ID")
+	if correlationID == "" {
+		correlationID = uuid.NewString()
+	}
 
-			state := &requestState{}
-			ctx := logging.WithMeta(r.Context(), meta)
-			ctx = context.WithValue(ctx, reqStateKey, state)
-			r = r.WithContext(ctx)
-			w.Header().Set("X-Request-ID", reqID)
+	meta := logging.Meta{
+		RequestID:     reqID,
+		CorrelationID: correlationID,
+		IP:            getClientIP(r),
+		Method:        r.Method,
+		Path:          r.URL.Path,
+		Route:         route,
+		UserAgent:     r.UserAgent(),
+		Trace:         logging.TraceFromHeader(r.Header),
+	}
 
-			next.ServeHTTP(w, r)
-		})
+	if logger.ConnInfoEnabled() {
+		meta = logging.WithConnInfo(meta, r)
+	}
+
+	state := &requestState{}
+	ctx := logging.WithMeta(r.Context(), meta)
+	ctx = logging.WithLogger(ctx, logger)
+	ctx = context.WithValue(ctx, reqStateKey, state)
+	w.Header().Set("X-Request-ID", reqID)
+	w.Header().Set("X-Correlation-ID", correlationID)
+
+	return r.WithContext(ctx)
+}
+
+/**
+ * drainServerless waits for alerts and flushes the logger in Serverless mode,
+ * since the runtime may freeze the process as soon as the handler returns.
+ *
+ * @param logger Logger instance
+ */
+func drainServerless(logger *logging.Logger) {
+	if logger.ServerlessEnabled() {
+		_ = logger.Drain(context.Background())
 	}
 }
 
@@ -83,43 +237,165 @@ func HTTPMiddleware(logger *logging.Logger) func(http.Handler) http.Handler {
  * @return func(http.Handler) http.Handler Middleware wrapper
  */
 func HTTPLogger(logger *logging.Logger) func(http.Handler) http.Handler {
+	return HTTPLoggerWithConfig(logger, LoggerConfig{})
+}
+
+/**
+ * HTTPLoggerWithConfig returns HTTPLogger with per-route overrides.
+ * Framework-agnostic alternative to GinLoggerWithConfig; rules match the raw path.
+ *
+ * @param logger Logger instance
+ * @param config Route rules evaluated per request
+ * @return func(http.Handler) http.Handler Middleware wrapper
+ */
+func HTTPLoggerWithConfig(logger *logging.Logger, config LoggerConfig) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer drainServerless(logger)
+
+			meta, hasMeta := logging.FromContext(r.Context())
+
+			rule := config.match(meta.Route, r.URL.Path)
+			if rule != nil && rule.Skip {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if rule != nil && rule.LogBody && hasMeta {
+				meta.Body = peekBody(r)
+				r = r.WithContext(logging.WithMeta(r.Context(), meta))
+			}
+
 			start := time.Now()
 
-			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			rw := &responseWriter{
+				ResponseWriter: w,
+				statusCode:     http.StatusOK,
+				start:          start,
+				timing:         config.TimingHeader,
+				errorBody:      newErrorBody(config.ErrorBodyBytes),
+			}
 
 			next.ServeHTTP(rw, r)
 
 			latency := time.Since(start)
+			if !rw.headerSent {
+				rw.headerSent = true
+				setTimingHeader(w.Header(), config.TimingHeader, latency)
+			}
 			statusCode := rw.statusCode
+			disconnected := clientDisconnected(r)
+			if disconnected {
+				statusCode = StatusClientClosedRequest
+			}
+
+			if config.Metrics != nil {
+				route := meta.Route
+				if route == "" {
+					route = r.Pattern
+				}
+				config.Metrics.Observe(r.Method, route, statusCode, latency)
+			}
+
+			if rule.sampledOut(statusCode) {
+				return
+			}
 
 			var err error
 			if state, ok := r.Context().Value(reqStateKey).(*requestState); ok && state != nil {
 				err = state.GetError()
 			}
 
-			logger.LogRequestWithError(r.Context(), statusCode, latency, err)
+			contentType, cacheStatus := config.responseHeaders(rw.Header())
+			ctx := logging.WithResponseStats(r.Context(), logging.ResponseStats{
+				Bytes:              rw.bytes,
+				FirstByte:          rw.firstByte,
+				ClientDisconnected: disconnected,
+				ErrorBody:          rw.errorBody.String(),
+				ContentType:        contentType,
+				CacheStatus:        cacheStatus,
+			})
+
+			// an abandoned request is not a server failure
+			if disconnected {
+				logger.LogRequestWithLevel(ctx, logging.LevelWarn, statusCode, latency, err)
+				return
+			}
+
+			if rule != nil && rule.Level != "" && statusCode < 400 {
+				logger.LogRequestWithLevel(ctx, rule.Level, statusCode, latency, err)
+				return
+			}
+
+			logger.LogRequestWithError(ctx, statusCode, latency, err)
 		})
 	}
 }
 
+/**
+ * WrapHTTP wraps handler in the full middleware chain in the order it needs:
+ * request context, request logging, then panic recovery innermost so the
+ * logger still records requests that panicked.
+ *
+ * @param logger Logger instance
+ * @param handler Application handler
+ * @return http.Handler Handler with logging and recovery installed
+ */
+func WrapHTTP(logger *logging.Logger, handler http.Handler) http.Handler {
+	return HTTPMiddleware(logger)(HTTPLogger(logger)(HTTPRecovery(logger)(handler)))
+}
+
 /**
  * HTTPRecovery handles panic recovery for standard http handlers.
- * Framework-agnostic alternative to GinRecovery.
+ * Framework-agnostic alternative to GinRecovery, including the request dump
+ * written with Config.DumpRequestOnPanic.
  *
  * @param logger Logger instance
  * @return func(http.Handler) http.Handler Recovery middleware wrapper
  */
 func HTTPRecovery(logger *logging.Logger) func(http.Handler) http.Handler {
+	return HTTPRecoveryWithHandler(logger, nil)
+}
+
+/**
+ * PanicHandler renders the response for a recovered panic.
+ * The request context still carries Meta, so the request ID is available.
+ */
+type PanicHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+/**
+ * HTTPRecoveryWithHandler is HTTPRecovery with a custom panic response,
+ * e.g. a JSON error body containing the request ID or a different status.
+ * The panic is recorded for the logging middleware either way.
+ *
+ * @param logger Logger instance
+ * @param handler Renders the response (plain-text 500 if nil)
+ * @return func(http.Handler) http.Handler Recovery middleware wrapper
+ */
+func HTTPRecoveryWithHandler(logger *logging.Logger, handler PanicHandler) func(http.Handler) http.Handler {
+	if handler == nil {
+		handler = defaultPanicHandler
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			dump := logger.RequestDumpEnabled()
+			body := ""
+			if dump {
+				body = peekBody(r)
+			}
+
 			defer func() {
 				if rec := recover(); rec != nil {
+					err := logging.MarkPanic(errFromPanic(rec))
+					if dump {
+						err = logging.WithRequestDump(err, logger.DumpRequest(r, body))
+						logger.Error(r.Context(), err)
+					}
 					if state, ok := r.Context().Value(reqStateKey).(*requestState); ok && state != nil {
-						state.SetError(errFromPanic(rec))
+						state.SetError(err)
 					}
-					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					handler(w, r, err)
 				}
 			}()
 
@@ -128,6 +404,10 @@ func HTTPRecovery(logger *logging.Logger) func(http.Handler) http.Handler {
 	}
 }
 
+func defaultPanicHandler(w http.ResponseWriter, r *http.Request, err error) {
+	http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+}
+
 /**
  * SetHTTPError stores an error in the request state for logging.
  * Use this in handlers to pass errors to the logging middleware.
@@ -141,6 +421,22 @@ func SetHTTPError(r *http.Request, err error) {
 	}
 }
 
+/**
+ * AddHTTPError appends an error to the request state instead of replacing it.
+ * All accumulated errors are emitted in the Loki entry's errors.messages list.
+ *
+ * @param r HTTP request
+ * @param err Error to append
+ */
+func AddHTTPError(r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+	if state, ok := r.Context().Value(reqStateKey).(*requestState); ok && state != nil {
+		state.AddError(err)
+	}
+}
+
 func getClientIP(r *http.Request) string {
 	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
 		return xff
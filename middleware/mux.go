@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/gorilla/mux"
+)
+
+/**
+ * MuxMiddleware returns gorilla/mux middleware for request logging.
+ * Works like HTTPMiddleware but also records the matched route template
+ * (e.g. /users/{id}) in Meta, so entries group by declared route.
+ * Register it with router.Use so it runs after route matching.
+ *
+ * @param logger Logger instance
+ * @return mux.MiddlewareFunc Middleware wrapper
+ */
+func MuxMiddleware(logger *logging.Logger) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, withRequestMeta(logger, w, r, muxRoute(r)))
+		})
+	}
+}
+
+func muxRoute(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+
+	tmpl, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return tmpl
+}
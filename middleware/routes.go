@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/metrics"
+)
+
+const maxLoggedBodyBytes = 4096
+
+/**
+ * RouteRule overrides logging behavior for requests matching Pattern.
+ * Patterns match the route template when available, otherwise the raw path.
+ * A trailing "*" matches any suffix ("/webhooks/*"), other patterns use path.Match.
+ */
+type RouteRule struct {
+	Pattern    string           `yaml:"pattern"`
+	Skip       bool             `yaml:"skip"`
+	Level      logging.LogLevel `yaml:"level"`
+	SampleRate float64          `yaml:"sample_rate"`
+	LogBody    bool             `yaml:"log_body"`
+}
+
+/**
+ * LoggerConfig configures GinLoggerWithConfig and HTTPLoggerWithConfig.
+ * TimingHeader, when set to Server-Timing or X-Response-Time, adds the measured
+ * latency to the response. Headers are sent before the body, so for responses
+ * with a body the value is the time until the first write.
+ * Metrics, when set, records every non-skipped request in a latency histogram,
+ * independent of sampling.
+ * ErrorBodyBytes, when positive, keeps up to that many bytes of the body of
+ * responses with status >= 400 for errors.response_body in the Loki entry.
+ * Successful responses are never buffered.
+ * CacheStatusHeader names a response header such as X-Cache or CF-Cache-Status
+ * whose value is recorded as http.cache_status.
+ */
+type LoggerConfig struct {
+	Rules             []RouteRule       `yaml:"rules"`
+	TimingHeader      string            `yaml:"timing_header"`
+	Metrics           *metrics.Recorder `yaml:"-"`
+	ErrorBodyBytes    int               `yaml:"error_body_bytes"`
+	CacheStatusHeader string            `yaml:"cache_status_header"`
+}
+
+/**
+ * match returns the first rule matching the route or path, or nil if none does.
+ *
+ * @param route Route template (may be empty)
+ * @param reqPath Raw request path
+ * @return *RouteRule Matching rule or nil
+ */
+func (c LoggerConfig) match(route, reqPath string) *RouteRule {
+	target := reqPath
+	if route != "" {
+		target = route
+	}
+
+	for i := range c.Rules {
+		if matchPattern(c.Rules[i].Pattern, target) {
+			return &c.Rules[i]
+		}
+	}
+
+	return nil
+}
+
+func matchPattern(pattern, target string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(target, strings.TrimSuffix(pattern, "*"))
+	}
+
+	if pattern == target {
+		return true
+	}
+
+	matched, _ := path.Match(pattern, target)
+	return matched
+}
+
+/**
+ * sampledOut reports whether a successful request should be dropped by sampling.
+ * Requests with status >= 400 are never sampled out.
+ */
+func (r *RouteRule) sampledOut(statusCode int) bool {
+	if r == nil || r.SampleRate <= 0 || r.SampleRate >= 1 || statusCode >= 400 {
+		return false
+	}
+	return rand.Float64() >= r.SampleRate
+}
+
+/**
+ * levelFor returns the overridden level for successful requests.
+ * Error responses keep the level derived from their status code.
+ */
+func (r *RouteRule) levelFor(statusCode int, level logging.LogLevel) logging.LogLevel {
+	if r == nil || r.Level == "" || statusCode >= 400 {
+		return level
+	}
+	return r.Level
+}
+
+/**
+ * errorBody keeps the start of an error response body, up to max bytes.
+ * Writes are only recorded once the status is known to be >= 400.
+ */
+type errorBody struct {
+	max       int
+	buf       []byte
+	truncated bool
+}
+
+func (b *errorBody) record(status int, p []byte) {
+	if b == nil || status < 400 || b.truncated {
+		return
+	}
+	if room := b.max - len(b.buf); len(p) > room {
+		p = p[:room]
+		b.truncated = true
+	}
+	b.buf = append(b.buf, p...)
+}
+
+func (b *errorBody) String() string {
+	if b == nil {
+		return ""
+	}
+	if b.truncated {
+		return string(b.buf) + "...(truncated)"
+	}
+	return string(b.buf)
+}
+
+/**
+ * responseHeaders returns the media type of the response (without parameters
+ * such as charset) and the configured cache status header.
+ */
+func (c LoggerConfig) responseHeaders(h http.Header) (contentType, cacheStatus string) {
+	contentType, _, _ = strings.Cut(h.Get("Content-Type"), ";")
+	if c.CacheStatusHeader != "" {
+		cacheStatus = h.Get(c.CacheStatusHeader)
+	}
+	return strings.TrimSpace(contentType), cacheStatus
+}
+
+func newErrorBody(max int) *errorBody {
+	if max <= 0 {
+		return nil
+	}
+	return &errorBody{max: max}
+}
+
+/**
+ * peekBody returns the start of the request body for logging. Only the first
+ * maxLoggedBodyBytes are buffered, the rest is still streamed to the handler,
+ * so large uploads do not end up in memory.
+ */
+func peekBody(r *http.Request) string {
+	if r.Body == nil || r.Body == http.NoBody {
+		return ""
+	}
+
+	head, err := io.ReadAll(io.LimitReader(r.Body, maxLoggedBodyBytes+1))
+	// hand the bytes read so far back even on error, the handler then sees the error itself
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+	if err != nil {
+		return ""
+	}
+
+	if len(head) > maxLoggedBodyBytes {
+		return string(head[:maxLoggedBodyBytes]) + "...(truncated)"
+	}
+	return string(head)
+}
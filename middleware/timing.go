@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HeaderServerTiming = "Server-Timing"
+	HeaderResponseTime = "X-Response-Time"
+)
+
+/**
+ * setTimingHeader writes the elapsed time in the configured header format:
+ * Server-Timing uses "app;dur=12.34", any other header name gets "12.34ms".
+ *
+ * @param h Response headers
+ * @param name Header name, no-op if empty
+ * @param elapsed Time measured since the start of the request
+ */
+func setTimingHeader(h http.Header, name string, elapsed time.Duration) {
+	if name == "" {
+		return
+	}
+
+	ms := strconv.FormatFloat(float64(elapsed.Microseconds())/1000, 'f', 2, 64)
+	if http.CanonicalHeaderKey(name) == HeaderServerTiming {
+		h.Add(HeaderServerTiming, "app;dur="+ms)
+		return
+	}
+	h.Set(name, ms+"ms")
+}
+
+/**
+ * ginTimingWriter sets the timing header just before Gin flushes the headers.
+ * Responses without a body get the header from GinLoggerWithConfig instead,
+ * with the exact latency that is logged.
+ */
+type ginTimingWriter struct {
+	gin.ResponseWriter
+	header string
+	start  time.Time
+}
+
+func (w *ginTimingWriter) beforeHeaders() {
+	if !w.Written() {
+		setTimingHeader(w.Header(), w.header, time.Since(w.start))
+	}
+}
+
+func (w *ginTimingWriter) WriteHeaderNow() {
+	w.beforeHeaders()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *ginTimingWriter) Write(p []byte) (int, error) {
+	w.beforeHeaders()
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *ginTimingWriter) WriteString(s string) (int, error) {
+	w.beforeHeaders()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *ginTimingWriter) Flush() {
+	w.beforeHeaders()
+	w.ResponseWriter.Flush()
+}
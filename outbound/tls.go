@@ -0,0 +1,84 @@
+package outbound
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+/**
+ * TLSConfig configures outbound TLS for sinks and alerters, for networks that
+ * use a private CA or require client certificates (mTLS).
+ *
+ * CAFile is a PEM bundle trusted in addition to the system roots. CertFile and
+ * KeyFile present a client certificate. MinVersion is "1.0" to "1.3"
+ * (default: Go's default, currently 1.2). ServerName overrides the name
+ * verified against the server certificate.
+ */
+type TLSConfig struct {
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	MinVersion         string `yaml:"min_version"`
+	ServerName         string `yaml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+/**
+ * Build loads the certificates and returns the crypto/tls configuration.
+ * A nil TLSConfig yields nil, which means Go's defaults.
+ *
+ * @return *tls.Config TLS configuration, or nil
+ * @return error Error if a file cannot be loaded or MinVersion is unknown
+ */
+func (c *TLSConfig) Build() (*tls.Config, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.MinVersion != "" {
+		v, ok := tlsVersions[c.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS min version %q (want 1.0, 1.1, 1.2 or 1.3)", c.MinVersion)
+		}
+		cfg.MinVersion = v
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
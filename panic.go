@@ -0,0 +1,46 @@
+package logging
+
+import "errors"
+
+type panicError struct {
+	err error
+}
+
+func (e *panicError) Error() string {
+	return e.err.Error()
+}
+
+func (e *panicError) Unwrap() error {
+	return e.err
+}
+
+/**
+ * MarkPanic flags err as coming from a recovered panic. The recovery
+ * middleware marks panics so that AlertsConfig.AlwaysAlertPanics can tell
+ * them apart from ordinary handler errors; the message is unchanged.
+ *
+ * @param err Error built from the recovered value
+ * @return error err marked as a panic
+ */
+func MarkPanic(err error) error {
+	if err == nil || IsPanic(err) {
+		return err
+	}
+	return &panicError{err: err}
+}
+
+/**
+ * IsPanic reports whether err, or an error it wraps, was marked with MarkPanic.
+ *
+ * @param err Error to inspect
+ * @return bool True for recovered panics
+ */
+func IsPanic(err error) bool {
+	var pe *panicError
+	return errors.As(err, &pe)
+}
+
+// alwaysAlert reports whether err must be alerted as CRITICAL past MinLevel and rate limiting
+func (l *Logger) alwaysAlert(err error) bool {
+	return l.config.Alerts != nil && l.config.Alerts.AlwaysAlertPanics && IsPanic(err)
+}
@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+)
+
+/**
+ * TraceHeaders lists the incoming headers that middleware keeps in Meta.Trace
+ * and InjectHeaders forwards to downstream services (W3C Trace Context and Baggage).
+ */
+var TraceHeaders = []string{"traceparent", "tracestate", "baggage"}
+
+/**
+ * TraceFromHeader extracts the headers listed in TraceHeaders.
+ *
+ * @param h Incoming request headers
+ * @return map[string]string Present trace headers keyed by name, nil if none
+ */
+func TraceFromHeader(h http.Header) map[string]string {
+	var trace map[string]string
+
+	for _, name := range TraceHeaders {
+		if v := h.Get(name); v != "" {
+			if trace == nil {
+				trace = make(map[string]string)
+			}
+			trace[name] = v
+		}
+	}
+
+	return trace
+}
+
+/**
+ * InjectHeaders copies the request ID, correlation ID and trace headers from ctx
+ * onto an outbound request so downstream services log under the same IDs. Headers already set on
+ * the request are left untouched.
+ *
+ * @param ctx Context containing request metadata
+ * @param req Outbound request to modify
+ */
+func InjectHeaders(ctx context.Context, req *http.Request) {
+	meta, ok := FromContext(ctx)
+	if !ok {
+		return
+	}
+
+	setIfEmpty(req.Header, "X-Request-ID", meta.RequestID)
+	setIfEmpty(req.Header, "X-Correlation-ID", meta.CorrelationID)
+
+	for name, v := range meta.Trace {
+		setIfEmpty(req.Header, name, v)
+	}
+}
+
+func setIfEmpty(h http.Header, name, value string) {
+	if value != "" && h.Get(name) == "" {
+		h.Set(name, value)
+	}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+/**
+ * Transport wraps an http.RoundTripper so every outbound request made with the
+ * incoming request's context carries its request ID, correlation ID and trace headers.
+ *
+ * @param base Underlying transport (http.DefaultTransport if nil)
+ * @return http.RoundTripper Propagating transport
+ */
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, ok := FromContext(req.Context()); !ok {
+		return t.base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the caller's request
+	out := req.Clone(req.Context())
+	InjectHeaders(req.Context(), out)
+
+	return t.base.RoundTrip(out)
+}
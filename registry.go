@@ -0,0 +1,134 @@
+package logging
+
+import (
+	"log"
+	"sort"
+	"sync"
+)
+
+type Registry struct {
+	mu      sync.Mutex
+	base    *Logger
+	loggers map[string]*Logger
+}
+
+/**
+ * NewRegistry creates a registry of named loggers for multi-tenant or multi-module services.
+ * All loggers obtained from the registry share the base writers and alert manager,
+ * but each one reports its own service name and prefixes its text lines.
+ *
+ * @param config Base configuration used to open the shared writers
+ * @return *Registry Registry ready for lazy logger creation
+ * @return error Error if writer setup fails
+ */
+func NewRegistry(config *Config) (*Registry, error) {
+	base, err := New(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Registry{
+		base:    base,
+		loggers: make(map[string]*Logger),
+	}, nil
+}
+
+/**
+ * Get returns the logger registered under name, creating it on first use.
+ *
+ * @param name Tenant or module name, used as service name and line prefix
+ * @return *Logger Logger sharing the registry writers
+ */
+func (r *Registry) Get(name string) *Logger {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if logger, ok := r.loggers[name]; ok {
+		return logger
+	}
+
+	logger := r.base.derive(name)
+	r.loggers[name] = logger
+
+	return logger
+}
+
+func (r *Registry) Base() *Logger {
+	return r.base
+}
+
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.loggers))
+	for name := range r.loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+/**
+ * Close closes the shared writers and forgets all registered loggers.
+ * Loggers obtained from the registry must not be used afterwards.
+ *
+ * @return error First error encountered while closing, if any
+ */
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.loggers = make(map[string]*Logger)
+
+	return r.base.Close()
+}
+
+func (l *Logger) derive(name string) *Logger {
+	cfg := *l.config
+	cfg.ServiceName = name
+
+	prefix := "[" + name + "] "
+	errorPrefix := prefix
+	if l.singleLineErrors() {
+		// a prefix would break logfmt and JSON parsing
+		errorPrefix = ""
+	}
+	accessPrefix, accessFlags := l.accessLogger.Prefix()+prefix, l.accessLogger.Flags()|log.Lmsgprefix
+	if l.config.AccessLogFormat == FormatLogfmt || l.config.AccessLogFormat == FormatCSV {
+		// same for logfmt and CSV access lines
+		accessPrefix, accessFlags = l.accessLogger.Prefix(), l.accessLogger.Flags()
+	}
+
+	return &Logger{
+		accessLogger: log.New(l.accessLogger.Writer(), accessPrefix, accessFlags),
+		errorLogger:  log.New(l.errorLogger.Writer(), errorPrefix, l.errorLogger.Flags()|log.Lmsgprefix),
+		debugLogger:  log.New(l.debugLogger.Writer(), prefix, l.debugLogger.Flags()|log.Lmsgprefix),
+		lokiWriter:   l.lokiWriter,
+		config:       &cfg,
+		alertManager: l.alertManager,
+		suppressor:   l.suppressor,
+		hooks:        l.hooks,
+		callerSkip:   l.callerSkip,
+		stackDepth:   l.stackDepth,
+		level:        l.level,
+		stats:        l.stats,
+		selfLog:      l.selfLog,
+		hostname:     l.hostname,
+		version:      l.version,
+		build:        l.build,
+		buildField:   l.buildField,
+		alertFields:  l.alertFields,
+		alertIgnore:  l.alertIgnore,
+		reporter:     l.reporter,
+		excerpt:      l.excerpt,
+		lokiSink:     l.lokiSink,
+		lokiOutputs:  l.lokiOutputs,
+		fallbacks:    l.fallbacks,
+		files:        l.files,
+		durable:      l.durable,
+		debugStream:  l.debugStream,
+		parent:       l,
+	}
+}
@@ -0,0 +1,338 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts/email"
+	"github.com/ahmadsaubani/go-logging-lib/outbound"
+)
+
+const (
+	defaultReportTopErrors = 10
+	reportLatencySamples   = 10000
+	reportMaxErrorKeys     = 1000
+	reportOtherErrors      = "(other errors)"
+)
+
+/**
+ * ReportConfig enables a periodic digest of the logged traffic: request count,
+ * error rate, p95 latency and the most frequent errors. Interval is hourly,
+ * daily (default) or weekly, counted from New. The report is POSTed as JSON to
+ * WebhookURL and, with Email, sent through the Alerts.Email settings.
+ */
+type ReportConfig struct {
+	Interval   RotationInterval `yaml:"interval"`
+	WebhookURL string           `yaml:"webhook_url"`
+	Email      bool             `yaml:"email"`
+	TopErrors  int              `yaml:"top_errors"`
+}
+
+/**
+ * Report summarizes one period. Requests and ErrorRate cover request entries
+ * (LogRequest, the middlewares); an error request is one logged at ERROR or
+ * CRITICAL. TopErrors counts every ERROR/CRITICAL entry with an error, by
+ * message. P95LatencyMs is computed from a uniform sample of up to 10000
+ * requests.
+ */
+type Report struct {
+	Service      string        `json:"service"`
+	Environment  string        `json:"environment,omitempty"`
+	Hostname     string        `json:"hostname,omitempty"`
+	From         time.Time     `json:"from"`
+	To           time.Time     `json:"to"`
+	Requests     uint64        `json:"requests"`
+	Errors       uint64        `json:"errors"`
+	ErrorRate    float64       `json:"error_rate"`
+	P95LatencyMs int64         `json:"p95_latency_ms"`
+	TopErrors    []ReportError `json:"top_errors"`
+}
+
+type ReportError struct {
+	Message string `json:"message"`
+	Count   uint64 `json:"count"`
+}
+
+type reporter struct {
+	logger *Logger
+	config *ReportConfig
+	email  *email.Alerter
+	client *http.Client
+
+	mu        sync.Mutex
+	from      time.Time
+	requests  uint64
+	errors    uint64
+	latencies []time.Duration
+	errCounts map[string]uint64
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func validateReport(config *Config) error {
+	r := config.Report
+	if r == nil {
+		return nil
+	}
+	switch r.Interval {
+	case "", RotateHourly, RotateDaily, RotateWeekly:
+	default:
+		return fmt.Errorf("unknown report interval %q", r.Interval)
+	}
+	if r.Email && (config.Alerts == nil || config.Alerts.Email == nil) {
+		return fmt.Errorf("report email requires alerts.email settings")
+	}
+	return nil
+}
+
+func (c *ReportConfig) period() time.Duration {
+	switch c.Interval {
+	case RotateHourly:
+		return time.Hour
+	case RotateWeekly:
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+func startReporter(l *Logger, config *ReportConfig) *reporter {
+	client, _ := outbound.NewHTTPClient(outbound.ClientOptions{Timeout: 10 * time.Second})
+	r := &reporter{
+		logger:    l,
+		config:    config,
+		client:    client,
+		from:      time.Now(),
+		errCounts: make(map[string]uint64),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if config.Email {
+		r.email = email.New(l.config.Alerts.Email)
+	}
+	go r.run()
+	return r
+}
+
+func (r *reporter) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.config.period())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.send(context.Background()); err != nil {
+				r.logger.selfLog.report("report", err)
+			}
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+func (r *reporter) Close() error {
+	r.once.Do(func() { close(r.stop) })
+	<-r.done
+	return nil
+}
+
+func (r *reporter) request(level LogLevel, latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests++
+	if levelPriority(level) >= levelPriority(LevelError) {
+		r.errors++
+	}
+
+	// reservoir sampling keeps the latency sample uniform over the whole period
+	if len(r.latencies) < reportLatencySamples {
+		r.latencies = append(r.latencies, latency)
+	} else if i := rand.Uint64N(r.requests); i < reportLatencySamples {
+		r.latencies[i] = latency
+	}
+}
+
+func (r *reporter) error(err error) {
+	key := err.Error()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.errCounts[key]; !ok && len(r.errCounts) >= reportMaxErrorKeys {
+		key = reportOtherErrors
+	}
+	r.errCounts[key]++
+}
+
+/**
+ * snapshot builds the report for the period so far and, with reset, starts a
+ * new period.
+ */
+func (r *reporter) snapshot(reset bool) Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	report := Report{
+		Service:     r.logger.config.ServiceName,
+		Environment: r.logger.config.Environment,
+		Hostname:    r.logger.hostname,
+		From:        r.from,
+		To:          now,
+		Requests:    r.requests,
+		Errors:      r.errors,
+		TopErrors:   []ReportError{},
+	}
+	if r.requests > 0 {
+		report.ErrorRate = float64(r.errors) / float64(r.requests)
+	}
+	if len(r.latencies) > 0 {
+		sorted := append([]time.Duration{}, r.latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		report.P95LatencyMs = sorted[(len(sorted)*95+99)/100-1].Milliseconds()
+	}
+
+	for msg, count := range r.errCounts {
+		report.TopErrors = append(report.TopErrors, ReportError{Message: msg, Count: count})
+	}
+	sort.Slice(report.TopErrors, func(i, j int) bool {
+		if report.TopErrors[i].Count != report.TopErrors[j].Count {
+			return report.TopErrors[i].Count > report.TopErrors[j].Count
+		}
+		return report.TopErrors[i].Message < report.TopErrors[j].Message
+	})
+	top := r.config.TopErrors
+	if top <= 0 {
+		top = defaultReportTopErrors
+	}
+	if len(report.TopErrors) > top {
+		report.TopErrors = report.TopErrors[:top]
+	}
+
+	if reset {
+		r.from = now
+		r.requests, r.errors = 0, 0
+		r.latencies = r.latencies[:0]
+		r.errCounts = make(map[string]uint64)
+	}
+	return report
+}
+
+func (r *reporter) send(ctx context.Context) error {
+	report := r.snapshot(true)
+	var firstErr error
+
+	if r.config.WebhookURL != "" {
+		body, _ := json.Marshal(report)
+		resp, err := outbound.PostJSON(ctx, r.client, r.config.WebhookURL, body)
+		if err != nil {
+			firstErr = fmt.Errorf("failed to post report: %w", err)
+		} else {
+			resp.Body.Close()
+			if resp.StatusCode >= 400 {
+				firstErr = fmt.Errorf("report webhook returned status %d", resp.StatusCode)
+			}
+		}
+	}
+
+	if r.email != nil {
+		var htmlBuf, textBuf bytes.Buffer
+		if err := reportTemplate.Execute(&htmlBuf, report); err != nil {
+			return err
+		}
+		if err := reportTextTemplate.Execute(&textBuf, report); err != nil {
+			return err
+		}
+		if err := r.email.SendMessage(ctx, report.subject(), htmlBuf.String(), textBuf.String()); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to email report: %w", err)
+		}
+	}
+
+	return firstErr
+}
+
+func (r Report) subject() string {
+	service := r.Service
+	if r.Environment != "" {
+		service += " (" + r.Environment + ")"
+	}
+	return fmt.Sprintf("[Report] %s - %d requests, %.1f%% errors", service, r.Requests, r.ErrorRate*100)
+}
+
+/**
+ * Report returns the digest for the current period without starting a new
+ * one. Without Config.Report it is empty apart from the service fields.
+ *
+ * @return Report Counters since New or the last sent report
+ */
+func (l *Logger) Report() Report {
+	if l.reporter == nil {
+		return Report{Service: l.config.ServiceName, Environment: l.config.Environment, Hostname: l.hostname}
+	}
+	return l.reporter.snapshot(false)
+}
+
+/**
+ * SendReport sends the digest for the current period now, e.g. from an
+ * external scheduler, and starts a new period.
+ *
+ * @param ctx Cancels the delivery
+ * @return error Error if reporting is disabled or a delivery failed
+ */
+func (l *Logger) SendReport(ctx context.Context) error {
+	if l.reporter == nil {
+		return fmt.Errorf("reporting is not configured")
+	}
+	return l.reporter.send(ctx)
+}
+
+var reportTextTemplate = texttemplate.Must(texttemplate.New("report").Parse(`{{.Service}} report
+{{.From.Format "02 Jan 2006 15:04"}} - {{.To.Format "02 Jan 2006 15:04"}}{{if .Environment}} ({{.Environment}}){{end}}{{if .Hostname}} on {{.Hostname}}{{end}}
+
+Requests:    {{.Requests}}
+Errors:      {{.Errors}}
+p95 latency: {{.P95LatencyMs}} ms
+
+Top errors
+{{range .TopErrors}}  {{.Count}}x {{.Message}}
+{{else}}  No errors
+{{end}}`))
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(v float64) string { return fmt.Sprintf("%.2f%%", v*100) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<body style="margin:0;padding:24px;background-color:#f0f0f0;font-family:'Helvetica Neue',Helvetica,Arial,sans-serif;color:#333;">
+<table width="600" cellpadding="0" cellspacing="0" style="background-color:#ffffff;border-radius:8px;padding:32px 40px;">
+<tr><td>
+<h1 style="margin:0 0 4px 0;font-size:22px;font-weight:600;">{{.Service}} report</h1>
+<p style="margin:0 0 24px 0;font-size:13px;color:#666;">{{.From.Format "02 Jan 2006 15:04"}} - {{.To.Format "02 Jan 2006 15:04"}}{{if .Environment}} • {{.Environment}}{{end}}{{if .Hostname}} • {{.Hostname}}{{end}}</p>
+<table width="100%" cellpadding="8" cellspacing="0" style="margin-bottom:24px;">
+<tr><td style="color:#999;font-size:12px;">Requests</td><td style="color:#999;font-size:12px;">Error rate</td><td style="color:#999;font-size:12px;">p95 latency</td></tr>
+<tr><td style="font-size:20px;font-weight:600;">{{.Requests}}</td><td style="font-size:20px;font-weight:600;">{{percent .ErrorRate}}</td><td style="font-size:20px;font-weight:600;">{{.P95LatencyMs}} ms</td></tr>
+</table>
+<p style="margin:0 0 8px 0;font-size:11px;text-transform:uppercase;letter-spacing:1px;color:#999;font-weight:600;">Top errors</p>
+<table width="100%" cellpadding="8" cellspacing="0" style="border:1px solid #e9ecef;">
+{{range .TopErrors}}
+<tr><td style="border-bottom:1px solid #e9ecef;font-family:'Courier New',monospace;font-size:13px;word-break:break-word;">{{.Message}}</td><td align="right" style="border-bottom:1px solid #e9ecef;font-weight:600;">{{.Count}}</td></tr>
+{{else}}
+<tr><td style="color:#888;text-align:center;">No errors</td></tr>
+{{end}}
+</table>
+</td></tr>
+</table>
+</body>
+</html>`))
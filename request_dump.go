@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+)
+
+/**
+ * DefaultRedactHeaders are always replaced with [REDACTED] in request dumps;
+ * Config.RedactHeaders adds to them.
+ */
+var DefaultRedactHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+/**
+ * RequestDumpEnabled reports whether recovery middleware should attach a
+ * request dump to panics (Config.DumpRequestOnPanic).
+ *
+ * @return bool True if panics carry a request dump
+ */
+func (l *Logger) RequestDumpEnabled() bool {
+	return l.config.DumpRequestOnPanic
+}
+
+/**
+ * DumpRequest renders r as with httputil.DumpRequest, with sensitive headers
+ * redacted. The body is passed separately because handlers usually consumed
+ * it by the time the dump is taken.
+ *
+ * @param r Request to dump
+ * @param body Body captured before the handler ran (may be empty)
+ * @return string Request line, headers and body
+ */
+func (l *Logger) DumpRequest(r *http.Request, body string) string {
+	clone := r.Clone(r.Context())
+	clone.Body = nil
+	for _, name := range append(DefaultRedactHeaders, l.config.RedactHeaders...) {
+		if clone.Header.Get(name) != "" {
+			clone.Header.Set(name, "[REDACTED]")
+		}
+	}
+
+	b, err := httputil.DumpRequest(clone, false)
+	if err != nil {
+		return ""
+	}
+
+	dump := strings.TrimSpace(strings.ReplaceAll(string(b), "\r\n", "\n"))
+	if body != "" {
+		dump += "\n\n" + body
+	}
+	return dump
+}
+
+type requestDumpError struct {
+	err  error
+	dump string
+}
+
+func (e *requestDumpError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestDumpError) Unwrap() error {
+	return e.err
+}
+
+/**
+ * WithRequestDump attaches a request dump to err. The error message is
+ * unchanged; the dump is printed in the error log and sent as a "Request"
+ * field with alerts.
+ *
+ * @param err Error raised while serving the request
+ * @param dump Output of DumpRequest
+ * @return error err carrying the dump
+ */
+func WithRequestDump(err error, dump string) error {
+	if err == nil || dump == "" {
+		return err
+	}
+	return &requestDumpError{err: err, dump: dump}
+}
+
+func requestDumpFrom(err error) string {
+	var de *requestDumpError
+	if errors.As(err, &de) {
+		return de.dump
+	}
+	return ""
+}
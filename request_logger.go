@@ -0,0 +1,167 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+)
+
+type loggerKey struct{}
+
+/**
+ * WithLogger stores l in ctx for FromRequest and RequestLoggerFromContext. The
+ * request middleware does this for every request.
+ *
+ * @param ctx Request context
+ * @param l Logger handling the request
+ * @return context.Context Context carrying the logger
+ */
+func WithLogger(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+/**
+ * RequestLogger is a logger bound to one request's context, so every call
+ * carries the request's Meta without passing ctx around:
+ * reqLog.Info("cache miss"). Methods on a nil RequestLogger do nothing.
+ */
+type RequestLogger struct {
+	logger *Logger
+	ctx    context.Context
+}
+
+/**
+ * ForContext returns a logger bound to ctx.
+ *
+ * @param ctx Context carrying request metadata
+ * @return *RequestLogger Request-scoped logger
+ */
+func (l *Logger) ForContext(ctx context.Context) *RequestLogger {
+	return &RequestLogger{logger: l.WithCallerSkip(1), ctx: ctx}
+}
+
+/**
+ * RequestLoggerFromContext returns a logger bound to ctx, using the logger stored
+ * by the request middleware.
+ *
+ * @param ctx Request context
+ * @return *RequestLogger Request-scoped logger, nil if ctx carries no logger
+ */
+func RequestLoggerFromContext(ctx context.Context) *RequestLogger {
+	l, ok := ctx.Value(loggerKey{}).(*Logger)
+	if !ok || l == nil {
+		return nil
+	}
+	return l.ForContext(ctx)
+}
+
+/**
+ * FromRequest returns a logger bound to r's context. r must have passed
+ * GinMiddleware, HTTPMiddleware, MuxMiddleware or the connect interceptor.
+ *
+ * @param r Incoming request
+ * @return *RequestLogger Request-scoped logger, nil outside the middleware
+ */
+func FromRequest(r *http.Request) *RequestLogger {
+	return RequestLoggerFromContext(r.Context())
+}
+
+/**
+ * Context returns the request context the logger is bound to.
+ *
+ * @return context.Context Request context
+ */
+func (r *RequestLogger) Context() context.Context {
+	if r == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+/**
+ * Info writes an INFO message prefixed with the request ID, method and path to
+ * the access stream and Loki.
+ *
+ * @param msg Message to log
+ */
+func (r *RequestLogger) Info(msg string) {
+	if r == nil {
+		return
+	}
+	r.logger.logMessage(r.ctx, LevelInfo, msg, r.logger.accessLogger)
+}
+
+/**
+ * Warn is Logger.Warn with the bound context.
+ *
+ * @param msg Message to log
+ */
+func (r *RequestLogger) Warn(msg string) {
+	if r == nil {
+		return
+	}
+	r.logger.logMessage(r.ctx, LevelWarn, msg, r.logger.accessLogger)
+}
+
+/**
+ * Debug is Logger.Debug with the bound context.
+ *
+ * @param msg Message to log
+ */
+func (r *RequestLogger) Debug(msg string) {
+	if r == nil {
+		return
+	}
+	r.logger.logMessage(r.ctx, LevelDebug, msg, r.logger.debugLogger)
+}
+
+/**
+ * Trace is Logger.Trace with the bound context.
+ *
+ * @param msg Message to log
+ */
+func (r *RequestLogger) Trace(msg string) {
+	if r == nil {
+		return
+	}
+	r.logger.logMessage(r.ctx, LevelTrace, msg, r.logger.debugLogger)
+}
+
+/**
+ * Error is Logger.Error with the bound context. The source and stack start at
+ * the caller of this method.
+ *
+ * @param err Error to log
+ */
+func (r *RequestLogger) Error(err error) {
+	if r == nil {
+		return
+	}
+	r.logger.Error(r.ctx, err)
+}
+
+/**
+ * ErrorMsg is Logger.ErrorMsg with the bound context.
+ *
+ * @param msg Human description, e.g. "failed to charge card"
+ * @param err Error to log
+ */
+func (r *RequestLogger) ErrorMsg(msg string, err error) {
+	if r == nil {
+		return
+	}
+	r.logger.ErrorMsg(r.ctx, msg, err)
+}
+
+/**
+ * Errorf is Logger.Errorf with the bound context; the error is taken from the
+ * last argument.
+ *
+ * @param format Description format
+ * @param args Format arguments followed by the error
+ */
+func (r *RequestLogger) Errorf(format string, args ...interface{}) {
+	if r == nil {
+		return
+	}
+	r.logger.Errorf(r.ctx, format, args...)
+}
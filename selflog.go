@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultSelfLogRateSec = 60
+
+/**
+ * selfLog is the channel for the library's own failures: file writes falling
+ * back, Loki pushes failing, alerts not delivered, entries that cannot be
+ * encoded. Each distinct failure (source + message) is written at most once per
+ * interval; repeats are counted and reported with the next line that gets out.
+ */
+type selfLog struct {
+	mu         sync.Mutex
+	out        io.Writer
+	interval   time.Duration
+	last       map[string]time.Time
+	suppressed map[string]int
+	total      atomic.Uint64
+}
+
+// defaultSelfLog serves the package-level functions (LogLoki, ...) that have no Logger
+var defaultSelfLog = newSelfLog(os.Stderr, defaultSelfLogRateSec*time.Second)
+
+func newSelfLog(out io.Writer, interval time.Duration) *selfLog {
+	return &selfLog{
+		out:        out,
+		interval:   interval,
+		last:       make(map[string]time.Time),
+		suppressed: make(map[string]int),
+	}
+}
+
+/**
+ * report records an internal failure. It never blocks on or returns errors from
+ * the output, so it is safe to call from any write path.
+ *
+ * @param source Component that failed (e.g. "writer", "loki.push", "alerts.slack")
+ * @param err The failure
+ */
+func (s *selfLog) report(source string, err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.total.Add(1)
+
+	key := source + ": " + err.Error()
+	now := time.Now()
+
+	s.mu.Lock()
+	if last, ok := s.last[key]; ok && now.Sub(last) < s.interval {
+		s.suppressed[key]++
+		s.mu.Unlock()
+		return
+	}
+	repeated := s.suppressed[key]
+	delete(s.suppressed, key)
+	s.last[key] = now
+
+	// forget keys that have been quiet for a while so the map stays bounded
+	for k, t := range s.last {
+		if now.Sub(t) > 2*s.interval && s.suppressed[k] == 0 {
+			delete(s.last, k)
+		}
+	}
+	s.mu.Unlock()
+
+	line := fmt.Sprintf("%s [go-logging-lib] %s", now.Format(time.RFC3339), key)
+	if repeated > 0 {
+		line += fmt.Sprintf(" (%d similar suppressed)", repeated)
+	}
+	fmt.Fprintln(s.out, line)
+}
@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+/**
+ * serverlessConfig returns a copy of config set up for Serverless mode: no
+ * files or plain stdout copies, single-line JSON for the error and Loki
+ * streams (written to stdout by setupWriters).
+ */
+func serverlessConfig(config *Config) *Config {
+	cfg := *config
+	cfg.EnableFile = false
+	cfg.EnableStdout = false
+	cfg.ErrorLogFormat = FormatJSON
+	cfg.LokiFormat = FormatJSON
+	return &cfg
+}
+
+/**
+ * ServerlessEnabled reports whether the logger runs in Serverless mode, where
+ * middleware takes the request ID from the platform and drains the logger
+ * before the handler returns.
+ *
+ * @return bool True if Config.Serverless is set
+ */
+func (l *Logger) ServerlessEnabled() bool {
+	return l.config.Serverless
+}
+
+/**
+ * PlatformRequestID returns the request ID assigned by the serverless platform:
+ * request_id from the X-Amzn-Lambda-Context header (AWS Lambda Web Adapter) or
+ * the trace ID from X-Cloud-Trace-Context (Cloud Run).
+ *
+ * @param h Incoming request headers
+ * @return string Platform request ID, empty if none is present
+ */
+func PlatformRequestID(h http.Header) string {
+	if v := h.Get("X-Amzn-Lambda-Context"); v != "" {
+		var lc struct {
+			RequestID string `json:"request_id"`
+		}
+		if json.Unmarshal([]byte(v), &lc) == nil && lc.RequestID != "" {
+			return lc.RequestID
+		}
+	}
+
+	if v := h.Get("X-Cloud-Trace-Context"); v != "" {
+		// TRACE_ID/SPAN_ID;o=OPTIONS
+		traceID, _, _ := strings.Cut(v, "/")
+		traceID, _, _ = strings.Cut(traceID, ";")
+		return traceID
+	}
+
+	return ""
+}
+
+/**
+ * RequestIDFromHeader picks the request ID for an incoming request: the
+ * platform request ID in Serverless mode, then X-Request-ID, then a new UUID.
+ *
+ * @param h Incoming request headers
+ * @return string Request ID, never empty
+ */
+func (l *Logger) RequestIDFromHeader(h http.Header) string {
+	if l.config.Serverless {
+		if id := PlatformRequestID(h); id != "" {
+			return id
+		}
+	}
+	if id := h.Get("X-Request-ID"); id != "" {
+		return id
+	}
+	return uuid.NewString()
+}
+
+/**
+ * Drain waits for in-flight alerts, for at most Alerts.DrainTimeoutSec
+ * (default 5) seconds or until ctx is done, then flushes the Loki sink and
+ * log files. Unlike Close the logger stays usable, so a serverless handler can
+ * call it before returning, when the runtime may freeze the process.
+ * Middleware does this automatically in Serverless mode.
+ *
+ * @param ctx Bounds how long to wait for alerts
+ * @return error Alert wait timeout or first flush error, if any
+ */
+func (l *Logger) Drain(ctx context.Context) error {
+	var firstErr error
+
+	if l.alertManager != nil {
+		waitCtx, cancel := context.WithTimeout(ctx, l.drainTimeout())
+		firstErr = l.alertManager.Wait(waitCtx)
+		cancel()
+	}
+
+	if err := l.Flush(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
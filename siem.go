@@ -0,0 +1,216 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	siemVendor  = "go-logging-lib"
+	siemVersion = "1.0"
+)
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+	leefHeaderEscaper   = strings.NewReplacer(`|`, `\|`, "\n", " ", "\r", " ", "\t", " ")
+	leefValueEscaper    = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+)
+
+/**
+ * siemEvent holds the parts of a Loki event that CEF and LEEF lines are built from.
+ */
+type siemEvent struct {
+	ts            time.Time
+	level         string
+	service       string
+	requestID     string
+	correlationID string
+	status        string
+	latencyMs     string
+	http          map[string]string
+	message       string
+}
+
+func newSIEMEvent(ev map[string]interface{}) siemEvent {
+	e := siemEvent{
+		level:         fieldString(ev["level"]),
+		service:       fieldString(ev["service"]),
+		requestID:     fieldString(ev["request_id"]),
+		correlationID: fieldString(ev["correlation_id"]),
+		status:        fieldString(ev["status_code"]),
+		latencyMs:     fieldString(ev["latency_ms"]),
+		message:       fieldString(ev["message"]),
+	}
+
+	e.ts, _ = time.Parse(time.RFC3339, fieldString(ev["ts"]))
+	e.http, _ = ev["http"].(map[string]string)
+
+	if errs, ok := ev["errors"].(map[string]interface{}); ok {
+		e.message = fieldString(errs["error"])
+		if msg := fieldString(errs["message"]); msg != "" {
+			e.message = msg + ": " + e.message
+		}
+	}
+
+	return e
+}
+
+/**
+ * name is the short event description used as the CEF Name: the error or message
+ * if there is one, otherwise the request method and route.
+ */
+func (e siemEvent) name() string {
+	if e.message != "" {
+		return e.message
+	}
+
+	route := e.http["route"]
+	if route == "" {
+		route = e.http["path"]
+	}
+	return strings.TrimSpace(e.http["method"] + " " + route)
+}
+
+/**
+ * eventID is the CEF Signature ID / LEEF Event ID: the HTTP status, or the level
+ * for entries without one (Warn, Debug).
+ */
+func (e siemEvent) eventID() string {
+	if e.status != "" {
+		return e.status
+	}
+	return e.level
+}
+
+/**
+ * siemSeverity maps a level onto the CEF 0-10 (and LEEF 1-10) severity scale.
+ */
+func siemSeverity(level string) int {
+	switch LogLevel(level) {
+	case LevelTrace, LevelDebug:
+		return 1
+	case LevelInfo:
+		return 3
+	case LevelWarn:
+		return 5
+	case LevelError:
+		return 7
+	case LevelCritical, LevelFatal:
+		return 10
+	default:
+		return 5
+	}
+}
+
+/**
+ * encodeCEF renders a Loki event as an ArcSight CEF line. The service name is the
+ * device product and the HTTP status the signature ID; request details map onto
+ * the standard extension keys (src, requestMethod, request, ...) and IDs onto
+ * labelled custom strings.
+ *
+ * @param ev Loki event as built by buildLokiEvent
+ * @return string CEF line without trailing newline
+ */
+func encodeCEF(ev map[string]interface{}) string {
+	e := newSIEMEvent(ev)
+
+	header := fmt.Sprintf(
+		"CEF:0|%s|%s|%s|%s|%s|%d|",
+		cefHeaderEscaper.Replace(siemVendor),
+		cefHeaderEscaper.Replace(e.service),
+		siemVersion,
+		cefHeaderEscaper.Replace(e.eventID()),
+		cefHeaderEscaper.Replace(e.name()),
+		siemSeverity(e.level),
+	)
+
+	var ext []string
+	add := func(key, value string) {
+		if value != "" {
+			ext = append(ext, key+"="+cefExtensionEscaper.Replace(value))
+		}
+	}
+
+	if !e.ts.IsZero() {
+		add("rt", fmt.Sprint(e.ts.UnixMilli()))
+	}
+	add("src", e.http["ip"])
+	add("requestMethod", e.http["method"])
+	add("request", e.http["path"])
+	add("requestClientApplication", e.http["ua"])
+	if e.status != "" {
+		add("cn1", e.status)
+		add("cn1Label", "statusCode")
+	}
+	if e.latencyMs != "" {
+		add("cn2", e.latencyMs)
+		add("cn2Label", "latencyMs")
+	}
+	if e.requestID != "" {
+		add("cs1", e.requestID)
+		add("cs1Label", "requestId")
+	}
+	if e.correlationID != "" {
+		add("cs2", e.correlationID)
+		add("cs2Label", "correlationId")
+	}
+	add("msg", e.message)
+
+	return header + strings.Join(ext, " ")
+}
+
+/**
+ * encodeLEEF renders a Loki event as an IBM QRadar LEEF 1.0 line with
+ * tab-separated attributes.
+ *
+ * @param ev Loki event as built by buildLokiEvent
+ * @return string LEEF line without trailing newline
+ */
+func encodeLEEF(ev map[string]interface{}) string {
+	e := newSIEMEvent(ev)
+
+	header := fmt.Sprintf(
+		"LEEF:1.0|%s|%s|%s|%s|",
+		leefHeaderEscaper.Replace(siemVendor),
+		leefHeaderEscaper.Replace(e.service),
+		siemVersion,
+		leefHeaderEscaper.Replace(e.eventID()),
+	)
+
+	var attrs []string
+	add := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, key+"="+leefValueEscaper.Replace(value))
+		}
+	}
+
+	if !e.ts.IsZero() {
+		add("devTime", e.ts.Format("2006-01-02T15:04:05Z07:00"))
+		add("devTimeFormat", "yyyy-MM-dd'T'HH:mm:ssXXX")
+	}
+	add("sev", fmt.Sprint(siemSeverity(e.level)))
+	add("cat", e.level)
+	add("src", e.http["ip"])
+	add("method", e.http["method"])
+	add("url", e.http["path"])
+	add("userAgent", e.http["ua"])
+	add("status", e.status)
+	add("latencyMs", e.latencyMs)
+	add("requestId", e.requestID)
+	add("correlationId", e.correlationID)
+	add("msg", e.message)
+
+	return header + strings.Join(attrs, "\t")
+}
+
+func fieldString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
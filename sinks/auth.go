@@ -0,0 +1,43 @@
+package sinks
+
+import (
+	"net/http"
+	"os"
+)
+
+/**
+ * Auth holds credentials for a remote log endpoint. Every value may reference
+ * environment variables as $VAR or ${VAR}, expanded on each request, so
+ * secrets can stay out of config files and be rotated without a restart.
+ *
+ * BearerToken takes precedence over Username/Password. Headers are added to
+ * every request (e.g. API keys of hosted backends) and may override
+ * Authorization.
+ */
+type Auth struct {
+	Username    string            `yaml:"username"`
+	Password    string            `yaml:"password"`
+	BearerToken string            `yaml:"bearer_token"`
+	Headers     map[string]string `yaml:"headers,omitempty"`
+}
+
+/**
+ * Apply sets the authentication headers on req. A nil Auth does nothing.
+ *
+ * @param req Outgoing request
+ */
+func (a *Auth) Apply(req *http.Request) {
+	if a == nil {
+		return
+	}
+
+	if token := os.ExpandEnv(a.BearerToken); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if user := os.ExpandEnv(a.Username); user != "" {
+		req.SetBasicAuth(user, os.ExpandEnv(a.Password))
+	}
+
+	for k, v := range a.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+}
@@ -0,0 +1,233 @@
+package loki
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+/**
+ * Encoding selects the push request body format.
+ *
+ * EncodingJSON sends the JSON push API body uncompressed (default).
+ * EncodingGzip sends the same JSON gzip-compressed (Content-Encoding: gzip).
+ * EncodingSnappy sends a snappy-compressed protobuf PushRequest, the format
+ * promtail and the Loki ingesters use natively.
+ */
+type Encoding string
+
+const (
+	EncodingJSON   Encoding = "json"
+	EncodingGzip   Encoding = "gzip"
+	EncodingSnappy Encoding = "snappy"
+)
+
+type payload struct {
+	body            []byte
+	contentType     string
+	contentEncoding string
+}
+
+/**
+ * Valid reports whether e is a known encoding; empty means EncodingJSON.
+ *
+ * @return bool True for "", json, gzip and snappy
+ */
+func (e Encoding) Valid() bool {
+	switch e {
+	case "", EncodingJSON, EncodingGzip, EncodingSnappy:
+		return true
+	}
+	return false
+}
+
+/**
+ * encode builds the request body for a batch in the configured encoding.
+ */
+func (w *Writer) encode(batch []entry) (payload, error) {
+	switch w.config.Encoding {
+	case EncodingSnappy:
+		return payload{
+			body:        snappyEncode(encodeProtoPush(batch)),
+			contentType: "application/x-protobuf",
+		}, nil
+	case EncodingGzip:
+		body, err := json.Marshal(buildPushRequest(batch))
+		if err != nil {
+			return payload{}, err
+		}
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(body); err != nil {
+			return payload{}, err
+		}
+		if err := gz.Close(); err != nil {
+			return payload{}, err
+		}
+		return payload{body: buf.Bytes(), contentType: "application/json", contentEncoding: "gzip"}, nil
+	default:
+		body, err := json.Marshal(buildPushRequest(batch))
+		if err != nil {
+			return payload{}, err
+		}
+		return payload{body: body, contentType: "application/json"}, nil
+	}
+}
+
+/**
+ * encodeProtoPush writes a logproto.PushRequest:
+ *
+ *	PushRequest  { repeated StreamAdapter streams = 1; }
+ *	StreamAdapter { string labels = 1; repeated EntryAdapter entries = 2; }
+ *	EntryAdapter  { google.protobuf.Timestamp timestamp = 1; string line = 2; }
+ *	Timestamp     { int64 seconds = 1; int32 nanos = 2; }
+ */
+func encodeProtoPush(batch []entry) []byte {
+	streams := make(map[string][]entry)
+	order := make([]string, 0)
+
+	for _, e := range batch {
+		key := promLabels(e.labels)
+		if _, ok := streams[key]; !ok {
+			order = append(order, key)
+		}
+		streams[key] = append(streams[key], e)
+	}
+
+	var req []byte
+	for _, labels := range order {
+		var stream []byte
+		stream = protowire.AppendTag(stream, 1, protowire.BytesType)
+		stream = protowire.AppendString(stream, labels)
+
+		for _, e := range streams[labels] {
+			var ts []byte
+			ts = protowire.AppendTag(ts, 1, protowire.VarintType)
+			ts = protowire.AppendVarint(ts, uint64(e.ts.Unix()))
+			ts = protowire.AppendTag(ts, 2, protowire.VarintType)
+			ts = protowire.AppendVarint(ts, uint64(e.ts.Nanosecond()))
+
+			var ent []byte
+			ent = protowire.AppendTag(ent, 1, protowire.BytesType)
+			ent = protowire.AppendBytes(ent, ts)
+			ent = protowire.AppendTag(ent, 2, protowire.BytesType)
+			ent = protowire.AppendString(ent, e.line)
+
+			stream = protowire.AppendTag(stream, 2, protowire.BytesType)
+			stream = protowire.AppendBytes(stream, ent)
+		}
+
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, stream)
+	}
+
+	return req
+}
+
+/**
+ * promLabels formats labels the way the protobuf API expects them:
+ * {key="value", ...} with sorted keys.
+ */
+func promLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+strconv.Quote(labels[k]))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+const snappyMaxBlock = 65536
+
+/**
+ * snappyEncode compresses src in the snappy block format (not the framed
+ * stream format), which is what Loki expects for protobuf pushes.
+ */
+func snappyEncode(src []byte) []byte {
+	dst := binary.AppendUvarint(nil, uint64(len(src)))
+
+	for len(src) > 0 {
+		block := src
+		if len(block) > snappyMaxBlock {
+			block = block[:snappyMaxBlock]
+		}
+		src = src[len(block):]
+		dst = snappyEncodeBlock(dst, block)
+	}
+
+	return dst
+}
+
+/**
+ * snappyEncodeBlock greedily replaces 4+ byte repeats with copies, using a
+ * hash table of the last position each 4-byte sequence was seen at.
+ */
+func snappyEncodeBlock(dst, src []byte) []byte {
+	if len(src) < 16 {
+		return snappyLiteral(dst, src)
+	}
+
+	var table [1 << 14]uint16
+	lit := 0
+
+	for i := 0; i+4 <= len(src); {
+		cur := binary.LittleEndian.Uint32(src[i:])
+		h := (cur * 0x1e35a7bd) >> 18
+		cand := int(table[h])
+		table[h] = uint16(i)
+
+		if cand >= i || binary.LittleEndian.Uint32(src[cand:]) != cur {
+			i++
+			continue
+		}
+
+		end := i + 4
+		for end < len(src) && src[end] == src[cand+end-i] {
+			end++
+		}
+
+		dst = snappyLiteral(dst, src[lit:i])
+		dst = snappyCopy(dst, i-cand, end-i)
+		i, lit = end, end
+	}
+
+	return snappyLiteral(dst, src[lit:])
+}
+
+func snappyLiteral(dst, lit []byte) []byte {
+	if len(lit) == 0 {
+		return dst
+	}
+
+	n := len(lit) - 1
+	switch {
+	case n < 60:
+		dst = append(dst, byte(n<<2))
+	case n < 1<<8:
+		dst = append(dst, 60<<2, byte(n))
+	default:
+		dst = append(dst, 61<<2, byte(n), byte(n>>8))
+	}
+	return append(dst, lit...)
+}
+
+// snappyCopy emits copies with a 2-byte offset, at most 64 bytes each
+func snappyCopy(dst []byte, offset, length int) []byte {
+	for length > 0 {
+		n := min(length, 64)
+		dst = append(dst, byte((n-1)<<2|2), byte(offset), byte(offset>>8))
+		length -= n
+	}
+	return dst
+}
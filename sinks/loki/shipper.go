@@ -0,0 +1,339 @@
+package loki
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib/outbound"
+)
+
+const defaultPollInterval = time.Second
+
+/**
+ * ShipperConfig configures the embedded log shipper.
+ * Paths are glob patterns (e.g. logs/app.loki*.log) re-evaluated on every poll,
+ * so files created by rotation are picked up automatically. OnError receives
+ * shipping failures; without it they are printed to stdout.
+ */
+type ShipperConfig struct {
+	Loki          *Config                        `yaml:"loki"`
+	Paths         []string                       `yaml:"paths"`
+	PositionsFile string                         `yaml:"positions_file"`
+	PollInterval  time.Duration                  `yaml:"poll_interval"`
+	OnError       func(source string, err error) `yaml:"-"`
+}
+
+type Shipper struct {
+	config    ShipperConfig
+	writer    *Writer
+	mu        sync.Mutex
+	positions map[string]int64
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+/**
+ * NewShipper starts a shipper that tails the library's .loki files and pushes
+ * new lines to Loki, for environments where promtail cannot run. Read offsets
+ * are kept in PositionsFile and only advance after Loki accepted a batch, so a
+ * restart or a Loki outage resumes where it stopped instead of losing lines.
+ *
+ * @param config Shipper configuration (Loki.URL and Paths are required)
+ * @return *Shipper Running shipper, must be closed to ship the remaining lines
+ * @return error Error if the positions file cannot be read
+ */
+func NewShipper(config ShipperConfig) (*Shipper, error) {
+	if config.Loki == nil || config.Loki.URL == "" {
+		return nil, fmt.Errorf("loki push URL is empty")
+	}
+	if !config.Loki.Encoding.Valid() {
+		return nil, fmt.Errorf("unknown loki encoding %q", config.Loki.Encoding)
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultPollInterval
+	}
+
+	client, err := outbound.NewHTTPClient(config.Loki.clientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("invalid loki client config: %w", err)
+	}
+
+	labelKeys := config.Loki.LabelKeys
+	if len(labelKeys) == 0 {
+		labelKeys = []string{"service", "level"}
+	}
+
+	s := &Shipper{
+		config: config,
+		writer: &Writer{
+			config:    config.Loki,
+			client:    client,
+			labelKeys: labelKeys,
+			onError:   config.OnError,
+		},
+		positions: make(map[string]int64),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	if err := s.loadPositions(); err != nil {
+		return nil, err
+	}
+
+	go s.run()
+
+	return s, nil
+}
+
+/**
+ * Positions returns a copy of the current read offset per file.
+ *
+ * @return map[string]int64 Offsets keyed by resolved file path
+ */
+func (s *Shipper) Positions() map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string]int64, len(s.positions))
+	for k, v := range s.positions {
+		out[k] = v
+	}
+	return out
+}
+
+/**
+ * Close stops polling after a final pass over all files.
+ *
+ * @return error Error from the last positions save, if any
+ */
+func (s *Shipper) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+		err = s.savePositions()
+	})
+	return err
+}
+
+func (s *Shipper) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.poll()
+		case <-s.stop:
+			s.poll()
+			return
+		}
+	}
+}
+
+/**
+ * poll ships new lines from every matched file. A failed push leaves the file's
+ * offset untouched so the same lines are retried on the next poll.
+ */
+func (s *Shipper) poll() {
+	for _, file := range s.files() {
+		if err := s.shipFile(file); err != nil {
+			s.writer.reportError("loki.shipper", fmt.Errorf("%s: %w", file, err))
+		}
+	}
+}
+
+/**
+ * files resolves the configured globs to real paths, so the undated symlink and
+ * the dated file it points at are shipped once.
+ */
+func (s *Shipper) files() []string {
+	seen := make(map[string]bool)
+	var files []string
+
+	for _, pattern := range s.config.Paths {
+		matches, _ := filepath.Glob(pattern)
+		for _, m := range matches {
+			real, err := filepath.EvalSymlinks(m)
+			if err != nil {
+				continue
+			}
+			if abs, err := filepath.Abs(real); err == nil {
+				real = abs
+			}
+			if !seen[real] {
+				seen[real] = true
+				files = append(files, real)
+			}
+		}
+	}
+
+	sort.Strings(files)
+	return files
+}
+
+func (s *Shipper) shipFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	offset := s.positions[file]
+	s.mu.Unlock()
+
+	// truncated in place (copytruncate): start over
+	if info.Size() < offset {
+		offset = 0
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(f)
+	batchSize := s.config.Loki.batchSize()
+	batch := make([]entry, 0, batchSize)
+	pending := offset
+
+	flush := func() error {
+		if len(batch) > 0 {
+			if err := s.writer.push(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+		offset = pending
+		s.setPosition(file, offset)
+		return nil
+	}
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// an unterminated line is still being written; ship it next time
+			break
+		}
+		pending += int64(len(line))
+
+		trimmed := line[:len(line)-1]
+		if len(trimmed) == 0 {
+			continue
+		}
+
+		e := s.writer.splitLine(trimmed)
+		if ts, ok := lineTime(trimmed); ok {
+			e.ts = ts
+		}
+		batch = append(batch, e)
+
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(batch) == 0 && pending == s.Positions()[file] {
+		return nil
+	}
+	return flush()
+}
+
+func (s *Shipper) setPosition(file string, offset int64) {
+	s.mu.Lock()
+	s.positions[file] = offset
+	s.mu.Unlock()
+
+	if err := s.savePositions(); err != nil {
+		s.writer.reportError("loki.shipper", fmt.Errorf("failed to save positions: %w", err))
+	}
+}
+
+/**
+ * lineTime reads the entry timestamp so shipped lines keep their original time
+ * instead of the time they were shipped.
+ */
+func lineTime(line []byte) (time.Time, bool) {
+	var fields struct {
+		TS string `json:"ts"`
+	}
+	if err := json.Unmarshal(line, &fields); err != nil || fields.TS == "" {
+		return time.Time{}, false
+	}
+
+	ts, err := time.Parse(time.RFC3339, fields.TS)
+	return ts, err == nil
+}
+
+func (s *Shipper) loadPositions() error {
+	if s.config.PositionsFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.config.PositionsFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read positions file: %w", err)
+	}
+
+	var stored struct {
+		Positions map[string]int64 `json:"positions"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to parse positions file: %w", err)
+	}
+
+	for k, v := range stored.Positions {
+		s.positions[k] = v
+	}
+	return nil
+}
+
+/**
+ * savePositions writes the offsets atomically (temp file + rename), dropping
+ * files that no longer exist so the positions file does not grow forever.
+ */
+func (s *Shipper) savePositions() error {
+	if s.config.PositionsFile == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	current := make(map[string]int64, len(s.positions))
+	for file, offset := range s.positions {
+		if _, err := os.Stat(file); err == nil {
+			current[file] = offset
+		}
+	}
+	s.mu.Unlock()
+
+	data, err := json.Marshal(map[string]interface{}{"positions": current})
+	if err != nil {
+		return err
+	}
+
+	tmp := s.config.PositionsFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.config.PositionsFile)
+}
@@ -0,0 +1,263 @@
+package loki
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	spillFileName       = "loki-spill.wal"
+	defaultSpillMaxSize = 100 * 1024 * 1024
+)
+
+/**
+ * spool is the on-disk write-ahead log used while Loki is unreachable. Lines are
+ * appended as written; the replay offset is kept in a sidecar file and only
+ * advances after Loki accepted the lines, so delivery is at-least-once across
+ * outages and restarts. The file is truncated once everything was replayed.
+ */
+type spool struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	file    *os.File
+	size    int64
+	offset  int64
+}
+
+func openSpool(dir string, maxSize int64) (*spool, error) {
+	if maxSize <= 0 {
+		maxSize = defaultSpillMaxSize
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create spill dir: %w", err)
+	}
+
+	path := filepath.Join(dir, spillFileName)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spill file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	// a crash mid-append leaves a line without its newline; it can never be replayed
+	size, err := completeSize(path, info.Size())
+	if err == nil && size < info.Size() {
+		err = file.Truncate(size)
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to repair spill file: %w", err)
+	}
+
+	s := &spool{path: path, maxSize: maxSize, file: file, size: size}
+	s.offset = s.loadOffset()
+	if s.offset > s.size {
+		s.offset = 0
+	}
+	return s, nil
+}
+
+/**
+ * completeSize returns the length of the file up to and including its last
+ * newline, i.e. without a torn trailing line.
+ */
+func completeSize(path string, size int64) (int64, error) {
+	if size == 0 {
+		return 0, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, 4096)
+	for end := size; end > 0; {
+		start := end - int64(len(buf))
+		if start < 0 {
+			start = 0
+		}
+		chunk := buf[:end-start]
+		if _, err := f.ReadAt(chunk, start); err != nil {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+/**
+ * active reports whether lines are waiting for replay. While it is true, new
+ * entries are appended behind them to keep the original order.
+ */
+func (s *spool) active() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.size > s.offset
+}
+
+// tenantMark frames the tenant of a spilled line: \x1etenant\x1eline
+const tenantMark = '\x1e'
+
+type spoolRecord struct {
+	tenant string
+	line   []byte
+}
+
+/**
+ * append writes one line to the log, prefixed with its tenant if it has one.
+ *
+ * @return bool False if the line would exceed the size limit and was not written
+ * @return error Write error, if any
+ */
+func (s *spool) append(tenant string, line []byte) (bool, error) {
+	buf := make([]byte, 0, len(tenant)+len(line)+3)
+	if tenant != "" {
+		buf = append(append(append(buf, tenantMark), tenant...), tenantMark)
+	}
+	buf = append(append(buf, line...), '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size+int64(len(buf)) > s.maxSize {
+		return false, nil
+	}
+
+	if _, err := s.file.Write(buf); err != nil {
+		// drop the partial line so the next append does not glue onto it
+		if terr := s.file.Truncate(s.size); terr != nil {
+			return false, fmt.Errorf("%w (truncate: %v)", err, terr)
+		}
+		return false, err
+	}
+	s.size += int64(len(buf))
+	return true, nil
+}
+
+// position returns the current replay offset
+func (s *spool) position() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.offset
+}
+
+/**
+ * read returns up to max complete lines after the replay offset and the offset
+ * that commit should advance to once they are delivered. The offset does not
+ * move when no complete line follows it.
+ */
+func (s *spool) read(max int) ([]spoolRecord, int64, error) {
+	s.mu.Lock()
+	offset, size := s.offset, s.size
+	s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, offset, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+
+	reader := bufio.NewReader(io.LimitReader(f, size-offset))
+	var records []spoolRecord
+	next := offset
+
+	for len(records) < max {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			break
+		}
+		next += int64(len(line))
+		if len(line) > 1 {
+			records = append(records, parseSpoolRecord(line[:len(line)-1]))
+		}
+	}
+
+	return records, next, nil
+}
+
+func parseSpoolRecord(line []byte) spoolRecord {
+	if len(line) > 0 && line[0] == tenantMark {
+		if end := bytes.IndexByte(line[1:], tenantMark); end >= 0 {
+			return spoolRecord{tenant: string(line[1 : end+1]), line: line[end+2:]}
+		}
+	}
+	return spoolRecord{line: line}
+}
+
+/**
+ * commit records that everything before next was delivered. When the log is
+ * fully replayed it is truncated so it does not grow across outages.
+ */
+func (s *spool) commit(next int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.offset = next
+	if s.offset >= s.size {
+		if err := s.file.Truncate(0); err != nil {
+			return err
+		}
+		s.size, s.offset = 0, 0
+	}
+
+	return s.saveOffset()
+}
+
+func (s *spool) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+func (s *spool) loadOffset() int64 {
+	data, err := os.ReadFile(s.path + ".pos")
+	if err != nil {
+		return 0
+	}
+
+	var stored struct {
+		Offset int64 `json:"offset"`
+	}
+	if json.Unmarshal(data, &stored) != nil {
+		return 0
+	}
+	return stored.Offset
+}
+
+// saveOffset writes the offset atomically; the caller holds s.mu
+func (s *spool) saveOffset() error {
+	data, err := json.Marshal(map[string]int64{"offset": s.offset})
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".pos.tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path+".pos")
+}
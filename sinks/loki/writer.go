@@ -0,0 +1,781 @@
+package loki
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib/outbound"
+	"github.com/ahmadsaubani/go-logging-lib/sinks"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	defaultQueueSize     = 1000
+	defaultMaxRetries    = 3
+	defaultProbeInterval = 30 * time.Second
+	retryBackoff         = 100 * time.Millisecond
+)
+
+/**
+ * Config configures the Loki push sink.
+ * ProbeIntervalSec applies once a fallback is set (SetFallback): after a failed
+ * push, entries go straight to the fallback and Loki is retried with live
+ * traffic at most once per interval (default: 30). The same interval paces
+ * spill replay attempts.
+ *
+ * SpillDir enables disk spill: entries that cannot be pushed (or do not fit the
+ * in-memory queue) are appended to a write-ahead log in that directory and
+ * replayed in order once Loki accepts pushes again. SpillMaxMB bounds the log
+ * (default: 100); entries beyond it are dropped. Spill takes precedence over
+ * SetFallback.
+ *
+ * Entries are pushed in batches of BatchSize (default: 100) or every
+ * FlushInterval (default: 1s), whichever comes first. QueueSize bounds the
+ * entries waiting for a batch (default: 1000); a full queue never blocks the
+ * caller, the entry is spilled, sent to the fallback or dropped instead. A push
+ * that fails on the network or with a 5xx/429 is retried MaxRetries times
+ * (default: 3, negative disables) with exponential backoff from 100ms.
+ *
+ * Encoding selects the request body: json (default), gzip (compressed JSON) or
+ * snappy (snappy-compressed protobuf, Loki's native format).
+ *
+ * TenantID is sent as X-Scope-OrgID for multi-tenant Loki and Grafana Cloud;
+ * WriteTenant overrides it per entry. Auth adds basic auth, a bearer token or
+ * custom headers to every push (values may reference $ENV variables). TLS
+ * sets a private CA, client certificate (mTLS) or minimum TLS version. Proxy
+ * is a proxy URL, "direct", or empty for HTTPS_PROXY/NO_PROXY.
+ */
+type Config struct {
+	Enabled          bool                `yaml:"enabled"`
+	URL              string              `yaml:"url"`
+	LabelKeys        []string            `yaml:"label_keys"`
+	BatchSize        int                 `yaml:"batch_size"`
+	FlushInterval    time.Duration       `yaml:"flush_interval"`
+	QueueSize        int                 `yaml:"queue_size"`
+	MaxRetries       int                 `yaml:"max_retries"`
+	Encoding         Encoding            `yaml:"encoding"`
+	TenantID         string              `yaml:"tenant_id"`
+	Auth             *sinks.Auth         `yaml:"auth,omitempty"`
+	TLS              *outbound.TLSConfig `yaml:"tls,omitempty"`
+	Proxy            string              `yaml:"proxy"`
+	ProbeIntervalSec int                 `yaml:"probe_interval_sec"`
+	SpillDir         string              `yaml:"spill_dir"`
+	SpillMaxMB       int                 `yaml:"spill_max_mb"`
+}
+
+func (c *Config) clientOptions() outbound.ClientOptions {
+	return outbound.ClientOptions{TLS: c.TLS, Proxy: c.Proxy, Timeout: 10 * time.Second}
+}
+
+func (c *Config) batchSize() int {
+	if c.BatchSize > 0 {
+		return c.BatchSize
+	}
+	return defaultBatchSize
+}
+
+func (c *Config) flushInterval() time.Duration {
+	if c.FlushInterval > 0 {
+		return c.FlushInterval
+	}
+	return defaultFlushInterval
+}
+
+func (c *Config) queueSize() int {
+	if c.QueueSize > 0 {
+		return c.QueueSize
+	}
+	return defaultQueueSize
+}
+
+func (c *Config) maxRetries() int {
+	switch {
+	case c.MaxRetries < 0:
+		return 0
+	case c.MaxRetries == 0:
+		return defaultMaxRetries
+	}
+	return c.MaxRetries
+}
+
+type entry struct {
+	labels map[string]string
+	ts     time.Time
+	line   string
+	raw    []byte
+	tenant string
+}
+
+type Writer struct {
+	config    *Config
+	client    *http.Client
+	clientErr error
+	labelKeys []string
+	queue     chan entry
+	flushReq  chan chan error
+	done      chan struct{}
+	closeOnce sync.Once
+	mu        sync.RWMutex // guards closed against sends racing Close
+	closed    bool
+	onError   func(source string, err error)
+	fallback  io.Writer
+	spool     *spool
+	spoolErr  error
+	down      atomic.Bool
+	nextProbe atomic.Int64
+	pushed    atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+	failover  atomic.Uint64
+	spilled   atomic.Uint64
+	replayed  atomic.Uint64
+}
+
+/**
+ * Stats counts entries by outcome: accepted by Loki, lost in a failed push,
+ * dropped because the queue was full, or written to the fallback instead.
+ * OnFallback reports whether Loki is currently considered down. Spilled counts
+ * entries written to the spill log and Replayed those later accepted from it.
+ */
+type Stats struct {
+	Pushed     uint64 `json:"pushed"`
+	Failed     uint64 `json:"failed"`
+	Dropped    uint64 `json:"dropped"`
+	FailedOver uint64 `json:"failed_over"`
+	OnFallback bool   `json:"on_fallback"`
+	Spilled    uint64 `json:"spilled"`
+	Replayed   uint64 `json:"replayed"`
+}
+
+/**
+ * New creates a Loki push writer.
+ * Every JSON line written is split into stream labels (fields listed in LabelKeys)
+ * and line content (everything else), then pushed in batches to the Loki push API.
+ * A spill log left over from a previous run (SpillDir) is replayed first.
+ *
+ * @param config Loki sink configuration including push URL and label keys
+ * @return *Writer Ready-to-use Loki writer, must be closed to flush pending entries
+ */
+func New(config *Config) *Writer {
+	labelKeys := config.LabelKeys
+	if len(labelKeys) == 0 {
+		labelKeys = []string{"service", "level"}
+	}
+
+	client, clientErr := outbound.NewHTTPClient(config.clientOptions())
+
+	w := &Writer{
+		config:    config,
+		client:    client,
+		clientErr: clientErr,
+		labelKeys: labelKeys,
+		queue:     make(chan entry, config.queueSize()),
+		flushReq:  make(chan chan error),
+		done:      make(chan struct{}),
+	}
+
+	if config.SpillDir != "" {
+		s, err := openSpool(config.SpillDir, int64(config.SpillMaxMB)*1024*1024)
+		if err != nil {
+			// the error handler is not set yet; SetErrorHandler reports it
+			w.spoolErr = err
+		} else {
+			w.spool = s
+		}
+	}
+
+	go w.run()
+
+	return w
+}
+
+/**
+ * Write queues a single JSON log line for pushing.
+ * Lines that are not valid JSON are pushed as-is with no extracted labels.
+ * Entries are dropped when the queue is full so logging never blocks on Loki,
+ * unless a fallback is set, which then receives them. While Loki is down the
+ * fallback receives every entry until the next probe is due.
+ *
+ * @param p JSON encoded log line
+ * @return int Number of bytes accepted (always len(p))
+ * @return error Always nil
+ */
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.WriteTenant("", p)
+}
+
+/**
+ * WriteTenant queues a line for a specific tenant (X-Scope-OrgID), overriding
+ * Config.TenantID. Entries of different tenants are pushed in separate
+ * requests; an empty tenant uses Config.TenantID.
+ *
+ * @param tenant Loki tenant ID for this entry
+ * @param p JSON encoded log line
+ * @return int Number of bytes accepted (always len(p))
+ * @return error Always nil
+ */
+func (w *Writer) WriteTenant(tenant string, p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		// the queue and spill log are gone; keep the line if there is somewhere to put it
+		if w.fallback != nil {
+			w.failover.Add(1)
+			w.fallback.Write(p)
+			return len(p), nil
+		}
+		w.dropped.Add(1)
+		w.reportError("loki.queue", fmt.Errorf("writer closed, dropping entry"))
+		return len(p), nil
+	}
+
+	if w.spool != nil && w.spool.active() {
+		w.spill(tenant, bytes.TrimRight(p, "\n"))
+		return len(p), nil
+	}
+
+	if w.fallback != nil && w.down.Load() && time.Now().UnixNano() < w.nextProbe.Load() {
+		w.failover.Add(1)
+		w.fallback.Write(p)
+		return len(p), nil
+	}
+
+	e := w.splitLine(bytes.TrimRight(p, "\n"))
+	e.tenant = tenant
+
+	select {
+	case w.queue <- e:
+	default:
+		if w.spool != nil {
+			w.spill(tenant, e.raw)
+			break
+		}
+		if w.fallback != nil {
+			w.failover.Add(1)
+			w.fallback.Write(p)
+			break
+		}
+		w.dropped.Add(1)
+		w.reportError("loki.queue", fmt.Errorf("queue full, dropping entry"))
+	}
+
+	return len(p), nil
+}
+
+/**
+ * SetFallback declares a secondary sink (typically a local file) for entries
+ * Loki did not accept. A failed push writes its batch to fw and switches the
+ * writer over; the first push after ProbeIntervalSec is the recovery probe and
+ * switches back when Loki accepts it. Call before the first Write; fw must be
+ * safe for concurrent use.
+ *
+ * @param fw Secondary sink receiving the original lines
+ */
+func (w *Writer) SetFallback(fw io.Writer) {
+	w.fallback = fw
+}
+
+/**
+ * SetErrorHandler routes push failures and queue overflows to fn instead of
+ * printing them to stdout. Call before the first Write. If SpillDir could not
+ * be opened in New, fn receives that error right away and the writer keeps
+ * pushing without spill.
+ *
+ * @param fn Receives the failing component ("loki.push", "loki.queue", "loki.failover", "loki.spill") and error
+ */
+func (w *Writer) SetErrorHandler(fn func(source string, err error)) {
+	w.onError = fn
+	if w.spoolErr != nil {
+		w.reportError("loki.spill", fmt.Errorf("spill disabled: %w", w.spoolErr))
+	}
+}
+
+func (w *Writer) reportError(source string, err error) {
+	if w.onError != nil {
+		w.onError(source, err)
+		return
+	}
+	fmt.Printf("[LokiWriter] %v\n", err)
+}
+
+/**
+ * Stats returns the entry counters since the writer was created.
+ *
+ * @return Stats Pushed, failed and dropped counts
+ */
+func (w *Writer) Stats() Stats {
+	return Stats{
+		Pushed:     w.pushed.Load(),
+		Failed:     w.failed.Load(),
+		Dropped:    w.dropped.Load(),
+		FailedOver: w.failover.Load(),
+		OnFallback: w.down.Load(),
+		Spilled:    w.spilled.Load(),
+		Replayed:   w.replayed.Load(),
+	}
+}
+
+/**
+ * Flush pushes every entry queued so far and waits for Loki to answer.
+ *
+ * @return error Error from the last push, if any
+ */
+func (w *Writer) Flush() error {
+	ack := make(chan error, 1)
+
+	select {
+	case w.flushReq <- ack:
+		return <-ack
+	case <-w.done:
+		return nil
+	}
+}
+
+/**
+ * Close stops the background pusher after flushing all queued entries. Spilled
+ * entries Loki does not accept by then stay on disk for the next start. Lines
+ * written after Close go to the fallback, or are dropped and reported.
+ *
+ * @return error Always nil
+ */
+func (w *Writer) Close() error {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+
+		close(w.queue)
+		<-w.done
+		if w.spool != nil {
+			w.spool.close()
+		}
+	})
+	return nil
+}
+
+func (w *Writer) splitLine(p []byte) entry {
+	e := entry{
+		labels: map[string]string{},
+		ts:     time.Now(),
+		line:   string(p),
+	}
+	if w.fallback != nil || w.spool != nil {
+		// p belongs to the caller (log.Logger reuses its buffer)
+		e.raw = append([]byte(nil), p...)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(p, &fields); err != nil {
+		return e
+	}
+
+	for _, key := range w.labelKeys {
+		value, ok := extractField(fields, strings.Split(key, "."))
+		if !ok {
+			continue
+		}
+		e.labels[labelName(key)] = value
+	}
+
+	if b, err := json.Marshal(fields); err == nil {
+		e.line = string(b)
+	}
+
+	return e
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.config.flushInterval())
+	defer ticker.Stop()
+
+	batchSize := w.config.batchSize()
+	batch := make([]entry, 0, batchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		// queued behind spilled entries: keep them in order on disk
+		if w.spool != nil && w.spool.active() {
+			w.spillBatch(batch)
+			batch = batch[:0]
+			return nil
+		}
+		err := w.push(batch)
+		switch {
+		case err != nil && w.spool != nil:
+			w.spillBatch(batch)
+			w.nextProbe.Store(time.Now().Add(w.probeInterval()).UnixNano())
+			w.reportError("loki.spill", fmt.Errorf("failed to push %d entries, spilling to disk: %w", len(batch), err))
+		case err != nil && w.fallback != nil:
+			w.failOver(batch, err)
+		case err != nil:
+			w.failed.Add(uint64(len(batch)))
+			w.reportError("loki.push", fmt.Errorf("failed to push %d entries: %w", len(batch), err))
+		default:
+			w.pushed.Add(uint64(len(batch)))
+			if w.down.Swap(false) {
+				w.reportError("loki.failover", fmt.Errorf("push to %s recovered, leaving fallback", w.config.URL))
+			}
+		}
+		batch = batch[:0]
+		return err
+	}
+
+	for {
+		select {
+		case e, ok := <-w.queue:
+			if !ok {
+				flush()
+				w.replay(true)
+				return
+			}
+			batch = append(batch, e)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+			w.replay(false)
+		case ack := <-w.flushReq:
+			err := w.drain(&batch, flush)
+			if rerr := w.replay(true); err == nil {
+				err = rerr
+			}
+			ack <- err
+		}
+	}
+}
+
+/**
+ * failOver writes a rejected batch to the fallback and keeps new entries off
+ * the queue until the next probe is due.
+ */
+func (w *Writer) failOver(batch []entry, err error) {
+	for _, e := range batch {
+		w.fallback.Write(append(e.raw, '\n'))
+	}
+	w.failover.Add(uint64(len(batch)))
+
+	w.nextProbe.Store(time.Now().Add(w.probeInterval()).UnixNano())
+
+	if !w.down.Swap(true) {
+		w.reportError("loki.failover", fmt.Errorf("push failed, switching to fallback: %w", err))
+	}
+}
+
+/**
+ * spill appends one line to the spill log, dropping it if the log is full.
+ */
+func (w *Writer) spill(tenant string, line []byte) {
+	ok, err := w.spool.append(tenant, line)
+	if err != nil {
+		w.dropped.Add(1)
+		w.reportError("loki.spill", fmt.Errorf("failed to write spill file: %w", err))
+		return
+	}
+	if !ok {
+		w.dropped.Add(1)
+		w.reportError("loki.spill", fmt.Errorf("spill file full, dropping entry"))
+		return
+	}
+	w.spilled.Add(1)
+}
+
+func (w *Writer) spillBatch(batch []entry) {
+	for _, e := range batch {
+		w.spill(e.tenant, e.raw)
+	}
+}
+
+/**
+ * replay pushes spilled entries in order, advancing the spill offset after
+ * each accepted batch. A failed batch stays on disk and is retried once the
+ * probe interval passed; force ignores the interval (Flush and Close).
+ *
+ * @return error Error from the failed push, if any
+ */
+func (w *Writer) replay(force bool) error {
+	if w.spool == nil || !w.spool.active() {
+		return nil
+	}
+	if !force && time.Now().UnixNano() < w.nextProbe.Load() {
+		return nil
+	}
+
+	for w.spool.active() {
+		offset := w.spool.position()
+		records, next, err := w.spool.read(w.config.batchSize())
+		if err != nil {
+			w.reportError("loki.spill", fmt.Errorf("failed to read spill file: %w", err))
+			return err
+		}
+		if next == offset {
+			// no complete line left; retrying would spin forever
+			break
+		}
+
+		batch := make([]entry, 0, len(records))
+		for _, r := range records {
+			e := w.splitLine(r.line)
+			if ts, ok := lineTime(r.line); ok {
+				e.ts = ts
+			}
+			e.tenant = r.tenant
+			batch = append(batch, e)
+		}
+
+		if len(batch) > 0 {
+			err := w.push(batch)
+			switch {
+			case rejected(err):
+				// Loki will never take these (e.g. too old); don't block the lines behind them
+				w.failed.Add(uint64(len(batch)))
+				w.reportError("loki.spill", fmt.Errorf("dropping %d spilled entries: %w", len(batch), err))
+			case err != nil:
+				w.nextProbe.Store(time.Now().Add(w.probeInterval()).UnixNano())
+				return err
+			default:
+				w.replayed.Add(uint64(len(batch)))
+			}
+		}
+
+		if err := w.spool.commit(next); err != nil {
+			w.reportError("loki.spill", fmt.Errorf("failed to save spill offset: %w", err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *Writer) probeInterval() time.Duration {
+	if w.config.ProbeIntervalSec > 0 {
+		return time.Duration(w.config.ProbeIntervalSec) * time.Second
+	}
+	return defaultProbeInterval
+}
+
+/**
+ * drain moves everything currently queued into batches and pushes them,
+ * returning the first push error.
+ */
+func (w *Writer) drain(batch *[]entry, flush func() error) error {
+	var firstErr error
+
+	for {
+		select {
+		case e, ok := <-w.queue:
+			if !ok {
+				return firstErr
+			}
+			*batch = append(*batch, e)
+			if len(*batch) < w.config.batchSize() {
+				continue
+			}
+		default:
+			if err := flush(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+			return firstErr
+		}
+
+		if err := flush(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+}
+
+/**
+ * push sends a batch as one request per tenant. The batch counts as failed if
+ * any request failed, so callers retry or spill all of it (at-least-once).
+ */
+func (w *Writer) push(batch []entry) error {
+	if w.config.URL == "" {
+		return fmt.Errorf("loki push URL is empty")
+	}
+
+	groups := make(map[string][]entry)
+	order := make([]string, 0, 1)
+	for _, e := range batch {
+		tenant := e.tenant
+		if tenant == "" {
+			tenant = w.config.TenantID
+		}
+		if _, ok := groups[tenant]; !ok {
+			order = append(order, tenant)
+		}
+		groups[tenant] = append(groups[tenant], e)
+	}
+
+	var firstErr error
+	for _, tenant := range order {
+		if err := w.pushTenant(tenant, groups[tenant]); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+/**
+ * pushTenant sends one request, retrying transient failures (network errors,
+ * 5xx, 429) with exponential backoff. Rejected requests are not retried.
+ */
+func (w *Writer) pushTenant(tenant string, batch []entry) error {
+	body, err := w.encode(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode loki push request: %w", err)
+	}
+
+	backoff := retryBackoff
+	for attempt := 0; ; attempt++ {
+		err = w.send(tenant, body)
+		if err == nil || rejected(err) || attempt >= w.config.maxRetries() {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (w *Writer) send(tenant string, body payload) error {
+	if w.clientErr != nil {
+		return fmt.Errorf("invalid loki client config: %w", w.clientErr)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.config.URL, bytes.NewReader(body.body))
+	if err != nil {
+		return fmt.Errorf("failed to build loki push request: %w", err)
+	}
+	req.Header.Set("Content-Type", body.contentType)
+	if body.contentEncoding != "" {
+		req.Header.Set("Content-Encoding", body.contentEncoding)
+	}
+	w.config.Auth.Apply(req)
+	if tenant != "" {
+		req.Header.Set("X-Scope-OrgID", tenant)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send loki push request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return &statusError{code: resp.StatusCode}
+	}
+
+	return nil
+}
+
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("loki push returned status %d", e.code)
+}
+
+/**
+ * rejected reports whether Loki refused a push for good (4xx other than 429),
+ * as opposed to being unreachable or overloaded.
+ */
+func rejected(err error) bool {
+	var se *statusError
+	if !errors.As(err, &se) {
+		return false
+	}
+	return se.code >= 400 && se.code < 500 && se.code != http.StatusTooManyRequests
+}
+
+func buildPushRequest(batch []entry) map[string]interface{} {
+	streams := make(map[string]map[string]interface{})
+	order := make([]string, 0)
+
+	for _, e := range batch {
+		key := streamKey(e.labels)
+
+		stream, ok := streams[key]
+		if !ok {
+			stream = map[string]interface{}{
+				"stream": e.labels,
+				"values": [][]string{},
+			}
+			streams[key] = stream
+			order = append(order, key)
+		}
+
+		stream["values"] = append(stream["values"].([][]string), []string{
+			strconv.FormatInt(e.ts.UnixNano(), 10),
+			e.line,
+		})
+	}
+
+	result := make([]map[string]interface{}, 0, len(order))
+	for _, key := range order {
+		result = append(result, streams[key])
+	}
+
+	return map[string]interface{}{"streams": result}
+}
+
+/**
+ * extractField resolves a dotted path inside the decoded JSON line and removes
+ * the field so it is not duplicated between the stream labels and the line.
+ */
+func extractField(fields map[string]interface{}, path []string) (string, bool) {
+	if len(path) == 0 {
+		return "", false
+	}
+
+	value, ok := fields[path[0]]
+	if !ok || value == nil {
+		return "", false
+	}
+
+	if len(path) > 1 {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		return extractField(nested, path[1:])
+	}
+
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = v
+	case float64:
+		str = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		str = strconv.FormatBool(v)
+	default:
+		return "", false
+	}
+
+	delete(fields, path[0])
+	return str, true
+}
+
+func labelName(key string) string {
+	return strings.ReplaceAll(key, ".", "_")
+}
+
+func streamKey(labels map[string]string) string {
+	b, _ := json.Marshal(labels)
+	return string(b)
+}
@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"io"
+	"sync/atomic"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+)
+
+var statsLevels = []LogLevel{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelCritical, LevelFatal}
+
+var statsStreams = []string{"access", "error", "loki", "debug"}
+
+/**
+ * Stats is a snapshot of the logging pipeline's health, for surfacing in the
+ * application's own dashboards.
+ *
+ * Entries counts what was logged per level (request and Loki entries, Info,
+ * Error, Warn, Debug). BytesWritten is per stream, counted once regardless of
+ * how many outputs (file, stdout, Loki push) the stream fans out to.
+ * WriteErrors counts file writes that fell back to stdout plus Loki entries
+ * lost in failed pushes. Dropped counts entries discarded by hooks or by a full
+ * Loki queue; Suppressed counts errors collapsed by ErrorRepeatSec.
+ * FailedOver counts Loki entries written to the declared fallback instead.
+ * InternalErrors counts failures reported to the self-log (InternalLog),
+ * including those it rate limited. AlertsIgnored counts errors that matched an
+ * alert ignore rule and were logged without alerting.
+ */
+type Stats struct {
+	Entries        map[LogLevel]uint64 `json:"entries"`
+	BytesWritten   map[string]uint64   `json:"bytes_written"`
+	WriteErrors    uint64              `json:"write_errors"`
+	Dropped        uint64              `json:"dropped"`
+	Suppressed     uint64              `json:"suppressed"`
+	FailedOver     uint64              `json:"failed_over"`
+	InternalErrors uint64              `json:"internal_errors"`
+	AlertsIgnored  uint64              `json:"alerts_ignored"`
+	Alerts         alerts.Stats        `json:"alerts"`
+}
+
+/**
+ * pipelineStats holds the counters shared by a logger, its clones and the
+ * loggers of its Registry. The maps are built once and only read afterwards.
+ */
+type pipelineStats struct {
+	entries       map[LogLevel]*atomic.Uint64
+	bytes         map[string]*atomic.Uint64
+	dropped       atomic.Uint64
+	suppressed    atomic.Uint64
+	alertsIgnored atomic.Uint64
+}
+
+func newPipelineStats() *pipelineStats {
+	s := &pipelineStats{
+		entries: make(map[LogLevel]*atomic.Uint64, len(statsLevels)),
+		bytes:   make(map[string]*atomic.Uint64, len(statsStreams)),
+	}
+	for _, level := range statsLevels {
+		s.entries[level] = &atomic.Uint64{}
+	}
+	for _, stream := range statsStreams {
+		s.bytes[stream] = &atomic.Uint64{}
+	}
+	return s
+}
+
+func (s *pipelineStats) entry(level LogLevel) {
+	if c, ok := s.entries[level]; ok {
+		c.Add(1)
+	}
+}
+
+/**
+ * countingWriter adds the size of every write to a stream's byte counter.
+ */
+type countingWriter struct {
+	w     io.Writer
+	count *atomic.Uint64
+}
+
+func (c countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.count.Add(uint64(n))
+	return n, err
+}
+
+func (s *pipelineStats) counting(stream string, w io.Writer) io.Writer {
+	return countingWriter{w: w, count: s.bytes[stream]}
+}
+
+/**
+ * Stats returns a snapshot of the pipeline counters since New. Loggers from the
+ * same Registry share one set of counters.
+ *
+ * @return Stats Counters per level, per stream, and for errors, drops and alerts
+ */
+func (l *Logger) Stats() Stats {
+	st := Stats{
+		Entries:       make(map[LogLevel]uint64, len(statsLevels)),
+		BytesWritten:  make(map[string]uint64, len(statsStreams)),
+		Dropped:       l.stats.dropped.Load(),
+		Suppressed:    l.stats.suppressed.Load(),
+		AlertsIgnored: l.stats.alertsIgnored.Load(),
+	}
+	if l.selfLog != nil {
+		st.InternalErrors = l.selfLog.total.Load()
+	}
+
+	for level, c := range l.stats.entries {
+		st.Entries[level] = c.Load()
+	}
+	for stream, c := range l.stats.bytes {
+		st.BytesWritten[stream] = c.Load()
+	}
+
+	for _, f := range l.files {
+		st.WriteErrors += f.Stats().Failures
+	}
+	if l.debugStream != nil {
+		if f := l.debugStream.opened(); f != nil {
+			st.WriteErrors += f.Stats().Failures
+		}
+	}
+
+	if l.lokiSink != nil {
+		sink := l.lokiSink.Stats()
+		st.WriteErrors += sink.Failed
+		st.Dropped += sink.Dropped
+		st.FailedOver = sink.FailedOver
+	}
+
+	if l.alertManager != nil {
+		st.Alerts = l.alertManager.Stats()
+	}
+
+	return st
+}
@@ -0,0 +1,135 @@
+package logging
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+type suppressedError struct {
+	message string
+	first   time.Time
+	count   int
+}
+
+type errorSuppressor struct {
+	mu        sync.Mutex
+	window    time.Duration
+	logger    *log.Logger
+	format    Format
+	entries   map[string]*suppressedError
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+/**
+ * newErrorSuppressor collapses repeated errors with the same fingerprint.
+ * The first occurrence in a window is logged normally; later ones are counted
+ * and reported as a single "repeated N times" line once the window expires.
+ *
+ * @param window Suppression window per fingerprint
+ * @param logger Error logger receiving the summary lines
+ * @param format Config.ErrorLogFormat, so summaries match the error lines
+ * @return *errorSuppressor Suppressor with its periodic flusher running
+ */
+func newErrorSuppressor(window time.Duration, logger *log.Logger, format Format) *errorSuppressor {
+	s := &errorSuppressor{
+		window:  window,
+		logger:  logger,
+		format:  format,
+		entries: make(map[string]*suppressedError),
+		stop:    make(chan struct{}),
+	}
+
+	go s.run()
+
+	return s
+}
+
+/**
+ * allow reports whether an error should be written, counting it otherwise.
+ *
+ * @param key Error fingerprint
+ * @param message Error message used in the summary line
+ * @return bool True if the error is the first occurrence in the current window
+ */
+func (s *errorSuppressor) allow(key, message string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	entry, exists := s.entries[key]
+	if exists && now.Sub(entry.first) < s.window {
+		entry.count++
+		return false
+	}
+
+	if exists {
+		s.report(entry, now)
+	}
+
+	s.entries[key] = &suppressedError{message: message, first: now}
+	return true
+}
+
+func (s *errorSuppressor) run() {
+	ticker := time.NewTicker(s.window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flush(false)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *errorSuppressor) flush(all bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	for key, entry := range s.entries {
+		if !all && now.Sub(entry.first) < s.window {
+			continue
+		}
+		s.report(entry, now)
+		delete(s.entries, key)
+	}
+}
+
+func (s *errorSuppressor) report(entry *suppressedError, now time.Time) {
+	if entry.count == 0 {
+		return
+	}
+
+	if s.format == FormatLogfmt || s.format == FormatJSON {
+		printSingleLine(s.logger, s.format, []logfmtField{
+			{"ts", now.Format(time.RFC3339)},
+			{"level", string(LevelError)},
+			{"error", entry.message},
+			{"repeated", entry.count},
+			{"window_sec", int(now.Sub(entry.first).Seconds())},
+		})
+		return
+	}
+
+	s.logger.Printf(
+		"[REPEATED] err=%s repeated %d times in last %d seconds",
+		entry.message,
+		entry.count,
+		int(now.Sub(entry.first).Seconds()),
+	)
+}
+
+func (s *errorSuppressor) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		s.flush(true)
+	})
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+)
+
+// TestAccessLogMinStatus verifies only failures reach the access file while Loki gets everything
+func TestAccessLogMinStatus(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/access-filter.access.log")
+	os.Remove(basicLogDir + "/access-filter.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:        "access-filter-test",
+		LogPath:            basicLogDir,
+		FilePrefix:         "access-filter",
+		EnableFile:         true,
+		EnableRotation:     false,
+		AccessLogMinStatus: 400,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(404) })
+	handler := middleware.HTTPMiddleware(logger)(middleware.HTTPLogger(logger)(mux))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+	logger.Close()
+
+	access, _ := os.ReadFile(basicLogDir + "/access-filter.access.log")
+	if strings.Contains(string(access), "/ok") {
+		t.Error("Successful request should not reach the access log")
+	}
+	if !strings.Contains(string(access), "/missing") {
+		t.Error("Expected failed request in access log")
+	}
+
+	loki, _ := os.ReadFile(basicLogDir + "/access-filter.loki.log")
+	if strings.Count(string(loki), "\n") != 2 {
+		t.Errorf("Expected both requests in Loki log, got:\n%s", loki)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+)
+
+// TestAdminHandler verifies level control, flush, stats and test alerts over HTTP
+func TestAdminHandler(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/admin-test.debug.log")
+
+	alerted := make(chan string, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		raw, _ := json.Marshal(body)
+		alerted <- string(raw)
+	}))
+	defer webhook.Close()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "admin-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "admin-test",
+		EnableFile:     true,
+		EnableRotation: false,
+		Alerts: &logging.AlertsConfig{
+			Enabled:  true,
+			MinLevel: "ERROR",
+			Slack:    &slack.Config{Enabled: true, WebhookURL: webhook.URL},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/logging/", http.StripPrefix("/admin/logging", logging.AdminHandler(logger)))
+
+	do := func(method, target, body string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		var resp map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+
+	if code, resp := do("GET", "/admin/logging/level", ""); code != 200 || resp["level"] != "INFO" {
+		t.Fatalf("Expected INFO level, got %d %v", code, resp)
+	}
+
+	logger.Debug(t.Context(), "ADMIN TEST: before")
+
+	if code, resp := do("PUT", "/admin/logging/level", `{"level":"debug"}`); code != 200 || resp["level"] != "DEBUG" {
+		t.Fatalf("Expected level set to DEBUG, got %d %v", code, resp)
+	}
+	if !logger.Enabled(logging.LevelDebug) {
+		t.Error("Expected DEBUG to be enabled after PUT /level")
+	}
+	if code, _ := do("POST", "/admin/logging/level?level=verbose", ""); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown level, got %d", code)
+	}
+
+	logger.Debug(t.Context(), "ADMIN TEST: after")
+
+	if code, resp := do("POST", "/admin/logging/flush", ""); code != 200 || resp["flushed"] != true {
+		t.Errorf("Expected flush to succeed, got %d %v", code, resp)
+	}
+
+	debug, err := os.ReadFile(basicLogDir + "/admin-test.debug.log")
+	if err != nil {
+		t.Fatalf("Expected debug file once DEBUG was enabled at runtime: %v", err)
+	}
+	if strings.Contains(string(debug), "ADMIN TEST: before") || !strings.Contains(string(debug), "ADMIN TEST: after") {
+		t.Errorf("Expected only the post-switch debug line, got %q", debug)
+	}
+
+	code, resp := do("GET", "/admin/logging/stats", "")
+	if code != 200 || resp["service"] != "admin-test" {
+		t.Fatalf("Unexpected stats response: %d %v", code, resp)
+	}
+	writers, _ := resp["writers"].(map[string]interface{})
+	if _, ok := writers[basicLogDir+"/admin-test.debug"]; !ok || len(writers) != 4 {
+		t.Errorf("Expected stats for 4 streams including debug, got %v", writers)
+	}
+
+	if code, _ := do("POST", "/admin/logging/alert/test", ""); code != http.StatusAccepted {
+		t.Fatalf("Expected 202 for test alert, got %d", code)
+	}
+	select {
+	case body := <-alerted:
+		if !strings.Contains(body, "test alert from admin endpoint") {
+			t.Errorf("Unexpected alert body: %s", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Test alert was not delivered")
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+)
+
+type sendTracker struct {
+	active  atomic.Int32
+	peak    atomic.Int32
+	handled atomic.Int32
+}
+
+type slowAlerter struct {
+	name    string
+	tracker *sendTracker
+}
+
+func (s slowAlerter) Name() string { return s.name }
+
+func (s slowAlerter) Send(ctx context.Context, payload alerts.Payload) error {
+	n := s.tracker.active.Add(1)
+	defer s.tracker.active.Add(-1)
+	for peak := s.tracker.peak.Load(); n > peak && !s.tracker.peak.CompareAndSwap(peak, n); peak = s.tracker.peak.Load() {
+	}
+	time.Sleep(20 * time.Millisecond)
+	s.tracker.handled.Add(1)
+	return nil
+}
+
+// TestAlertSendConcurrency verifies a burst of distinct alerts never exceeds MaxConcurrentSends across providers
+func TestAlertSendConcurrency(t *testing.T) {
+	tracker := &sendTracker{}
+	m := alerts.NewManager(&alerts.Config{Enabled: true, MinLevel: alerts.LevelError, MaxConcurrentSends: 3})
+	m.Register(slowAlerter{"Slow", tracker})
+	m.Register(slowAlerter{"Slower", tracker})
+
+	for i := range 20 {
+		m.Alert(alerts.Payload{ServiceName: "burst", Level: "ERROR", Error: fmt.Sprintf("failure %d", i)})
+	}
+	m.Close(t.Context())
+
+	if handled := tracker.handled.Load(); handled != 40 {
+		t.Errorf("Expected all 40 sends to complete, got %d", handled)
+	}
+	if peak := tracker.peak.Load(); peak > 3 {
+		t.Errorf("Expected at most 3 concurrent sends, got %d", peak)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+)
+
+type legacyCounter struct {
+	sent atomic.Int32
+}
+
+func (c *legacyCounter) Name() string { return "Legacy" }
+
+func (c *legacyCounter) Send(payload alerts.Payload) error {
+	c.sent.Add(1)
+	return nil
+}
+
+// TestAlertShutdownCancelsSends verifies Shutdown cancels a hung webhook once its grace period ends
+func TestAlertShutdownCancelsSends(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	m := alerts.NewManager(&alerts.Config{Enabled: true, MinLevel: alerts.LevelError})
+	m.SetErrorHandler(func(string, error) {})
+	m.Register(slack.New(&slack.Config{Enabled: true, WebhookURL: server.URL, TimeoutSec: 30}))
+	m.Alert(alerts.Payload{ServiceName: "shutdown-test", Level: "ERROR", Error: "hung"})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := m.Close(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected shutdown shortly after grace period, took %v", elapsed)
+	}
+	if s := m.Stats(); s.Failed != 1 {
+		t.Errorf("Expected cancelled send to count as failed, got %+v", s)
+	}
+
+	m.Alert(alerts.Payload{ServiceName: "shutdown-test", Level: "ERROR", Error: "after shutdown"})
+	if s := m.Stats(); s.Sent+s.Failed != 1 {
+		t.Errorf("Expected alerts after shutdown to be dropped, got %+v", s)
+	}
+}
+
+// TestAdaptLegacyAlerter verifies context-free alerters still work through Adapt
+func TestAdaptLegacyAlerter(t *testing.T) {
+	legacy := &legacyCounter{}
+
+	m := alerts.NewManager(&alerts.Config{Enabled: true, MinLevel: alerts.LevelError})
+	m.Register(alerts.Adapt(legacy))
+	m.Alert(alerts.Payload{ServiceName: "legacy-test", Level: "ERROR", Error: "boom"})
+
+	if err := m.Close(t.Context()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if n := legacy.sent.Load(); n != 1 {
+		t.Errorf("Expected legacy alerter to receive 1 alert, got %d", n)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if err := alerts.Adapt(legacy).Send(ctx, alerts.Payload{}); err != context.Canceled {
+		t.Errorf("Expected Canceled for a done context, got %v", err)
+	}
+}
+
+// TestLoggerCloseDrainsAlerts verifies an alert fired just before Close is still delivered
+func TestLoggerCloseDrainsAlerts(t *testing.T) {
+	delivered := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		delivered <- struct{}{}
+	}))
+	defer server.Close()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "drain-test",
+		Alerts: &logging.AlertsConfig{
+			Enabled:         true,
+			MinLevel:        "ERROR",
+			DrainTimeoutSec: 2,
+			Slack:           &slack.Config{Enabled: true, WebhookURL: server.URL},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.ErrorMsg(t.Context(), "exiting", errors.New("fatal config error"))
+	logger.Close()
+
+	select {
+	case <-delivered:
+	default:
+		t.Fatal("Expected the alert to be delivered before Close returned")
+	}
+	if s := logger.Stats().Alerts; s.Sent != 1 {
+		t.Errorf("Expected 1 sent alert, got %+v", s)
+	}
+}
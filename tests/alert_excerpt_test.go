@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/email"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+)
+
+// TestAlertLogExcerpt verifies alerts carry the request's error-log block in Slack and as an email attachment
+func TestAlertLogExcerpt(t *testing.T) {
+	messages := make(chan map[string]any, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]any
+		json.NewDecoder(r.Body).Decode(&msg)
+		messages <- msg
+	}))
+	defer webhook.Close()
+	host, port, mails := smtpServer(t, nil)
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "orders-api",
+		Alerts: &logging.AlertsConfig{
+			Enabled:  true,
+			MinLevel: "ERROR",
+			Excerpt:  &logging.ExcerptConfig{Lines: 50, Request: true},
+			Slack:    &slack.Config{Enabled: true, WebhookURL: webhook.URL},
+			Email: &email.Config{
+				Enabled:  true,
+				SMTPHost: host,
+				SMTPPort: port,
+				From:     "alerts@example.com",
+				To:       []string{"oncall@example.com"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	other := logging.WithMeta(t.Context(), logging.Meta{RequestID: "req-other", Method: "GET", Path: "/health"})
+	logger.Error(other, errors.New("unrelated failure"))
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "req-excerpt", Method: "POST", Path: "/orders"})
+	logger.ErrorMsg(ctx, "failed to reserve stock", errors.New("inventory timeout"))
+	logger.Close()
+
+	excerpt := slackFields(<-messages)["Log Excerpt"]
+	if !strings.Contains(excerpt, "REQ    : req-excerpt") || !strings.Contains(excerpt, "inventory timeout") {
+		t.Errorf("Expected the request's error block in Slack, got %q", excerpt)
+	}
+	if strings.Contains(excerpt, "unrelated failure") {
+		t.Errorf("Expected other requests to be left out, got %q", excerpt)
+	}
+
+	m := <-mails
+	msg, _ := mail.ReadMessage(strings.NewReader(m.Data))
+	mediaType, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if mediaType != "multipart/mixed" {
+		t.Fatalf("Expected multipart/mixed email, got %q", mediaType)
+	}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var attached string
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		if part.FileName() == "log-excerpt.txt" {
+			data, _ := io.ReadAll(part)
+			decoded, _ := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(data)), ""))
+			attached = string(decoded)
+		}
+	}
+	if !strings.Contains(attached, "inventory timeout") {
+		t.Errorf("Expected excerpt attachment, got %q", attached)
+	}
+}
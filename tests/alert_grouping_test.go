@@ -0,0 +1,84 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+)
+
+// TestNormalizePath verifies identifier segments collapse into :id
+func TestNormalizePath(t *testing.T) {
+	cases := map[string]string{
+		"/orders/123":         "/orders/:id",
+		"/orders/456/items/7": "/orders/:id/items/:id",
+		"/users/3f2b8c1e-9a4d-4e6f-8b2a-1c5d7e9f0a3b": "/users/:id",
+		"/docs/507f1f77bcf86cd799439011":              "/docs/:id",
+		"/events/01HZX3K9QW8E7R6T5Y4U3I2O1P":          "/events/:id",
+		"/api/v2/health":                              "/api/v2/health",
+		"/blog/getting-started-with-logging":          "/blog/getting-started-with-logging",
+	}
+	for in, want := range cases {
+		if got := alerts.NormalizePath(in); got != want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestAlertRateLimitGroupsPaths verifies different IDs on one endpoint share a rate limit
+func TestAlertRateLimitGroupsPaths(t *testing.T) {
+	legacy := &legacyCounter{}
+	m := alerts.NewManager(&alerts.Config{Enabled: true, MinLevel: alerts.LevelError})
+	m.Register(alerts.Adapt(legacy))
+
+	for _, path := range []string{"/orders/123", "/orders/456", "/orders/789"} {
+		m.Alert(alerts.Payload{ServiceName: "grouping-test", Level: "ERROR", Error: "db timeout", Method: "GET", Path: path})
+	}
+	m.Close(t.Context())
+
+	if n := legacy.sent.Load(); n != 1 {
+		t.Errorf("Expected 1 alert for one endpoint, got %d", n)
+	}
+	if got := m.Stats().RateLimited; got != 2 {
+		t.Errorf("Expected 2 rate-limited alerts, got %d", got)
+	}
+}
+
+// TestAlertCustomPathNormalizer verifies a custom normalizer replaces the default
+func TestAlertCustomPathNormalizer(t *testing.T) {
+	legacy := &legacyCounter{}
+	m := alerts.NewManager(&alerts.Config{
+		Enabled:        true,
+		MinLevel:       alerts.LevelError,
+		PathNormalizer: func(path string) string { return path },
+	})
+	m.Register(alerts.Adapt(legacy))
+
+	m.Alert(alerts.Payload{ServiceName: "grouping-test", Level: "ERROR", Error: "db timeout", Method: "GET", Path: "/orders/123"})
+	m.Alert(alerts.Payload{ServiceName: "grouping-test", Level: "ERROR", Error: "db timeout", Method: "GET", Path: "/orders/456"})
+	m.Close(t.Context())
+
+	if n := legacy.sent.Load(); n != 2 {
+		t.Errorf("Expected identity normalizer to keep paths apart, got %d alerts", n)
+	}
+}
+
+// TestAlertManagerCloseStopsCleanup verifies the background cleanup goroutine exits on Close
+func TestAlertManagerCloseStopsCleanup(t *testing.T) {
+	before := runtime.NumGoroutine()
+	m := alerts.NewManager(&alerts.Config{Enabled: true, MinLevel: alerts.LevelError, RateLimitSec: 1})
+	for range 2 {
+		if err := m.Close(t.Context()); err != nil {
+			t.Errorf("Expected repeated Close to succeed, got %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("Expected cleanup goroutine to stop, %d goroutines left (was %d)", n, before)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+)
+
+var errOutOfStock = errors.New("out of stock")
+
+// TestAlertIgnoreRules verifies benign and user-ignored errors are logged without alerting
+func TestAlertIgnoreRules(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "ignore-test",
+		Alerts: &logging.AlertsConfig{
+			Enabled:        true,
+			MinLevel:       "ERROR",
+			IgnorePatterns: []string{`^cache miss`},
+			IgnoreErrors:   []error{errOutOfStock},
+			Slack:          &slack.Config{Enabled: true, WebhookURL: webhook.URL},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "ignore", Method: "GET", Path: "/orders"})
+	logger.ErrorLoki(ctx, logging.LevelError, fmt.Errorf("query aborted: %w", context.Canceled))
+	logger.ErrorLoki(ctx, logging.LevelError, fmt.Errorf("write response: %w", syscall.EPIPE))
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("write tcp 10.0.0.1:443: connection reset by peer"))
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("cache miss for sku-9"))
+	logger.ErrorLoki(ctx, logging.LevelError, fmt.Errorf("reserve item: %w", errOutOfStock))
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("database unavailable"))
+	logger.Close()
+
+	if got := logger.Stats().AlertsIgnored; got != 5 {
+		t.Errorf("Expected 5 ignored alerts, got %d", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 1 || !strings.Contains(bodies[0], "database unavailable") {
+		t.Errorf("Expected only the database alert, got %q", bodies)
+	}
+}
+
+// TestAlertIgnoreInvalidPattern verifies a broken regex fails New
+func TestAlertIgnoreInvalidPattern(t *testing.T) {
+	_, err := logging.New(&logging.Config{
+		ServiceName: "ignore-test",
+		Alerts:      &logging.AlertsConfig{Enabled: true, IgnorePatterns: []string{"(unclosed"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "alert ignore pattern") {
+		t.Errorf("Expected ignore pattern error, got %v", err)
+	}
+}
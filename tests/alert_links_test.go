@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/discord"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+)
+
+// TestExpandLink verifies placeholder substitution and skipping links that need a missing request ID
+func TestExpandLink(t *testing.T) {
+	payload := alerts.Payload{
+		ServiceName: "orders api",
+		Environment: "prod",
+		RequestID:   "req-1",
+		Timestamp:   time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+	}
+
+	got := alerts.ExpandLink("https://kibana/app?q=service:{service}+env:{environment}+rid:{request_id}&at={timestamp}&from={from_ms}&to={to_ms}", payload)
+	want := "https://kibana/app?q=service:orders+api+env:prod+rid:req-1&at=2026-10-16T12%3A00%3A00Z&from=1792151700000&to=1792152300000"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	payload.RequestID = ""
+	if got := alerts.ExpandLink("https://sentry/issues?query=request_id:{request_id}", payload); got != "" {
+		t.Errorf("Expected no link without a request ID, got %q", got)
+	}
+	if got := alerts.ExpandLink("https://sentry/issues?project={service}", payload); got != "https://sentry/issues?project=orders+api" {
+		t.Errorf("Expected service-only link, got %q", got)
+	}
+}
+
+// TestAlertDeepLinks verifies configured links reach Discord and Slack
+func TestAlertDeepLinks(t *testing.T) {
+	messages := make(chan map[string]any, 2)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]any
+		json.NewDecoder(r.Body).Decode(&msg)
+		msg["_path"] = r.URL.Path
+		messages <- msg
+	}))
+	defer webhook.Close()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "orders-api",
+		Alerts: &logging.AlertsConfig{
+			Enabled:  true,
+			MinLevel: "ERROR",
+			Links: []alerts.LinkTemplate{
+				{Name: "Sentry", URL: "https://sentry.example.com/issues?query=request_id:{request_id}"},
+			},
+			Discord: &discord.Config{Enabled: true, WebhookURL: webhook.URL + "/discord"},
+			Slack:   &slack.Config{Enabled: true, WebhookURL: webhook.URL + "/slack"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "links-1", Method: "GET", Path: "/orders"})
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("payment gateway timeout"))
+	logger.Close()
+
+	link := "https://sentry.example.com/issues?query=request_id:links-1"
+	for range 2 {
+		msg := <-messages
+		switch msg["_path"] {
+		case "/slack":
+			if got := slackButtons(msg)["View in Sentry"]; got != link {
+				t.Errorf("Expected Slack button %q, got %q", link, got)
+			}
+		case "/discord":
+			embed := msg["embeds"].([]any)[0].(map[string]any)
+			var links string
+			for _, f := range embed["fields"].([]any) {
+				if field := f.(map[string]any); field["name"] == "Links" {
+					links = field["value"].(string)
+				}
+			}
+			if !strings.Contains(links, "[Sentry]("+link+")") {
+				t.Errorf("Expected Discord links field, got %q", links)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+)
+
+// slackFields returns the "*Title*\nvalue" texts of a Slack Block Kit message keyed by title
+func slackFields(msg map[string]any) map[string]string {
+	attachment := msg["attachments"].([]any)[0].(map[string]any)
+	fields := map[string]string{}
+	add := func(text any) {
+		if s, ok := text.(string); ok && strings.HasPrefix(s, "*") {
+			if title, value, ok := strings.Cut(strings.TrimPrefix(s, "*"), "*\n"); ok {
+				fields[title] = value
+			}
+		}
+	}
+	for _, b := range attachment["blocks"].([]any) {
+		block := b.(map[string]any)
+		if block["type"] != "section" {
+			continue
+		}
+		if text, ok := block["text"].(map[string]any); ok {
+			add(text["text"])
+		}
+		if items, ok := block["fields"].([]any); ok {
+			for _, item := range items {
+				add(item.(map[string]any)["text"])
+			}
+		}
+	}
+	return fields
+}
+
+// TestAlertEnvironmentMetadata verifies alerts say which environment, version and host failed
+func TestAlertEnvironmentMetadata(t *testing.T) {
+	messages := make(chan map[string]any, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]any
+		json.NewDecoder(r.Body).Decode(&msg)
+		messages <- msg
+	}))
+	defer webhook.Close()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "orders-api",
+		ServiceVersion: "1.4.2",
+		Environment:    "staging",
+		Alerts: &logging.AlertsConfig{
+			Enabled:  true,
+			MinLevel: "ERROR",
+			Slack:    &slack.Config{Enabled: true, WebhookURL: webhook.URL},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "metadata", Method: "POST", Path: "/orders"})
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("inventory service down"))
+	logger.Close()
+
+	fields := slackFields(<-messages)
+
+	hostname, _ := os.Hostname()
+	want := map[string]string{
+		"Service":     "orders-api 1.4.2",
+		"Environment": "staging",
+		"Host":        hostname,
+	}
+	for title, value := range want {
+		if fields[title] != value {
+			t.Errorf("Expected %s %q, got %q", title, value, fields[title])
+		}
+	}
+}
+
+// TestAlertCustomFields verifies logger and context fields reach the provider, context winning on conflicts
+func TestAlertCustomFields(t *testing.T) {
+	messages := make(chan map[string]any, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]any
+		json.NewDecoder(r.Body).Decode(&msg)
+		messages <- msg
+	}))
+	defer webhook.Close()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "orders-api",
+		Alerts: &logging.AlertsConfig{
+			Enabled:  true,
+			MinLevel: "ERROR",
+			Slack:    &slack.Config{Enabled: true, WebhookURL: webhook.URL},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	worker := logger.WithAlertFields(map[string]string{"region": "eu-west-1", "tenant": "default"})
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "fields", Method: "POST", Path: "/orders"})
+	ctx = logging.WithAlertField(ctx, "order_id", "A-1001")
+	ctx = logging.WithAlertField(ctx, "tenant", "acme")
+	worker.ErrorLoki(ctx, logging.LevelError, errors.New("payment declined"))
+	logger.Close()
+
+	fields := slackFields(<-messages)
+	want := map[string]string{"region": "eu-west-1", "tenant": "acme", "order_id": "A-1001"}
+	for title, value := range want {
+		if fields[title] != value {
+			t.Errorf("Expected %s %q, got %q", title, value, fields[title])
+		}
+	}
+	if _, ok := fields["Stack Trace"]; !ok {
+		t.Error("Expected stack trace field after the custom fields")
+	}
+}
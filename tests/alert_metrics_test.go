@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+	"github.com/ahmadsaubani/go-logging-lib/metrics"
+)
+
+type brokenAlerter struct{}
+
+func (brokenAlerter) Name() string { return "Broken" }
+
+func (brokenAlerter) Send(ctx context.Context, payload alerts.Payload) error {
+	return errors.New("webhook gone")
+}
+
+// TestAlertProviderMetrics verifies per-provider delivery counts in Stats and the Prometheus output
+func TestAlertProviderMetrics(t *testing.T) {
+	m := alerts.NewManager(&alerts.Config{Enabled: true, MinLevel: alerts.LevelError})
+	m.Register(&recordingAlerter{})
+	m.Register(brokenAlerter{})
+	m.SetErrorHandler(func(string, error) {})
+
+	payload := alerts.Payload{ServiceName: "metrics-test", Level: "ERROR", Error: "db timeout", Method: "GET", Path: "/orders"}
+	m.Alert(payload)
+	m.Alert(payload)
+	m.Close(t.Context())
+
+	stats := m.Stats()
+	expected := map[string]alerts.ProviderStats{
+		"recorder": {Sent: 1, RateLimited: 1},
+		"broken":   {Failed: 1, RateLimited: 1},
+	}
+	for name, want := range expected {
+		if got := stats.Providers[name]; got != want {
+			t.Errorf("Expected %s stats %+v, got %+v", name, want, got)
+		}
+	}
+
+	recorder := metrics.NewRecorder()
+	recorder.Register(metrics.NewAlertCollector(m.Stats))
+	rec := httptest.NewRecorder()
+	recorder.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, line := range []string{
+		"# TYPE alerts_sent_total counter",
+		`alerts_sent_total{provider="recorder"} 1`,
+		`alerts_failed_total{provider="broken"} 1`,
+		`alerts_rate_limited_total{provider="broken"} 1`,
+		"alerts_grouped_total 0",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("Expected %q in metrics output:\n%s", line, body)
+		}
+	}
+}
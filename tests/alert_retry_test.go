@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+)
+
+func waitAlertStats(m *alerts.Manager) alerts.Stats {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if s := m.Stats(); s.Sent+s.Failed > 0 {
+			return s
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return m.Stats()
+}
+
+// TestAlertRetriesFlakyWebhook verifies a webhook failing twice is retried until it succeeds
+func TestAlertRetriesFlakyWebhook(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	m := alerts.NewManager(&alerts.Config{Enabled: true, MinLevel: alerts.LevelError})
+	m.Register(slack.New(&slack.Config{Enabled: true, WebhookURL: server.URL, Retries: 2}))
+	m.Alert(alerts.Payload{ServiceName: "retry-test", Level: "ERROR", Error: "flaky"})
+
+	if s := waitAlertStats(m); s.Sent != 1 || s.Failed != 0 {
+		t.Errorf("Expected 1 sent after retries, got %+v", s)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("Expected 3 attempts, got %d", n)
+	}
+}
+
+// TestAlertTimeout verifies a slow webhook is abandoned after timeout_sec
+func TestAlertTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	a := slack.New(&slack.Config{Enabled: true, WebhookURL: server.URL, TimeoutSec: 1})
+
+	start := time.Now()
+	err := a.Send(t.Context(), alerts.Payload{ServiceName: "timeout-test", Level: "ERROR", Error: "slow"})
+	if err == nil || !strings.Contains(err.Error(), "Timeout") {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected send to give up after ~1s, took %v", elapsed)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+)
+
+type recordingAlerter struct {
+	mu       sync.Mutex
+	payloads []alerts.Payload
+}
+
+func (r *recordingAlerter) Name() string { return "Recorder" }
+
+func (r *recordingAlerter) Send(ctx context.Context, payload alerts.Payload) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.payloads = append(r.payloads, payload)
+	return nil
+}
+
+func (r *recordingAlerter) received() []alerts.Payload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]alerts.Payload{}, r.payloads...)
+}
+
+func repeatedField(p alerts.Payload) string {
+	for _, f := range p.Fields {
+		if f.Key == "Repeated" {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// TestFirstOccurrenceSummary verifies repeats are folded into one summary sent on shutdown
+func TestFirstOccurrenceSummary(t *testing.T) {
+	rec := &recordingAlerter{}
+	m := alerts.NewManager(&alerts.Config{Enabled: true, MinLevel: alerts.LevelError, FirstOccurrenceOnly: true})
+	m.Register(rec)
+
+	payload := alerts.Payload{ServiceName: "summary-test", Level: "ERROR", Error: "db timeout", Method: "GET", Path: "/orders/1"}
+	for i := 0; i < 4; i++ {
+		m.Alert(payload)
+	}
+	m.Alert(alerts.Payload{ServiceName: "summary-test", Level: "ERROR", Error: "cache down", Method: "GET", Path: "/orders/1"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rec.received()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := rec.received(); len(got) != 2 || repeatedField(got[0]) != "" || repeatedField(got[1]) != "" {
+		t.Fatalf("Expected 2 immediate first-occurrence alerts, got %+v", got)
+	}
+
+	m.Close(t.Context())
+
+	got := rec.received()
+	if len(got) != 3 {
+		t.Fatalf("Expected 1 summary on shutdown, got %d alerts", len(got))
+	}
+	if got[2].Error != "db timeout" || !strings.HasPrefix(repeatedField(got[2]), "3 more times") {
+		t.Errorf("Unexpected summary: %+v", got[2])
+	}
+	if s := m.Stats(); s.Grouped != 3 || s.RateLimited != 0 {
+		t.Errorf("Expected 3 grouped, got %+v", s)
+	}
+}
+
+// TestFirstOccurrenceForgetsQuietKeys verifies a key alerts immediately again after a quiet interval
+func TestFirstOccurrenceForgetsQuietKeys(t *testing.T) {
+	rec := &recordingAlerter{}
+	m := alerts.NewManager(&alerts.Config{
+		Enabled:             true,
+		MinLevel:            alerts.LevelError,
+		FirstOccurrenceOnly: true,
+		SummaryIntervalSec:  1,
+	})
+	m.Register(rec)
+	defer m.Close(t.Context())
+
+	payload := alerts.Payload{ServiceName: "summary-test", Level: "ERROR", Error: "db timeout", Method: "GET", Path: "/orders"}
+	m.Alert(payload)
+	time.Sleep(2200 * time.Millisecond)
+	m.Alert(payload)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rec.received()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	got := rec.received()
+	if len(got) != 2 || repeatedField(got[1]) != "" {
+		t.Errorf("Expected a fresh alert after the quiet interval, got %+v", got)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+)
+
+// TestBuildInfoInLokiEntries verifies version and build fields on every Loki entry
+func TestBuildInfoInLokiEntries(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/build-info.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "build-test",
+		ServiceVersion: "2.3.0",
+		LogPath:        basicLogDir,
+		FilePrefix:     "build-info",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "req-build"})
+	logger.LogRequest(ctx, 200, 0)
+	logger.Close()
+
+	content, _ := os.ReadFile(basicLogDir + "/build-info.loki.log")
+	var entry struct {
+		Version string         `json:"version"`
+		Build   map[string]any `json:"build"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(content))), &entry); err != nil {
+		t.Fatalf("Failed to parse Loki entry %q: %v", content, err)
+	}
+	if entry.Version != "2.3.0" {
+		t.Errorf("Expected version 2.3.0, got %q", entry.Version)
+	}
+	if entry.Build["go"] != runtime.Version() {
+		t.Errorf("Expected build.go %s, got %v", runtime.Version(), entry.Build)
+	}
+}
+
+// TestServiceLabelRevision verifies alerts show the short VCS revision next to the version
+func TestServiceLabelRevision(t *testing.T) {
+	payload := alerts.Payload{
+		ServiceName:    "orders-api",
+		ServiceVersion: "1.4.2",
+		Build:          alerts.Build{Revision: "a1b2c3d4e5f6a7b8c9d0", Modified: true},
+	}
+	if got := payload.ServiceLabel(); got != "orders-api 1.4.2 (a1b2c3d-dirty)" {
+		t.Errorf("Unexpected service label %q", got)
+	}
+
+	payload.Build = alerts.Build{}
+	if got := payload.ServiceLabel(); got != "orders-api 1.4.2" {
+		t.Errorf("Expected no revision without VCS info, got %q", got)
+	}
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestCaptureToggles verifies caller and stack capture can be switched off
+func TestCaptureToggles(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "capture-1", Method: "GET", Path: "/x"})
+
+	for _, disabled := range []bool{false, true} {
+		prefix := "capture-on"
+		if disabled {
+			prefix = "capture-off"
+		}
+		os.Remove(basicLogDir + "/" + prefix + ".error.log")
+		os.Remove(basicLogDir + "/" + prefix + ".loki.log")
+
+		logger, err := logging.New(&logging.Config{
+			ServiceName:    "capture-test",
+			LogPath:        basicLogDir,
+			FilePrefix:     prefix,
+			EnableFile:     true,
+			EnableRotation: false,
+			DisableCaller:  disabled,
+			DisableStack:   disabled,
+			StackDepth:     2,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		logger.Error(ctx, errors.New("capture failure"))
+		logger.ErrorLoki(ctx, logging.LevelError, errors.New("capture failure"))
+		logger.Close()
+
+		errorLog, _ := os.ReadFile(basicLogDir + "/" + prefix + ".error.log")
+		entry := readSingleLokiEntry(t, basicLogDir+"/"+prefix+".loki.log")
+
+		if disabled {
+			if strings.Contains(string(errorLog), "capture_test.go") {
+				t.Error("Expected no caller in error log when disabled")
+			}
+			if entry.Errors.Source != nil || entry.Errors.Stack != nil {
+				t.Errorf("Expected no source/stack in Loki when disabled, got %+v", entry.Errors)
+			}
+			continue
+		}
+
+		if !strings.Contains(string(errorLog), "FROM   : capture_test.go:") {
+			t.Errorf("Expected caller in error log, got:\n%s", errorLog)
+		}
+		if entry.Errors.Source["file"] != "capture_test.go" {
+			t.Errorf("Expected Loki source capture_test.go, got %v", entry.Errors.Source["file"])
+		}
+		if len(entry.Errors.Stack) != 2 {
+			t.Errorf("Expected stack depth 2, got %d", len(entry.Errors.Stack))
+		}
+	}
+}
+
+func benchmarkErrorLoki(b *testing.B, disabled bool) {
+	logger, _ := logging.New(&logging.Config{
+		ServiceName:   "capture-bench",
+		DisableCaller: disabled,
+		DisableStack:  disabled,
+	})
+	defer logger.Close()
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "bench", Method: "GET", Path: "/bench"})
+	err := errors.New("bench failure")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.ErrorLoki(ctx, logging.LevelError, err)
+	}
+}
+
+func BenchmarkErrorLokiWithCapture(b *testing.B)    { benchmarkErrorLoki(b, false) }
+func BenchmarkErrorLokiWithoutCapture(b *testing.B) { benchmarkErrorLoki(b, true) }
+
+func reportFailure(logger *logging.Logger, ctx context.Context, err error) {
+	logger.ErrorLoki(ctx, logging.LevelError, err)
+}
+
+// TestWithCallerSkip verifies wrapper helpers report their caller as the error source
+func TestWithCallerSkip(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/caller-skip.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "caller-skip-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "caller-skip",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "skip-1"})
+	_, _, line, _ := runtime.Caller(0)
+	reportFailure(logger.WithCallerSkip(1), ctx, errors.New("wrapped failure"))
+	logger.Close()
+
+	entry := readSingleLokiEntry(t, basicLogDir+"/caller-skip.loki.log")
+	if got := entry.Errors.Source["line"]; got != float64(line+1) {
+		t.Errorf("Expected source line %d (call site), got %v", line+1, got)
+	}
+	if got, _ := entry.Errors.Source["function"].(string); !strings.HasSuffix(got, ".TestWithCallerSkip") {
+		t.Errorf("Expected source function of the call site, got %v", got)
+	}
+}
+
+// TestFullPaths verifies source files are reported relative to the source root
+func TestFullPaths(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/full-paths.error.log")
+	os.Remove(basicLogDir + "/full-paths.loki.log")
+
+	wd, _ := os.Getwd()
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "full-paths-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "full-paths",
+		EnableFile:     true,
+		EnableRotation: false,
+		FullPaths:      true,
+		SourceRoot:     filepath.Dir(wd),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "paths-1"})
+	logger.Error(ctx, errors.New("path failure"))
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("path failure"))
+	logger.Close()
+
+	errorLog, _ := os.ReadFile(basicLogDir + "/full-paths.error.log")
+	if !strings.Contains(string(errorLog), "FROM   : tests/capture_test.go:") {
+		t.Errorf("Expected root-relative path in error log, got:\n%s", errorLog)
+	}
+
+	entry := readSingleLokiEntry(t, basicLogDir+"/full-paths.loki.log")
+	if entry.Errors.Source["file"] != "tests/capture_test.go" {
+		t.Errorf("Expected tests/capture_test.go in Loki source, got %v", entry.Errors.Source["file"])
+	}
+	if len(entry.Errors.Stack) == 0 || !strings.HasPrefix(entry.Errors.Stack[0], "tests/capture_test.go:") {
+		t.Errorf("Expected root-relative stack frames, got %v", entry.Errors.Stack)
+	}
+}
+
+func nestedFailure(logger *logging.Logger, ctx context.Context, depth int) {
+	if depth > 0 {
+		nestedFailure(logger, ctx, depth-1)
+		return
+	}
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("deep failure"))
+}
+
+// TestStackDepthAndSkip verifies configured and per-call stack depth and skip
+func TestStackDepthAndSkip(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/stack-depth.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "stack-depth-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "stack-depth",
+		EnableFile:     true,
+		EnableRotation: false,
+		StackDepth:     3,
+		StackSkip:      1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "stack-1"})
+	nestedFailure(logger, ctx, 10)
+	nestedFailure(logger.WithStackDepth(8), ctx, 10)
+	logger.Close()
+
+	content, _ := os.ReadFile(basicLogDir + "/stack-depth.loki.log")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 Loki entries, got %d", len(lines))
+	}
+
+	for i, want := range []int{3, 8} {
+		var entry LokiLogEntry
+		json.Unmarshal([]byte(lines[i]), &entry)
+		if len(entry.Errors.Stack) != want {
+			t.Errorf("Entry %d: expected %d frames, got %d", i, want, len(entry.Errors.Stack))
+		}
+		if entry.Errors.Source["file"] != "capture_test.go" {
+			t.Errorf("Entry %d: StackSkip must not move the source, got %v", i, entry.Errors.Source["file"])
+		}
+	}
+}
+
+// TestStackMinLevel verifies stacks are only captured at or above the configured level
+func TestStackMinLevel(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "stack-level-1"})
+
+	for _, minLevel := range []logging.LogLevel{"", logging.LevelWarn} {
+		prefix := "stack-level-default"
+		if minLevel != "" {
+			prefix = "stack-level-warn"
+		}
+		os.Remove(basicLogDir + "/" + prefix + ".loki.log")
+
+		logger, err := logging.New(&logging.Config{
+			ServiceName:    "stack-level-test",
+			LogPath:        basicLogDir,
+			FilePrefix:     prefix,
+			EnableFile:     true,
+			EnableRotation: false,
+			StackMinLevel:  minLevel,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		logger.ErrorLoki(ctx, logging.LevelWarn, errors.New("degraded"))
+		logger.Close()
+
+		entry := readSingleLokiEntry(t, basicLogDir+"/"+prefix+".loki.log")
+		if entry.Errors.Source == nil {
+			t.Errorf("%s: expected source regardless of level", prefix)
+		}
+		if hasStack := len(entry.Errors.Stack) > 0; hasStack != (minLevel != "") {
+			t.Errorf("%s: unexpected stack %v", prefix, entry.Errors.Stack)
+		}
+	}
+}
+
+// TestLokiIncludeStack verifies LokiIncludeStack=false skips the Loki stack while the error log keeps it
+func TestLokiIncludeStack(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/loki-stack.error.log")
+	os.Remove(basicLogDir + "/loki-stack.loki.log")
+
+	includeStack := false
+	logger, err := logging.New(&logging.Config{
+		ServiceName:      "loki-stack-test",
+		LogPath:          basicLogDir,
+		FilePrefix:       "loki-stack",
+		EnableFile:       true,
+		EnableRotation:   false,
+		LokiIncludeStack: &includeStack,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "loki-stack-1"})
+	logger.Error(ctx, errors.New("stack failure"))
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("stack failure"))
+	logger.Close()
+
+	errorLog, _ := os.ReadFile(basicLogDir + "/loki-stack.error.log")
+	if !strings.Contains(string(errorLog), "STACK") {
+		t.Errorf("Expected stack in error log, got:\n%s", errorLog)
+	}
+
+	entry := readSingleLokiEntry(t, basicLogDir+"/loki-stack.loki.log")
+	if entry.Errors.Stack != nil {
+		t.Errorf("Expected no stack in Loki entry, got %v", entry.Errors.Stack)
+	}
+	if entry.Errors.Source["file"] != "capture_test.go" {
+		t.Errorf("Expected Loki source to be kept, got %v", entry.Errors.Source)
+	}
+}
+
+// TestRequestLogSource verifies request log calls report their call site as the Loki error source
+func TestRequestLogSource(t *testing.T) {
+	dir := t.TempDir()
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "source-1", Method: "GET", Path: "/x"})
+
+	calls := map[string]func(l *logging.Logger) int{
+		"LogRequestWithError": func(l *logging.Logger) int {
+			_, _, line, _ := runtime.Caller(0)
+			l.LogRequestWithError(ctx, 500, time.Millisecond, errors.New("request failure"))
+			return line + 1
+		},
+		"LogRequestWithLevel": func(l *logging.Logger) int {
+			_, _, line, _ := runtime.Caller(0)
+			l.LogRequestWithLevel(ctx, logging.LevelError, 500, time.Millisecond, errors.New("request failure"))
+			return line + 1
+		},
+	}
+
+	for name, call := range calls {
+		logger, err := logging.New(&logging.Config{
+			ServiceName:    "request-source-test",
+			LogPath:        dir,
+			FilePrefix:     name,
+			EnableFile:     true,
+			EnableRotation: false,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		line := call(logger)
+		logger.Close()
+
+		entry := readSingleLokiEntry(t, dir+"/"+name+".loki.log")
+		if entry.Errors.Source["file"] != "capture_test.go" || entry.Errors.Source["line"] != float64(line) {
+			t.Errorf("%s: expected source capture_test.go:%d, got %v:%v", name, line, entry.Errors.Source["file"], entry.Errors.Source["line"])
+		}
+	}
+}
+
+// TestLokiSource verifies Logger.Loki reports its call site as the error source
+func TestLokiSource(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "loki-source-test",
+		LogPath:        dir,
+		FilePrefix:     "loki-source",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "loki-source-1"})
+	_, _, line, _ := runtime.Caller(0)
+	logger.Loki(ctx, logging.LevelError, 500, time.Millisecond, errors.New("loki failure"))
+	logger.Close()
+
+	entry := readSingleLokiEntry(t, dir+"/loki-source.loki.log")
+	if entry.Errors.Source["file"] != "capture_test.go" || entry.Errors.Source["line"] != float64(line+1) {
+		t.Errorf("Expected source capture_test.go:%d, got %v:%v", line+1, entry.Errors.Source["file"], entry.Errors.Source["line"])
+	}
+}
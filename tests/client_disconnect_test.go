@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TestClientDisconnectLogged verifies abandoned requests are logged as 499 WARN with client_disconnected
+func TestClientDisconnectLogged(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/disconnect.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "disconnect-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "disconnect",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /report", func(w http.ResponseWriter, r *http.Request) {
+		abandon(r)
+		w.Write([]byte("late"))
+	})
+	handler := middleware.HTTPMiddleware(logger)(middleware.HTTPLogger(logger)(mux))
+
+	engine := gin.New()
+	engine.Use(middleware.GinMiddleware(logger), middleware.GinLogger(logger))
+	engine.GET("/export", func(c *gin.Context) {
+		abandon(c.Request)
+		c.String(200, "late")
+	})
+
+	for _, serve := range []struct {
+		h    http.Handler
+		path string
+	}{{handler, "/report"}, {engine, "/export"}} {
+		ctx, cancel := context.WithCancel(t.Context())
+		req := httptest.NewRequest("GET", serve.path, nil).WithContext(context.WithValue(ctx, cancelKey{}, cancel))
+		serve.h.ServeHTTP(httptest.NewRecorder(), req)
+		cancel()
+	}
+	logger.Close()
+
+	content, _ := os.ReadFile(basicLogDir + "/disconnect.loki.log")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 Loki entries, got %d: %s", len(lines), content)
+	}
+	for _, line := range lines {
+		var entry struct {
+			Level        string `json:"level"`
+			StatusCode   int    `json:"status_code"`
+			Disconnected bool   `json:"client_disconnected"`
+		}
+		json.Unmarshal([]byte(line), &entry)
+		if entry.StatusCode != 499 || entry.Level != "WARN" || !entry.Disconnected {
+			t.Errorf("Expected 499 WARN with client_disconnected, got %s", line)
+		}
+	}
+}
+
+type cancelKey struct{}
+
+// abandon simulates the client closing the connection while the handler runs
+func abandon(r *http.Request) {
+	r.Context().Value(cancelKey{}).(context.CancelFunc)()
+}
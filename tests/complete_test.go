@@ -18,20 +18,23 @@ import (
 
 // LokiLogEntry represents the unified Loki JSON format
 type LokiLogEntry struct {
-	TS         string            `json:"ts"`
-	Level      string            `json:"level"`
-	Service    string            `json:"service"`
-	RequestID  string            `json:"request_id"`
-	StatusCode int               `json:"status_code"`
-	LatencyMS  int64             `json:"latency_ms"`
-	HTTP       map[string]string `json:"http"`
-	Errors     *ErrorDetail      `json:"errors"`
+	TS            string            `json:"ts"`
+	Level         string            `json:"level"`
+	Service       string            `json:"service"`
+	RequestID     string            `json:"request_id"`
+	CorrelationID string            `json:"correlation_id"`
+	StatusCode    int               `json:"status_code"`
+	LatencyMS     int64             `json:"latency_ms"`
+	LatencyUS     int64             `json:"latency_us"`
+	HTTP          map[string]string `json:"http"`
+	Errors        *ErrorDetail      `json:"errors"`
 }
 
 type ErrorDetail struct {
-	Error  string                 `json:"error"`
-	Source map[string]interface{} `json:"source"`
-	Stack  []string               `json:"stack"`
+	Error    string                 `json:"error"`
+	Messages []string               `json:"messages"`
+	Source   map[string]interface{} `json:"source"`
+	Stack    []string               `json:"stack"`
 }
 
 func TestBasicAndGinLogging(t *testing.T) {
@@ -365,6 +368,11 @@ func TestBasicAndGinLogging(t *testing.T) {
 				if entry.Service != "gin-example" {
 					t.Errorf("Expected service 'gin-example', got '%s'", entry.Service)
 				}
+
+				// Verify route template is recorded for matched routes
+				if entry.HTTP["path"] == "/success" && entry.HTTP["route"] != "/success" {
+					t.Errorf("Expected route '/success', got '%s'", entry.HTTP["route"])
+				}
 				
 				// Verify errors field based on status code
 				if entry.StatusCode >= 400 {
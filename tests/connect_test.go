@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// TestConnectInterceptor verifies procedure names and RPC codes reach the Loki entry
+func TestConnectInterceptor(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/connect-test.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "connect-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "connect-test",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	const procedure = "/users.v1.UserService/GetUser"
+	handler := connect.NewUnaryHandler(
+		procedure,
+		func(ctx context.Context, req *connect.Request[wrapperspb.StringValue]) (*connect.Response[wrapperspb.StringValue], error) {
+			if _, ok := logging.FromContext(ctx); !ok {
+				t.Error("Expected Meta in handler context")
+			}
+			return nil, connect.NewError(connect.CodeNotFound, errors.New("user missing"))
+		},
+		connect.WithInterceptors(middleware.ConnectInterceptor(logger)),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle(procedure, handler)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := connect.NewClient[wrapperspb.StringValue, wrapperspb.StringValue](srv.Client(), srv.URL+procedure)
+	_, err = client.CallUnary(context.Background(), connect.NewRequest(wrapperspb.String("42")))
+	if connect.CodeOf(err) != connect.CodeNotFound {
+		t.Fatalf("Expected not_found from server, got %v", err)
+	}
+	logger.Close()
+
+	entry := readSingleLokiEntry(t, basicLogDir+"/connect-test.loki.log")
+	if entry.HTTP["route"] != procedure {
+		t.Errorf("Expected route %s, got %q", procedure, entry.HTTP["route"])
+	}
+	if entry.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", entry.StatusCode)
+	}
+	if entry.Level != "ERROR" {
+		t.Errorf("Expected ERROR level, got %s", entry.Level)
+	}
+	if entry.HTTP["ip"] == "" || strings.Contains(entry.HTTP["ip"], ":") {
+		t.Errorf("Expected peer host without port, got %q", entry.HTTP["ip"])
+	}
+	if entry.Errors == nil || !strings.Contains(entry.Errors.Error, "not_found") {
+		t.Errorf("Expected not_found code in error, got %+v", entry.Errors)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+)
+
+// TestConnInfo verifies protocol and TLS details are recorded only when enabled
+func TestConnInfo(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+
+	for _, enabled := range []bool{true, false} {
+		prefix := "conninfo-off"
+		if enabled {
+			prefix = "conninfo-on"
+		}
+		os.Remove(basicLogDir + "/" + prefix + ".loki.log")
+
+		logger, err := logging.New(&logging.Config{
+			ServiceName:    "conninfo-test",
+			LogPath:        basicLogDir,
+			FilePrefix:     prefix,
+			EnableFile:     true,
+			EnableRotation: false,
+			EnableConnInfo: enabled,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/secure", func(w http.ResponseWriter, r *http.Request) {})
+
+		srv := httptest.NewTLSServer(middleware.HTTPMiddleware(logger)(middleware.HTTPLogger(logger)(mux)))
+		resp, err := srv.Client().Get(srv.URL + "/secure")
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		srv.Close()
+		logger.Close()
+
+		entry := readSingleLokiEntry(t, basicLogDir+"/"+prefix+".loki.log")
+		if !enabled {
+			if _, ok := entry.HTTP["tls_version"]; ok {
+				t.Error("Expected no TLS details when disabled")
+			}
+			continue
+		}
+		if entry.HTTP["protocol"] != "HTTP/1.1" {
+			t.Errorf("Expected protocol HTTP/1.1, got %q", entry.HTTP["protocol"])
+		}
+		if entry.HTTP["tls_version"] != "TLS 1.3" {
+			t.Errorf("Expected TLS 1.3, got %q", entry.HTTP["tls_version"])
+		}
+		if entry.HTTP["tls_cipher"] == "" {
+			t.Error("Expected TLS cipher suite")
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TestResponseContentType verifies the response media type and cache status reach the Loki http object
+func TestResponseContentType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/content-type.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "content-type-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "content-type",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	config := middleware.LoggerConfig{CacheStatusHeader: "X-Cache"}
+
+	engine := gin.New()
+	engine.Use(middleware.GinMiddleware(logger), middleware.GinLoggerWithConfig(logger, config))
+	engine.GET("/api/orders", func(c *gin.Context) {
+		c.Header("X-Cache", "HIT")
+		c.JSON(200, gin.H{"orders": []string{}})
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html></html>"))
+	})
+	mux.HandleFunc("GET /empty", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := middleware.HTTPMiddleware(logger)(middleware.HTTPLoggerWithConfig(logger, config)(mux))
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/orders", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/empty", nil))
+	logger.Close()
+
+	content, _ := os.ReadFile(basicLogDir + "/content-type.loki.log")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 Loki entries, got %d: %s", len(lines), content)
+	}
+
+	want := []map[string]string{
+		{"content_type": "application/json", "cache_status": "HIT"},
+		{"content_type": "text/html", "cache_status": ""},
+		{"content_type": "", "cache_status": ""},
+	}
+	for i, line := range lines {
+		var entry struct {
+			HTTP map[string]string `json:"http"`
+		}
+		json.Unmarshal([]byte(line), &entry)
+		for key, value := range want[i] {
+			if entry.HTTP[key] != value {
+				t.Errorf("Entry %d: expected http.%s %q, got %q", i, key, value, entry.HTTP[key])
+			}
+		}
+	}
+}
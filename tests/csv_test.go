@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestCSVAccessLog verifies the access stream can be written as CSV with custom columns
+func TestCSVAccessLog(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/csv-test.access.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:      "csv-test",
+		LogPath:          basicLogDir,
+		FilePrefix:       "csv-test",
+		EnableFile:       true,
+		EnableRotation:   false,
+		AccessLogFormat:  logging.FormatCSV,
+		AccessLogColumns: []string{"level", "status", "method", "path", "ua", "error", "msg"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{
+		RequestID: "req-csv",
+		Method:    "GET",
+		Path:      "/reports",
+		UserAgent: "Mozilla/5.0 (X11, Linux)",
+	})
+	logger.LogRequest(ctx, 200, 3*time.Millisecond)
+	logger.LogRequestWithError(ctx, 404, time.Millisecond, errors.New(`report "q3" not found`))
+	logger.Info("export done")
+	logger.Close()
+
+	f, err := os.Open(basicLogDir + "/csv-test.access.log")
+	if err != nil {
+		t.Fatalf("Failed to open access log: %v", err)
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("Access log is not valid CSV: %v", err)
+	}
+
+	want := [][]string{
+		{"level", "status", "method", "path", "ua", "error", "msg"},
+		{"INFO", "200", "GET", "/reports", "Mozilla/5.0 (X11, Linux)", "", ""},
+		{"ERROR", "404", "GET", "/reports", "Mozilla/5.0 (X11, Linux)", `report "q3" not found`, ""},
+		{"INFO", "", "", "", "", "", "export done"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Expected %d rows, got %d: %v", len(want), len(rows), rows)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("Row %d column %s: expected %q, got %q", i, want[0][j], want[i][j], rows[i][j])
+			}
+		}
+	}
+
+	if _, err := logging.New(&logging.Config{AccessLogColumns: []string{"nope"}}); err == nil {
+		t.Error("Expected error for unknown column")
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestDebugStream verifies debug output goes to its own file only when enabled
+func TestDebugStream(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/debug-test.debug.log")
+	os.Remove(basicLogDir + "/debug-test.access.log")
+	os.Remove(basicLogDir + "/nodebug-test.debug.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "debug-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "debug-test",
+		EnableFile:     true,
+		EnableRotation: false,
+		MinLevel:       logging.LevelDebug,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.Debug(context.Background(), "DEBUG TEST: cache miss for key user:42")
+	logger.Info("DEBUG TEST: request handled")
+	logger.Close()
+
+	debug, err := os.ReadFile(basicLogDir + "/debug-test.debug.log")
+	if err != nil {
+		t.Fatalf("Failed to read debug log: %v", err)
+	}
+	if !strings.Contains(string(debug), "[DEBUG] DEBUG TEST: cache miss") {
+		t.Error("Expected debug message in debug log")
+	}
+	if !strings.Contains(string(debug), "debug_test.go:") {
+		t.Error("Expected caller file in debug log")
+	}
+
+	access, _ := os.ReadFile(basicLogDir + "/debug-test.access.log")
+	if strings.Contains(string(access), "cache miss") {
+		t.Error("Debug message should not appear in access log")
+	}
+
+	quiet, err := logging.New(&logging.Config{
+		ServiceName:    "nodebug-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "nodebug-test",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	quiet.Debug(context.Background(), "DEBUG TEST: should be dropped")
+	quiet.Close()
+
+	if _, err := os.Stat(basicLogDir + "/nodebug-test.debug.log"); err == nil {
+		t.Error("Debug file should not be created at default INFO level")
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestSyncEvery verifies the writer fsyncs after every N writes
+func TestSyncEvery(t *testing.T) {
+	w, err := logging.NewWriter(logging.WriterOptions{
+		BasePath:  t.TempDir() + "/sync-every",
+		SyncEvery: 3,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer w.Close()
+
+	for i := 0; i < 7; i++ {
+		w.Write([]byte("entry\n"))
+	}
+	if stats := w.Stats(); stats.Syncs != 2 || stats.Writes != 7 {
+		t.Errorf("Expected 2 syncs for 7 writes, got %+v", stats)
+	}
+}
+
+// TestSyncOnCritical verifies CRITICAL entries fsync the error and Loki files only
+func TestSyncOnCritical(t *testing.T) {
+	dir := t.TempDir()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "durability-test",
+		LogPath:        dir,
+		FilePrefix:     "durable",
+		EnableFile:     true,
+		EnableRotation: false,
+		SyncOnCritical: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "durable", Method: "POST", Path: "/payments"})
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("card declined"))
+	if syncs := writerSyncs(t, logger); syncs[dir+"/durable.loki"] != 0 {
+		t.Fatalf("Expected no sync for ERROR entries, got %v", syncs)
+	}
+
+	logger.Error(ctx, errors.New("ledger unreachable"))
+	logger.ErrorLoki(ctx, logging.LevelCritical, errors.New("ledger unreachable"))
+	syncs := writerSyncs(t, logger)
+	if syncs[dir+"/durable.error"] != 1 || syncs[dir+"/durable.loki"] != 1 {
+		t.Errorf("Expected error and loki files synced once, got %v", syncs)
+	}
+	if syncs[dir+"/durable.access"] != 0 {
+		t.Errorf("Expected access file left to the OS, got %v", syncs)
+	}
+}
+
+func writerSyncs(t *testing.T, logger *logging.Logger) map[string]uint64 {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	logging.AdminHandler(logger).ServeHTTP(rec, httptest.NewRequest("GET", "/stats", nil))
+
+	var resp struct {
+		Writers map[string]logging.WriterStats `json:"writers"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode stats: %v (%s)", err, rec.Body.String())
+	}
+
+	syncs := make(map[string]uint64, len(resp.Writers))
+	for path, stats := range resp.Writers {
+		syncs[path] = stats.Syncs
+	}
+	return syncs
+}
+
+func benchmarkCritical(b *testing.B, syncOnCritical bool, syncEvery int) {
+	logger, _ := logging.New(&logging.Config{
+		ServiceName:    "durability-bench",
+		LogPath:        b.TempDir(),
+		EnableFile:     true,
+		SyncOnCritical: syncOnCritical,
+		SyncEvery:      syncEvery,
+	})
+	defer logger.Close()
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "bench", Method: "POST", Path: "/bench"})
+	err := errors.New("bench failure")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.ErrorLoki(ctx, logging.LevelCritical, err)
+	}
+}
+
+func BenchmarkCriticalNoSync(b *testing.B)         { benchmarkCritical(b, false, 0) }
+func BenchmarkCriticalSyncOnCritical(b *testing.B) { benchmarkCritical(b, true, 0) }
+func BenchmarkCriticalSyncEvery100(b *testing.B)   { benchmarkCritical(b, false, 100) }
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/email"
+	"github.com/ahmadsaubani/go-logging-lib/outbound"
+)
+
+// smtpMessage is one message received by smtpServer
+type smtpMessage struct {
+	Data string
+	TLS  bool
+}
+
+// smtpServer accepts SMTP sessions without auth; with tlsConfig it offers STARTTLS
+func smtpServer(t *testing.T, tlsConfig *tls.Config) (host string, port int, messages <-chan smtpMessage) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	out := make(chan smtpMessage, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, tlsConfig, out)
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, out
+}
+
+func serveSMTP(conn net.Conn, tlsConfig *tls.Config, out chan<- smtpMessage) {
+	defer func() { conn.Close() }()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 test ESMTP")
+	secure := false
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		switch cmd := strings.ToUpper(strings.Fields(line + " x")[0]); cmd {
+		case "EHLO", "HELO":
+			if tlsConfig != nil && !secure {
+				tp.PrintfLine("250-test")
+				tp.PrintfLine("250 STARTTLS")
+			} else {
+				tp.PrintfLine("250 test")
+			}
+		case "STARTTLS":
+			tp.PrintfLine("220 ready")
+			conn = tls.Server(conn, tlsConfig)
+			tp = textproto.NewConn(conn)
+			secure = true
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			data, _ := tp.ReadDotBytes()
+			out <- smtpMessage{Data: string(data), TLS: secure}
+			tp.PrintfLine("250 ok")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("250 ok")
+		}
+	}
+}
+
+// TestEmailMultipartAlternative verifies alerts carry a plaintext part before the HTML part
+func TestEmailMultipartAlternative(t *testing.T) {
+	host, port, messages := smtpServer(t, nil)
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "orders-api",
+		Alerts: &logging.AlertsConfig{
+			Enabled:  true,
+			MinLevel: "ERROR",
+			Email: &email.Config{
+				Enabled:  true,
+				SMTPHost: host,
+				SMTPPort: port,
+				From:     "alerts@example.com",
+				To:       []string{"oncall@example.com"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "mail-1", Method: "POST", Path: "/orders"})
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("stock service unavailable – retry later"))
+	logger.Close()
+
+	var raw string
+	select {
+	case m := <-messages:
+		raw = m.Data
+	case <-time.After(2 * time.Second):
+		t.Fatal("No email received")
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("Invalid message: %v", err)
+	}
+	mediaType, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("Expected multipart/alternative, got %q", mediaType)
+	}
+	if subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); !strings.Contains(subject, "orders-api") {
+		t.Errorf("Unexpected subject %q", subject)
+	}
+
+	var types []string
+	var text string
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Invalid part: %v", err)
+		}
+		body, _ := io.ReadAll(part)
+		contentType := part.Header.Get("Content-Type")
+		types = append(types, contentType)
+		if strings.HasPrefix(contentType, "text/plain") {
+			text = string(body)
+		}
+	}
+
+	if len(types) != 2 || !strings.HasPrefix(types[0], "text/plain") || !strings.HasPrefix(types[1], "text/html") {
+		t.Fatalf("Expected plaintext then HTML part, got %q", types)
+	}
+	for _, want := range []string{"ERROR ALERT - orders-api", "stock service unavailable – retry later", "Request ID:  mail-1"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected plaintext to contain %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "<") {
+		t.Errorf("Expected no markup in plaintext part")
+	}
+}
+
+// emailAPIServer records the last request made to a fake email API
+func emailAPIServer(t *testing.T) (*httptest.Server, <-chan *http.Request, <-chan []byte) {
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests, bodies
+}
+
+// TestEmailAPIProviders verifies SendGrid, Mailgun and SES requests carry auth and both bodies
+func TestEmailAPIProviders(t *testing.T) {
+	tests := []struct {
+		name   string
+		config email.Config
+		check  func(t *testing.T, r *http.Request, body []byte)
+	}{
+		{
+			name:   "sendgrid",
+			config: email.Config{Provider: "sendgrid", APIKey: "SG.key"},
+			check: func(t *testing.T, r *http.Request, body []byte) {
+				if r.Header.Get("Authorization") != "Bearer SG.key" {
+					t.Errorf("Expected bearer auth, got %q", r.Header.Get("Authorization"))
+				}
+				var msg struct {
+					Subject string `json:"subject"`
+					Content []struct {
+						Type string `json:"type"`
+					} `json:"content"`
+				}
+				json.Unmarshal(body, &msg)
+				if msg.Subject != "Weekly report" || len(msg.Content) != 2 || msg.Content[0].Type != "text/plain" {
+					t.Errorf("Unexpected sendgrid body %s", body)
+				}
+			},
+		},
+		{
+			name:   "mailgun",
+			config: email.Config{Provider: "mailgun", APIKey: "mg-key", Domain: "mg.example.com"},
+			check: func(t *testing.T, r *http.Request, body []byte) {
+				if user, pass, _ := r.BasicAuth(); user != "api" || pass != "mg-key" {
+					t.Errorf("Expected basic auth api:mg-key, got %s:%s", user, pass)
+				}
+				if r.URL.Path != "/v3/mg.example.com/messages" {
+					t.Errorf("Unexpected mailgun path %s", r.URL.Path)
+				}
+				_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+				form, err := multipart.NewReader(bytes.NewReader(body), params["boundary"]).ReadForm(1 << 20)
+				if err != nil {
+					t.Fatalf("Invalid mailgun form: %v", err)
+				}
+				if form.Value["subject"][0] != "Weekly report" || form.Value["text"][0] == "" || form.Value["to"][0] != "oncall@example.com" {
+					t.Errorf("Unexpected mailgun form %v", form.Value)
+				}
+			},
+		},
+		{
+			name:   "ses",
+			config: email.Config{Provider: "ses", Region: "eu-west-1", AccessKeyID: "AKID", SecretAccessKey: "secret"},
+			check: func(t *testing.T, r *http.Request, body []byte) {
+				auth := r.Header.Get("Authorization")
+				if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/ses/aws4_request") {
+					t.Errorf("Expected SigV4 auth, got %q", auth)
+				}
+				if r.URL.Path != "/v2/email/outbound-emails" || r.Header.Get("X-Amz-Date") == "" {
+					t.Errorf("Unexpected ses request %s %v", r.URL.Path, r.Header)
+				}
+				if !strings.Contains(string(body), `"ToAddresses":["oncall@example.com"]`) {
+					t.Errorf("Unexpected ses body %s", body)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests, bodies := emailAPIServer(t)
+			config := tt.config
+			config.APIURL = server.URL
+			config.From = "alerts@example.com"
+			config.To = []string{"oncall@example.com"}
+
+			if err := email.New(&config).SendMessage(t.Context(), "Weekly report", "<p>report</p>", "report"); err != nil {
+				t.Fatalf("SendMessage failed: %v", err)
+			}
+			tt.check(t, <-requests, <-bodies)
+		})
+	}
+}
+
+// TestEmailAPIErrors verifies unknown providers and API failures are reported
+func TestEmailAPIErrors(t *testing.T) {
+	to := []string{"oncall@example.com"}
+	if err := email.New(&email.Config{Provider: "postmark", To: to}).SendMessage(t.Context(), "s", "h", ""); err == nil {
+		t.Error("Expected error for an unknown provider")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errors":[{"message":"invalid key"}]}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	err := email.New(&email.Config{Provider: "sendgrid", APIKey: "bad", APIURL: server.URL, To: to}).SendMessage(t.Context(), "s", "h", "")
+	if err == nil || !strings.Contains(err.Error(), "status 401") || !strings.Contains(err.Error(), "invalid key") {
+		t.Errorf("Expected status and detail in error, got %v", err)
+	}
+}
+
+// TestEmailStartTLS verifies STARTTLS upgrades with certificate checks and the required/disabled policies
+func TestEmailStartTLS(t *testing.T) {
+	certServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer certServer.Close()
+	caFile := writeServerCA(t, certServer)
+	host, port, messages := smtpServer(t, certServer.TLS)
+
+	send := func(config email.Config) error {
+		config.SMTPHost, config.SMTPPort = host, port
+		config.From, config.To = "alerts@example.com", []string{"oncall@example.com"}
+		config.TimeoutSec = 2
+		return email.New(&config).SendMessage(t.Context(), "subject", "<p>body</p>", "body")
+	}
+
+	if err := send(email.Config{StartTLS: "required", TLS: &outbound.TLSConfig{CAFile: caFile}}); err != nil {
+		t.Fatalf("STARTTLS with trusted CA failed: %v", err)
+	}
+	if m := <-messages; !m.TLS {
+		t.Error("Expected the message to be sent after STARTTLS")
+	}
+
+	if err := send(email.Config{}); err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("Expected opportunistic STARTTLS to verify the certificate, got %v", err)
+	}
+
+	if err := send(email.Config{StartTLS: "disabled"}); err != nil {
+		t.Fatalf("Plain delivery failed: %v", err)
+	}
+	if m := <-messages; m.TLS {
+		t.Error("Expected no TLS with starttls disabled")
+	}
+
+	plainHost, plainPort, _ := smtpServer(t, nil)
+	err := email.New(&email.Config{
+		SMTPHost: plainHost, SMTPPort: plainPort, StartTLS: "required",
+		From: "alerts@example.com", To: []string{"oncall@example.com"},
+	}).SendMessage(t.Context(), "subject", "<p>body</p>", "")
+	if err == nil || !strings.Contains(err.Error(), "does not offer STARTTLS") {
+		t.Errorf("Expected required STARTTLS to fail without server support, got %v", err)
+	}
+}
+
+// TestEmailTemplateOverride verifies custom template files replace the built-in ones and are checked at startup
+func TestEmailTemplateOverride(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(dir+"/alert.html", []byte(`<h1>ACME incident</h1><p>{{.Service}}: {{.Error}} at {{.Payload.Timestamp.Year}}</p>`), 0644)
+	os.WriteFile(dir+"/alert.txt", []byte(`ACME incident - {{.Service}}: {{.Error}}`), 0644)
+	os.WriteFile(dir+"/broken.html", []byte(`<p>{{.NoSuchField}}</p>`), 0644)
+
+	host, port, messages := smtpServer(t, nil)
+	config := &email.Config{
+		Enabled:          true,
+		SMTPHost:         host,
+		SMTPPort:         port,
+		From:             "alerts@example.com",
+		To:               []string{"oncall@example.com"},
+		TemplateFile:     dir + "/alert.html",
+		TextTemplateFile: dir + "/alert.txt",
+	}
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "orders-api",
+		Alerts:      &logging.AlertsConfig{Enabled: true, MinLevel: "ERROR", Email: config},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "tmpl-1", Method: "GET", Path: "/orders"})
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("<script>boom</script>"))
+	logger.Close()
+
+	m := <-messages
+	msg, _ := mail.ReadMessage(strings.NewReader(m.Data))
+	_, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		body, _ := io.ReadAll(part)
+		bodies = append(bodies, string(body))
+	}
+	if len(bodies) != 2 || bodies[0] != "ACME incident - orders-api: <script>boom</script>" {
+		t.Fatalf("Expected custom plaintext body, got %q", bodies)
+	}
+	if !strings.Contains(bodies[1], "<h1>ACME incident</h1>") || !strings.Contains(bodies[1], "&lt;script&gt;") {
+		t.Errorf("Expected custom escaped HTML body, got %q", bodies[1])
+	}
+
+	for _, file := range []string{dir + "/broken.html", dir + "/missing.html"} {
+		_, err := logging.New(&logging.Config{
+			ServiceName: "orders-api",
+			Alerts: &logging.AlertsConfig{Enabled: true, Email: &email.Config{
+				SMTPHost: host, To: []string{"oncall@example.com"}, TemplateFile: file,
+			}},
+		})
+		if err == nil {
+			t.Errorf("Expected startup error for template %s", file)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestEnvironmentInEntries verifies the environment reaches Loki entries and prefixes access lines
+func TestEnvironmentInEntries(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/environment.access.log")
+	os.Remove(basicLogDir + "/environment.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:       "env-test",
+		Environment:       "staging",
+		EnvironmentPrefix: true,
+		LogPath:           basicLogDir,
+		FilePrefix:        "environment",
+		EnableFile:        true,
+		EnableRotation:    false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "req-env", Method: "GET", Path: "/ping"})
+	logger.LogRequest(ctx, 200, time.Millisecond)
+	logger.Close()
+
+	access, _ := os.ReadFile(basicLogDir + "/environment.access.log")
+	if !strings.Contains(string(access), "[staging] [REQ:req-env]") {
+		t.Errorf("Expected environment prefix on access line, got %q", access)
+	}
+
+	loki, _ := os.ReadFile(basicLogDir + "/environment.loki.log")
+	if !strings.Contains(string(loki), `"environment":"staging"`) {
+		t.Errorf("Expected environment in Loki entry, got %q", loki)
+	}
+}
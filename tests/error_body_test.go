@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TestErrorBodyCapture verifies error response bodies reach errors.response_body, truncated, and successes are skipped
+func TestErrorBodyCapture(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/error-body.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "error-body-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "error-body",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	config := middleware.LoggerConfig{ErrorBodyBytes: 32}
+
+	engine := gin.New()
+	engine.Use(middleware.GinMiddleware(logger), middleware.GinLoggerWithConfig(logger, config))
+	engine.POST("/orders", func(c *gin.Context) {
+		c.JSON(400, gin.H{"error": "quantity must be positive"})
+	})
+	engine.GET("/orders", func(c *gin.Context) {
+		c.JSON(200, gin.H{"orders": []string{}})
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /report", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, strings.Repeat("x", 100), http.StatusServiceUnavailable)
+	})
+	handler := middleware.HTTPMiddleware(logger)(middleware.HTTPLoggerWithConfig(logger, config)(mux))
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/orders", nil))
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/report", nil))
+	logger.Close()
+
+	content, _ := os.ReadFile(basicLogDir + "/error-body.loki.log")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 Loki entries, got %d: %s", len(lines), content)
+	}
+
+	want := []string{
+		`{"error":"quantity must be positive"}`[:32] + "...(truncated)",
+		"",
+		strings.Repeat("x", 32) + "...(truncated)",
+	}
+	for i, line := range lines {
+		var entry struct {
+			Errors map[string]interface{} `json:"errors"`
+		}
+		json.Unmarshal([]byte(line), &entry)
+		got, _ := entry.Errors["response_body"].(string)
+		if got != want[i] {
+			t.Errorf("Entry %d: expected response_body %q, got %q", i, want[i], got)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestSingleLineErrorLog verifies ErrorLogFormat keeps each error on one line
+func TestSingleLineErrorLog(t *testing.T) {
+	for _, format := range []logging.Format{logging.FormatLogfmt, logging.FormatJSON} {
+		prefix := "errfmt-" + string(format)
+		content := writeErrorLog(t, prefix, format)
+
+		lines := strings.Split(strings.TrimSpace(content), "\n")
+		if len(lines) != 1 {
+			t.Fatalf("%s: expected 1 line, got %d: %q", format, len(lines), content)
+		}
+
+		if format == logging.FormatLogfmt {
+			for _, want := range []string{
+				"level=ERROR request_id=req-errfmt",
+				"source=errorformat_test.go:",
+				"method=DELETE path=/carts/7",
+				`msg="failed to clear cart"`,
+				`error="lock timeout\nretry later"`,
+				"stack=",
+			} {
+				if !strings.Contains(lines[0], want) {
+					t.Errorf("Expected %q in %q", want, lines[0])
+				}
+			}
+			continue
+		}
+
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+			t.Fatalf("Expected JSON error line, got %q: %v", lines[0], err)
+		}
+		if rec["error"] != "lock timeout\nretry later" || rec["msg"] != "failed to clear cart" {
+			t.Errorf("Unexpected error fields: %v", rec)
+		}
+		if !strings.HasPrefix(rec["source"].(string), "errorformat_test.go:") {
+			t.Errorf("Expected source in test file, got %v", rec["source"])
+		}
+		if stack, ok := rec["stack"].([]interface{}); !ok || len(stack) == 0 {
+			t.Errorf("Expected stack frames, got %v", rec["stack"])
+		}
+	}
+}
+
+// TestJSONErrorLogOtherWriters verifies std-log and named-logger lines stay valid JSON in the error stream
+func TestJSONErrorLogOtherWriters(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/errfmt-writers.error.log")
+
+	registry, err := logging.NewRegistry(&logging.Config{
+		ServiceName:    "errfmt-writers",
+		LogPath:        basicLogDir,
+		FilePrefix:     "errfmt-writers",
+		EnableFile:     true,
+		EnableRotation: false,
+		ErrorLogFormat: logging.FormatJSON,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+
+	base := registry.Get("base")
+	base.StdErrorLogger("[HTTP] ").Print("TLS handshake error")
+	restore := base.RedirectStdLog()
+	log.Print("stray dependency output")
+	restore()
+	registry.Get("tenant-acme").Error(logging.WithMeta(t.Context(), logging.Meta{RequestID: "req-tenant"}), errors.New("quota exceeded"))
+	registry.Close()
+
+	content, _ := os.ReadFile(basicLogDir + "/errfmt-writers.error.log")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d: %s", len(lines), content)
+	}
+
+	want := []map[string]string{
+		{"logger": "HTTP", "msg": "TLS handshake error"},
+		{"logger": "STDLOG", "msg": "stray dependency output"},
+		{"request_id": "req-tenant", "error": "quota exceeded"},
+	}
+	for i, line := range lines {
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("Line %d: expected JSON, got %q: %v", i, line, err)
+		}
+		for key, value := range want[i] {
+			if rec[key] != value {
+				t.Errorf("Line %d: expected %s %q, got %v", i, key, value, rec[key])
+			}
+		}
+	}
+}
+
+func writeErrorLog(t *testing.T, prefix string, format logging.Format) string {
+	t.Helper()
+
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/" + prefix + ".error.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    prefix,
+		LogPath:        basicLogDir,
+		FilePrefix:     prefix,
+		EnableFile:     true,
+		EnableRotation: false,
+		ErrorLogFormat: format,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{
+		RequestID: "req-errfmt",
+		Method:    "DELETE",
+		Path:      "/carts/7",
+	})
+	logger.ErrorMsg(ctx, "failed to clear cart", errors.New("lock timeout\nretry later"))
+	logger.Close()
+
+	content, err := os.ReadFile(basicLogDir + "/" + prefix + ".error.log")
+	if err != nil {
+		t.Fatalf("Failed to read error log: %v", err)
+	}
+	return string(content)
+}
+
+// TestConcurrentBoxedErrors verifies concurrent errors produce whole, flagged blocks in the default format
+func TestConcurrentBoxedErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "boxed-concurrency-test",
+		LogPath:        dir,
+		FilePrefix:     "boxed",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	const workers, perWorker = 20, 25
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				id := fmt.Sprintf("%d-%d", w, i)
+				ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "req-" + id})
+				logger.Error(ctx, errors.New("boom-"+id))
+			}
+		}(w)
+	}
+	wg.Wait()
+	logger.Close()
+
+	data, err := os.ReadFile(dir + "/boxed.error.log")
+	if err != nil {
+		t.Fatalf("Failed to read error log: %v", err)
+	}
+
+	blocks := strings.Split(string(data), "[ERROR]\n")[1:]
+	if len(blocks) != workers*perWorker {
+		t.Fatalf("Expected %d blocks, got %d", workers*perWorker, len(blocks))
+	}
+	for _, block := range blocks {
+		var id string
+		for _, line := range strings.Split(block, "\n") {
+			if rest, ok := strings.CutPrefix(line, "ERROR  : boom-"); ok {
+				id = rest
+			}
+		}
+		if id == "" || strings.Count(block, "ERROR  : ") != 1 || !strings.Contains(block, "REQ    : req-"+id+"\n") {
+			t.Fatalf("Expected one whole error per block, got:\n%s", block)
+		}
+		if strings.Count(block, "==CRITICAL[") != 2 {
+			t.Fatalf("Expected opening and closing separator, got:\n%s", block)
+		}
+	}
+
+	// every block header keeps the timestamp and caller flags
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasSuffix(line, "[ERROR]") && !strings.Contains(line, "error_logging.go:") {
+			t.Fatalf("Expected flags on block header, got %q", line)
+		}
+	}
+}
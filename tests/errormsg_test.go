@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestErrorMsg verifies the description reaches the error log and the Loki entry
+func TestErrorMsg(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/errormsg.error.log")
+	os.Remove(basicLogDir + "/errormsg.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "errormsg-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "errormsg",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "msg-1", Method: "POST", Path: "/pay"})
+	logger.ErrorMsg(ctx, "failed to charge card", errors.New("card declined"))
+	logger.Errorf(ctx, "failed to refund order %d", 42, errors.New("gateway timeout"))
+	logger.Close()
+
+	errorLog, _ := os.ReadFile(basicLogDir + "/errormsg.error.log")
+	for _, want := range []string{
+		"MSG    : failed to charge card\nERROR  : card declined",
+		"MSG    : failed to refund order 42\nERROR  : gateway timeout",
+		"FROM   : errormsg_test.go:",
+	} {
+		if !strings.Contains(string(errorLog), want) {
+			t.Errorf("Expected %q in error log:\n%s", want, errorLog)
+		}
+	}
+
+	content, _ := os.ReadFile(basicLogDir + "/errormsg.loki.log")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 Loki entries, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], `"message":"failed to charge card"`) || !strings.Contains(lines[0], `"error":"card declined"`) {
+		t.Errorf("Expected message and raw error in Loki entry, got %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"file":"errormsg_test.go"`) {
+		t.Errorf("Expected Errorf call site as source, got %s", lines[1])
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TestHTTPAccumulatedErrors verifies AddHTTPError keeps every error in the Loki entry
+func TestHTTPAccumulatedErrors(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/multi-errors.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "multi-errors-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "multi-errors",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+		middleware.AddHTTPError(r, errors.New("invalid quantity"))
+		middleware.AddHTTPError(r, errors.New("unknown sku"))
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	handler := middleware.HTTPMiddleware(logger)(middleware.HTTPLogger(logger)(mux))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/orders", nil))
+	logger.Close()
+
+	entry := readSingleLokiEntry(t, basicLogDir+"/multi-errors.loki.log")
+	if entry.Errors == nil {
+		t.Fatal("Expected errors in Loki entry")
+	}
+	if len(entry.Errors.Messages) != 2 ||
+		entry.Errors.Messages[0] != "invalid quantity" ||
+		entry.Errors.Messages[1] != "unknown sku" {
+		t.Errorf("Expected both messages, got %v", entry.Errors.Messages)
+	}
+	if entry.Errors.Error != "invalid quantity; unknown sku" {
+		t.Errorf("Expected joined error, got %q", entry.Errors.Error)
+	}
+}
+
+// TestGinAccumulatedErrors verifies AddLoggedError keeps every error in the Loki entry
+func TestGinAccumulatedErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ginLogDir := "../examples/gin/logs"
+	os.MkdirAll(ginLogDir, 0755)
+	os.Remove(ginLogDir + "/multi-errors.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "multi-errors-test",
+		LogPath:        ginLogDir,
+		FilePrefix:     "multi-errors",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(middleware.GinMiddleware(logger))
+	r.Use(middleware.GinLogger(logger))
+	r.POST("/orders", func(c *gin.Context) {
+		logging.AddLoggedError(c, errors.New("invalid quantity"))
+		logging.AddLoggedError(c, errors.New("unknown sku"))
+		c.Status(http.StatusBadRequest)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/orders", nil))
+	logger.Close()
+
+	entry := readSingleLokiEntry(t, ginLogDir+"/multi-errors.loki.log")
+	if entry.Errors == nil || len(entry.Errors.Messages) != 2 {
+		t.Fatalf("Expected two error messages, got %+v", entry.Errors)
+	}
+}
+
+func readSingleLokiEntry(t *testing.T, file string) LokiLogEntry {
+	t.Helper()
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Failed to read Loki log: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 Loki entry, got %d", len(lines))
+	}
+
+	var entry LokiLogEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Failed to parse Loki line: %v", err)
+	}
+	return entry
+}
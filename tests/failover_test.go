@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/sinks/loki"
+)
+
+// TestLokiFailoverAndRecovery verifies rejected pushes go to the fallback file and Loki is probed again
+func TestLokiFailoverAndRecovery(t *testing.T) {
+	dir := t.TempDir()
+
+	var healthy atomic.Bool
+	var mu sync.Mutex
+	accepted := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		mu.Lock()
+		accepted++
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	internal := &lockedBuffer{}
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "failover-test",
+		EnableLoki:  true,
+		InternalLog: internal,
+		Fallbacks:   map[string]string{"loki": dir + "/loki-fallback"},
+		Loki: &loki.Config{
+			Enabled:          true,
+			URL:              server.URL,
+			ProbeIntervalSec: 1,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "failover", Method: "GET", Path: "/orders"})
+
+	// first push fails and switches over, the second entry skips Loki entirely
+	logger.LogRequest(ctx, 200, time.Millisecond)
+	logger.Flush()
+	logger.LogRequest(ctx, 201, time.Millisecond)
+
+	data, err := os.ReadFile(dir + "/loki-fallback.log")
+	if err != nil {
+		t.Fatalf("Failed to read fallback file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"request_id":"failover"`) || !strings.Contains(lines[1], `"status_code":201`) {
+		t.Fatalf("Expected both entries in fallback file, got: %s", data)
+	}
+	if !strings.Contains(internal.String(), "switching to fallback") {
+		t.Errorf("Expected failover in self-log, got: %q", internal.String())
+	}
+
+	// once the probe interval passed, the next entry probes Loki and switches back
+	healthy.Store(true)
+	time.Sleep(1100 * time.Millisecond)
+	logger.LogRequest(ctx, 202, time.Millisecond)
+	if err := logger.Flush(); err != nil {
+		t.Fatalf("Expected probe push to succeed: %v", err)
+	}
+
+	mu.Lock()
+	if accepted != 1 {
+		t.Errorf("Expected 1 push accepted after recovery, got %d", accepted)
+	}
+	mu.Unlock()
+
+	if !strings.Contains(internal.String(), "recovered") {
+		t.Errorf("Expected recovery in self-log, got: %q", internal.String())
+	}
+	if st := logger.Stats(); st.WriteErrors != 0 || st.Dropped != 0 || st.FailedOver != 2 {
+		t.Errorf("Expected no lost entries, got %+v", st)
+	}
+}
+
+// TestFileFallback verifies a failing file stream writes to its declared fallback instead of stdout
+func TestFileFallback(t *testing.T) {
+	dir := t.TempDir()
+
+	secondary, err := logging.NewWriter(logging.WriterOptions{BasePath: dir + "/secondary"})
+	if err != nil {
+		t.Fatalf("Failed to create fallback writer: %v", err)
+	}
+	defer secondary.Close()
+
+	primary, err := logging.NewWriter(logging.WriterOptions{
+		BasePath: dir + "/primary",
+		Fallback: secondary,
+		OnError:  func(string, error) {},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+
+	// closing the underlying file makes the next write fail
+	primary.Close()
+	primary.Write([]byte("FAILOVER TEST: after failure\n"))
+
+	data, _ := os.ReadFile(dir + "/secondary.log")
+	if string(data) != "FAILOVER TEST: after failure\n" {
+		t.Errorf("Expected entry in fallback file, got: %q", data)
+	}
+}
+
+// TestUnknownFallbackStream verifies New rejects fallbacks for unknown streams
+func TestUnknownFallbackStream(t *testing.T) {
+	_, err := logging.New(&logging.Config{
+		ServiceName: "failover-test",
+		Fallbacks:   map[string]string{"audit": "logs/audit-fallback"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "audit") {
+		t.Errorf("Expected unknown stream error, got %v", err)
+	}
+}
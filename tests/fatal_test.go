@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+)
+
+// TestTraceLevel verifies TRACE sits below DEBUG and goes to the debug stream
+func TestTraceLevel(t *testing.T) {
+	for _, minLevel := range []logging.LogLevel{logging.LevelTrace, logging.LevelDebug} {
+		dir := t.TempDir()
+		logger, err := logging.New(&logging.Config{
+			ServiceName:    "trace-test",
+			LogPath:        dir,
+			FilePrefix:     "trace",
+			EnableFile:     true,
+			EnableRotation: false,
+			MinLevel:       minLevel,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		logger.Trace(context.Background(), "TRACE TEST: entering handler")
+		logger.Debug(context.Background(), "TRACE TEST: cache miss")
+		logger.Close()
+
+		debug, _ := os.ReadFile(dir + "/trace.debug.log")
+		traced := strings.Contains(string(debug), "[TRACE] TRACE TEST: entering handler")
+		if traced != (minLevel == logging.LevelTrace) {
+			t.Errorf("MinLevel %s: unexpected trace output %q", minLevel, debug)
+		}
+		if !strings.Contains(string(debug), "[DEBUG] TRACE TEST: cache miss") {
+			t.Errorf("MinLevel %s: expected debug line, got %q", minLevel, debug)
+		}
+	}
+
+	logger, _ := logging.New(&logging.Config{ServiceName: "trace-test"})
+	defer logger.Close()
+	for _, level := range []logging.LogLevel{"trace", "fatal"} {
+		if err := logger.SetLevel(level); err != nil {
+			t.Errorf("Expected %s to be a valid level: %v", level, err)
+		}
+	}
+	if !logger.Enabled(logging.LevelFatal) || logger.Enabled(logging.LevelCritical) {
+		t.Error("Expected FATAL to rank above CRITICAL")
+	}
+}
+
+// TestFatal verifies Fatal logs, delivers the alert and exits with the configured code
+func TestFatal(t *testing.T) {
+	if dir := os.Getenv("FATAL_TEST_DIR"); dir != "" {
+		runFatal(dir, os.Getenv("FATAL_TEST_WEBHOOK"))
+		return
+	}
+
+	var delivered atomic.Int32
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		delivered.Add(1)
+	}))
+	defer webhook.Close()
+
+	dir := t.TempDir()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatal$")
+	cmd.Env = append(os.Environ(), "FATAL_TEST_DIR="+dir, "FATAL_TEST_WEBHOOK="+webhook.URL)
+	out, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("Expected exit code 3, got %v:\n%s", err, out)
+	}
+	if strings.Contains(string(out), "after fatal") {
+		t.Error("Expected nothing to run after Fatal")
+	}
+	if delivered.Load() != 2 {
+		t.Errorf("Expected both alerts delivered before exit (the second despite rate limiting), got %d", delivered.Load())
+	}
+
+	errorLog, _ := os.ReadFile(dir + "/fatal.error.log")
+	if !strings.Contains(string(errorLog), "ERROR  : config missing") {
+		t.Errorf("Expected error block, got:\n%s", errorLog)
+	}
+
+	loki, _ := os.ReadFile(dir + "/fatal.loki.log")
+	lines := strings.Split(strings.TrimSpace(string(loki)), "\n")
+	var entry LokiLogEntry
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &entry); err != nil {
+		t.Fatalf("Failed to parse Loki entry %q: %v", lines[len(lines)-1], err)
+	}
+	if len(lines) != 2 || entry.Level != "FATAL" || entry.Errors == nil || entry.Errors.Source["file"] != "fatal_test.go" {
+		t.Errorf("Expected FATAL Loki entry sourced in the test, got %+v", entry)
+	}
+}
+
+func runFatal(dir, webhook string) {
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "fatal-test",
+		LogPath:        dir,
+		FilePrefix:     "fatal",
+		EnableFile:     true,
+		EnableRotation: false,
+		FatalExitCode:  3,
+		Alerts: &logging.AlertsConfig{
+			Enabled:  true,
+			MinLevel: "ERROR",
+			Slack:    &slack.Config{Enabled: true, WebhookURL: webhook},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "fatal-1"})
+	// same error twice: a regular alert would rate limit the fatal one
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("config missing"))
+	logger.Fatal(ctx, errors.New("config missing"))
+	println("after fatal")
+}
+
+// TestFatalSingleLine verifies Fatal entries in a JSON error log carry the FATAL level
+func TestFatalSingleLine(t *testing.T) {
+	if dir := os.Getenv("FATAL_JSON_TEST_DIR"); dir != "" {
+		logger, err := logging.New(&logging.Config{
+			ServiceName:    "fatal-json-test",
+			LogPath:        dir,
+			FilePrefix:     "fatal-json",
+			EnableFile:     true,
+			EnableRotation: false,
+			ErrorLogFormat: logging.FormatJSON,
+		})
+		if err != nil {
+			panic(err)
+		}
+		logger.Fatal(context.Background(), errors.New("disk gone"))
+		return
+	}
+
+	dir := t.TempDir()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatalSingleLine$")
+	cmd.Env = append(os.Environ(), "FATAL_JSON_TEST_DIR="+dir)
+	out, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Fatalf("Expected exit code 1, got %v:\n%s", err, out)
+	}
+
+	errorLog, _ := os.ReadFile(dir + "/fatal-json.error.log")
+	var entry map[string]interface{}
+	if err := json.Unmarshal(errorLog, &entry); err != nil {
+		t.Fatalf("Expected one JSON error line, got %q: %v", errorLog, err)
+	}
+	if entry["level"] != "FATAL" || entry["error"] != "disk gone" {
+		t.Errorf("Expected a FATAL entry, got %v", entry)
+	}
+}
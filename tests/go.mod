@@ -3,8 +3,11 @@ module tests
 go 1.25.1
 
 require (
+	connectrpc.com/connect v1.19.2
 	github.com/ahmadsaubani/go-logging-lib v0.0.0
 	github.com/gin-gonic/gin v1.11.0
+	github.com/gorilla/mux v1.8.1
+	google.golang.org/protobuf v1.36.9
 )
 
 require (
@@ -39,7 +42,6 @@ require (
 	golang.org/x/sys v0.35.0 // indirect
 	golang.org/x/text v0.27.0 // indirect
 	golang.org/x/tools v0.34.0 // indirect
-	google.golang.org/protobuf v1.36.9 // indirect
 )
 
 replace github.com/ahmadsaubani/go-logging-lib v0.0.0 => ../
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+)
+
+// TestHeartbeatPingsAndFails verifies pings while logging is active and a failure ping plus alert once it goes silent
+func TestHeartbeatPingsAndFails(t *testing.T) {
+	var mu sync.Mutex
+	var pings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		pings = append(pings, r.Method+" "+r.URL.Path+" "+string(body))
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "heartbeat-test",
+		Heartbeat: &logging.HeartbeatConfig{
+			URL:             server.URL + "/ping",
+			FailURL:         server.URL + "/ping/fail",
+			IntervalSec:     1,
+			RequireActivity: true,
+		},
+		Alerts: &logging.AlertsConfig{
+			Enabled:  true,
+			MinLevel: "ERROR",
+			Slack:    &slack.Config{Enabled: true, WebhookURL: server.URL + "/slack"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "heartbeat", Method: "GET", Path: "/orders"})
+	logger.LogRequest(ctx, 200, time.Millisecond)
+	time.Sleep(2300 * time.Millisecond)
+	logger.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(pings) < 3 {
+		t.Fatalf("Expected ping, failure ping and alert, got %q", pings)
+	}
+	if pings[0] != "GET /ping " {
+		t.Errorf("Expected healthy ping first, got %q", pings[0])
+	}
+	joined := strings.Join(pings[1:], "\n")
+	if !strings.Contains(joined, "POST /ping/fail logging pipeline unhealthy: nothing logged") {
+		t.Errorf("Expected failure ping with reason, got %q", joined)
+	}
+	if !strings.Contains(joined, "POST /slack") || !strings.Contains(joined, "CRITICAL") {
+		t.Errorf("Expected CRITICAL alert for the silent pipeline, got %q", joined)
+	}
+}
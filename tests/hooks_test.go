@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+)
+
+// TestHooks verifies hooks can enrich entries and drop them before any writer
+func TestHooks(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/hooks-test.access.log")
+	os.Remove(basicLogDir + "/hooks-test.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "hooks-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "hooks-test",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.AddHook(func(e *logging.Entry) bool {
+		return e.Meta.Path != "/healthz"
+	})
+	logger.AddHook(func(e *logging.Entry) bool {
+		e.Fields["build_sha"] = "abc123"
+		return true
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+	handler := middleware.HTTPMiddleware(logger)(middleware.HTTPLogger(logger)(mux))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders", nil))
+	logger.Close()
+
+	access, _ := os.ReadFile(basicLogDir + "/hooks-test.access.log")
+	if strings.Contains(string(access), "/healthz") {
+		t.Error("Dropped entry should not reach the access log")
+	}
+	if !strings.Contains(string(access), "/orders") {
+		t.Error("Expected /orders in access log")
+	}
+
+	content, err := os.ReadFile(basicLogDir + "/hooks-test.loki.log")
+	if err != nil {
+		t.Fatalf("Failed to read Loki log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 Loki entry, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], `"build_sha":"abc123"`) {
+		t.Errorf("Expected build_sha added by hook, got %s", lines[0])
+	}
+}
+
+// TestOnError verifies callbacks fire only for written ERROR/CRITICAL entries
+func TestOnError(t *testing.T) {
+	logger, err := logging.New(&logging.Config{ServiceName: "onerror-test"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	var levels []logging.LogLevel
+	logger.OnError(func(e *logging.Entry) {
+		levels = append(levels, e.Level)
+	})
+	logger.AddHook(func(e *logging.Entry) bool {
+		return e.Meta.Path != "/ignored"
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(404) })
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(500) })
+	mux.HandleFunc("/ignored", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(500) })
+	handler := middleware.HTTPMiddleware(logger)(middleware.HTTPLogger(logger)(mux))
+
+	for _, p := range []string{"/ok", "/missing", "/broken", "/ignored"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", p, nil))
+	}
+
+	if len(levels) != 2 || levels[0] != logging.LevelError || levels[1] != logging.LevelCritical {
+		t.Errorf("Expected [ERROR CRITICAL], got %v", levels)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestLokiStaticLabels verifies configured labels are injected into every Loki entry
+func TestLokiStaticLabels(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/labels-test.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "labels-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "labels-test",
+		EnableFile:     true,
+		EnableLoki:     true,
+		EnableRotation: false,
+		Labels: map[string]string{
+			"pod":    "api-7f9c",
+			"region": "ap-southeast-1",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "labels-001", Method: "GET", Path: "/labels"})
+	logger.LogRequest(ctx, 200, 10*time.Millisecond)
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("labels test error"))
+
+	time.Sleep(100 * time.Millisecond)
+
+	content, err := os.ReadFile(basicLogDir + "/labels-test.loki.log")
+	if err != nil {
+		t.Fatalf("Failed to read Loki log: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 Loki entries, got %d", len(lines))
+	}
+
+	for i, line := range lines {
+		var entry struct {
+			Labels map[string]string `json:"labels"`
+			Errors *ErrorDetail      `json:"errors"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to parse line %d: %v", i, err)
+		}
+
+		if entry.Labels["pod"] != "api-7f9c" || entry.Labels["region"] != "ap-southeast-1" {
+			t.Errorf("Line %d: expected static labels, got %v", i, entry.Labels)
+		}
+
+		if entry.Errors != nil && entry.Errors.Source["file"] != "labels_test.go" {
+			t.Errorf("Line %d: expected error source labels_test.go, got %v", i, entry.Errors.Source["file"])
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestLatencyMicroseconds verifies sub-millisecond requests keep their latency in latency_us
+func TestLatencyMicroseconds(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/latency-us.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "latency-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "latency-us",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "req-fast", Method: "GET", Path: "/ping"})
+	logger.LogRequest(ctx, 200, 153*time.Microsecond)
+	logger.Close()
+
+	entry := readSingleLokiEntry(t, basicLogDir+"/latency-us.loki.log")
+	if entry.LatencyMS != 0 || entry.LatencyUS != 153 {
+		t.Errorf("Expected latency_ms 0 and latency_us 153, got %d and %d", entry.LatencyMS, entry.LatencyUS)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+)
+
+// TestLevelMapper verifies a custom status mapping changes entry levels and suppresses alerts
+func TestLevelMapper(t *testing.T) {
+	var alertsSent atomic.Int32
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		alertsSent.Add(1)
+	}))
+	defer webhook.Close()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "level-mapper-test",
+		LevelMapper: func(status int) logging.LogLevel {
+			if status == http.StatusNotFound || status == http.StatusUnauthorized {
+				return logging.LevelInfo
+			}
+			return logging.DefaultLevelMapper(status)
+		},
+		Alerts: &logging.AlertsConfig{
+			Enabled:  true,
+			MinLevel: "ERROR",
+			Slack:    &slack.Config{Enabled: true, WebhookURL: webhook.URL},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	levels := map[int]logging.LogLevel{}
+	logger.AddHook(func(e *logging.Entry) bool {
+		levels[e.StatusCode] = e.Level
+		return true
+	})
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "mapper", Method: "GET", Path: "/orders"})
+	logger.LogRequestWithError(ctx, http.StatusNotFound, time.Millisecond, errors.New("order not found"))
+	logger.LogRequestWithError(ctx, http.StatusUnauthorized, time.Millisecond, errors.New("token expired"))
+	logger.LogRequestWithError(ctx, http.StatusBadRequest, time.Millisecond, errors.New("bad input"))
+	logger.Close()
+
+	want := map[int]logging.LogLevel{404: logging.LevelInfo, 401: logging.LevelInfo, 400: logging.LevelError}
+	for status, level := range want {
+		if levels[status] != level {
+			t.Errorf("Expected %d to log at %s, got %s", status, level, levels[status])
+		}
+	}
+	if n := alertsSent.Load(); n != 1 {
+		t.Errorf("Expected only the 400 to alert, got %d alerts", n)
+	}
+	if got := logger.LevelForStatus(http.StatusBadGateway); got != logging.LevelCritical {
+		t.Errorf("Expected default mapping for 502, got %s", got)
+	}
+}
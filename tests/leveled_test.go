@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestContextWarnAndDebug verifies Warn and Debug carry request meta and omit status_code in Loki
+func TestContextWarnAndDebug(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	for _, stream := range []string{"access", "debug", "loki"} {
+		os.Remove(basicLogDir + "/leveled-test." + stream + ".log")
+	}
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "leveled-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "leveled-test",
+		EnableFile:     true,
+		EnableRotation: false,
+		MinLevel:       logging.LevelDebug,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{
+		RequestID: "req-leveled",
+		Method:    "GET",
+		Path:      "/inventory",
+	})
+	logger.Warn(ctx, "stock below threshold")
+	logger.Debug(ctx, "cache miss for sku-9")
+	logger.Close()
+
+	access, _ := os.ReadFile(basicLogDir + "/leveled-test.access.log")
+	if !strings.Contains(string(access), "[WARN] [REQ:req-leveled] GET     /inventory | stock below threshold") {
+		t.Errorf("Expected warning with request meta in access log, got %q", access)
+	}
+	if !strings.Contains(string(access), "leveled_test.go:") {
+		t.Error("Expected caller file in access log")
+	}
+
+	debug, _ := os.ReadFile(basicLogDir + "/leveled-test.debug.log")
+	if !strings.Contains(string(debug), "[DEBUG] [REQ:req-leveled] GET     /inventory | cache miss for sku-9") {
+		t.Errorf("Expected debug line with request meta, got %q", debug)
+	}
+
+	content, err := os.ReadFile(basicLogDir + "/leveled-test.loki.log")
+	if err != nil {
+		t.Fatalf("Failed to read Loki log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 Loki entries, got %d", len(lines))
+	}
+
+	for i, want := range []struct{ level, message string }{
+		{"WARN", "stock below threshold"},
+		{"DEBUG", "cache miss for sku-9"},
+	} {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			t.Fatalf("Failed to parse Loki line: %v", err)
+		}
+		if entry["level"] != want.level || entry["message"] != want.message {
+			t.Errorf("Expected %s %q, got %v %v", want.level, want.message, entry["level"], entry["message"])
+		}
+		if entry["request_id"] != "req-leveled" {
+			t.Errorf("Expected request_id, got %v", entry["request_id"])
+		}
+		if _, ok := entry["status_code"]; ok {
+			t.Error("status_code should be omitted for leveled messages")
+		}
+	}
+}
+
+// TestInfoMinLevel verifies Info honours MinLevel like Warn and Debug
+func TestInfoMinLevel(t *testing.T) {
+	for _, minLevel := range []logging.LogLevel{logging.LevelInfo, logging.LevelWarn} {
+		dir := t.TempDir()
+		logger, err := logging.New(&logging.Config{
+			ServiceName:    "info-level-test",
+			LogPath:        dir,
+			FilePrefix:     "info-level",
+			EnableFile:     true,
+			EnableRotation: false,
+			MinLevel:       minLevel,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		logger.Info("INFO LEVEL TEST: started")
+		logger.Warn(t.Context(), "INFO LEVEL TEST: slow start")
+		logger.Close()
+
+		access, _ := os.ReadFile(dir + "/info-level.access.log")
+		logged := strings.Contains(string(access), "[INFO] INFO LEVEL TEST: started")
+		if logged != (minLevel == logging.LevelInfo) {
+			t.Errorf("MinLevel %s: unexpected info output %q", minLevel, access)
+		}
+		if !strings.Contains(string(access), "[WARN] INFO LEVEL TEST: slow start") {
+			t.Errorf("MinLevel %s: expected warn line, got %q", minLevel, access)
+		}
+	}
+}
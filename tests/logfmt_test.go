@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestLogfmtStreams verifies access and Loki streams can be switched to logfmt independently
+func TestLogfmtStreams(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/logfmt-test.access.log")
+	os.Remove(basicLogDir + "/logfmt-test.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:     "logfmt-test",
+		LogPath:         basicLogDir,
+		FilePrefix:      "logfmt-test",
+		EnableFile:      true,
+		EnableRotation:  false,
+		AccessLogFormat: logging.FormatLogfmt,
+		LokiFormat:      logging.FormatLogfmt,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{
+		RequestID: "req-logfmt",
+		IP:        "10.0.0.1",
+		Method:    "POST",
+		Path:      "/orders",
+		UserAgent: "curl/8.0",
+	})
+	logger.LogRequestWithError(ctx, 422, 15*time.Millisecond, errors.New(`upstream said "no"`))
+	logger.Info("ready")
+	logger.Close()
+
+	access, _ := os.ReadFile(basicLogDir + "/logfmt-test.access.log")
+	lines := strings.Split(strings.TrimSpace(string(access)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 access lines, got %d: %q", len(lines), access)
+	}
+	if !strings.HasPrefix(lines[0], "ts=") ||
+		!strings.HasSuffix(lines[0], "level=ERROR request_id=req-logfmt status=422 latency_ms=15 latency_us=15000 ip=10.0.0.1 method=POST path=/orders") {
+		t.Errorf("Unexpected logfmt access line: %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "level=INFO msg=ready") {
+		t.Errorf("Unexpected logfmt info line: %q", lines[1])
+	}
+
+	loki, _ := os.ReadFile(basicLogDir + "/logfmt-test.loki.log")
+	line := strings.TrimSpace(string(loki))
+	if strings.HasPrefix(line, "{") {
+		t.Fatalf("Expected logfmt Loki line, got JSON: %q", line)
+	}
+	for _, want := range []string{
+		"level=ERROR service=logfmt-test ",
+		"http.method=POST",
+		"http.ua=curl/8.0",
+		"status_code=422",
+		`errors.error="upstream said \"no\""`,
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected %q in Loki line %q", want, line)
+		}
+	}
+}
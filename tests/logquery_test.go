@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestLogquery verifies logquery merges one request's entries across streams and rotated files
+func TestLogquery(t *testing.T) {
+	logDir := t.TempDir()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "logquery-test",
+		LogPath:        logDir,
+		FilePrefix:     "logquery-test",
+		EnableFile:     true,
+		EnableRotation: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{
+		RequestID: "req-query",
+		Method:    "PUT",
+		Path:      "/profile",
+	})
+	other := logging.WithMeta(t.Context(), logging.Meta{RequestID: "req-other", Method: "GET", Path: "/"})
+
+	logger.Error(ctx, errors.New("avatar upload failed"))
+	logger.Error(other, errors.New("unrelated failure"))
+	logger.LogRequestWithError(ctx, 500, 8*time.Millisecond, errors.New("avatar upload failed"))
+	logger.LogRequest(other, 200, time.Millisecond)
+	logger.Close()
+
+	rotated := "2020/01/01 10:00:00 logger.go:1: [REQ:req-query] 2020-01-01T10:00:00Z | 200 |   1ms | 10.0.0.1 | GET     /profile\n"
+	if err := os.WriteFile(logDir+"/logquery-test.access-2020-01-01.log", []byte(rotated), 0644); err != nil {
+		t.Fatalf("Failed to write rotated file: %v", err)
+	}
+
+	out, err := exec.Command(
+		"go", "run", "github.com/ahmadsaubani/go-logging-lib/cmd/logquery",
+		"-dir", logDir, "-prefix", "logquery-test", "req-query",
+	).CombinedOutput()
+	if err != nil {
+		t.Fatalf("logquery failed: %v\n%s", err, out)
+	}
+	output := string(out)
+
+	if strings.Contains(output, "req-other") || strings.Contains(output, "unrelated failure") {
+		t.Errorf("Expected only req-query entries:\n%s", output)
+	}
+
+	order := []string{
+		"2020-01-01T10:00:00Z      ACCESS logquery-test.access-2020-01-01.log",
+		"ERROR  logquery-test.error-",
+		"REQ    : req-query",
+		"LOKI   logquery-test.loki-",
+		`"request_id":"req-query"`,
+		"ACCESS logquery-test.access-" + time.Now().Format("2006-01-02"),
+	}
+	pos := 0
+	for _, want := range order {
+		i := strings.Index(output[pos:], want)
+		if i < 0 {
+			t.Fatalf("Expected %q after offset %d in timeline:\n%s", want, pos, output)
+		}
+		pos += i + len(want)
+	}
+
+	if strings.Count(output, "ACCESS logquery-test.access.log") != 0 {
+		t.Errorf("Symlinked current file should not be read twice:\n%s", output)
+	}
+
+	if err := exec.Command(
+		"go", "run", "github.com/ahmadsaubani/go-logging-lib/cmd/logquery",
+		"-dir", logDir, "-prefix", "logquery-test", "req-missing",
+	).Run(); err == nil {
+		t.Error("Expected non-zero exit for unknown request ID")
+	}
+}
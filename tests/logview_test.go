@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestLogview verifies the logview CLI condenses Loki entries and folds stacks
+func TestLogview(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/logview-test.loki.log")
+	os.Remove(basicLogDir + "/logview-test.error.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "logview-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "logview-test",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{
+		RequestID: "req-view",
+		Method:    "GET",
+		Path:      "/health",
+	})
+	logger.LogRequest(ctx, 200, 4*time.Millisecond)
+	logger.ErrorMsg(ctx, "probe failed", errors.New("db unreachable"))
+	logger.Close()
+
+	out, err := exec.Command(
+		"go", "run", "github.com/ahmadsaubani/go-logging-lib/cmd/logview",
+		"-no-color",
+		basicLogDir+"/logview-test.loki.log",
+		basicLogDir+"/logview-test.error.log",
+	).CombinedOutput()
+	if err != nil {
+		t.Fatalf("logview failed: %v\n%s", err, out)
+	}
+	output := string(out)
+
+	for _, want := range []string{
+		"logview-test.loki.log: ",
+		"INFO     200 GET     /health 4ms req=req-view",
+		"ERROR    500 GET     /health 0ms req=req-view",
+		"error: probe failed: db unreachable (logview_test.go:",
+		"logview-test.error.log: MSG    : probe failed",
+		"frames folded (use -stack)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in logview output:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "logview-test.error.log: - ") {
+		t.Errorf("Expected error block stack to be folded:\n%s", output)
+	}
+
+	out, err = exec.Command(
+		"go", "run", "github.com/ahmadsaubani/go-logging-lib/cmd/logview",
+		"-no-color", "-n", "1",
+		basicLogDir+"/logview-test.loki.log",
+	).CombinedOutput()
+	if err != nil {
+		t.Fatalf("logview failed: %v\n%s", err, out)
+	}
+	if strings.Contains(string(out), "INFO") || !strings.Contains(string(out), "ERROR") {
+		t.Errorf("Expected only the last entry with -n 1, got:\n%s", out)
+	}
+}
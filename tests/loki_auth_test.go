@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib/sinks"
+	"github.com/ahmadsaubani/go-logging-lib/sinks/loki"
+)
+
+func pushWithAuth(t *testing.T, auth *sinks.Auth) http.Header {
+	t.Helper()
+
+	headers := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	w := loki.New(&loki.Config{Enabled: true, URL: server.URL, Auth: auth})
+	w.Write([]byte(`{"service":"auth-test","level":"INFO"}` + "\n"))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	w.Close()
+
+	return <-headers
+}
+
+// TestLokiBearerAuthFromEnv verifies bearer tokens and custom headers are expanded from the environment
+func TestLokiBearerAuthFromEnv(t *testing.T) {
+	t.Setenv("LOKI_TEST_TOKEN", "s3cret")
+	t.Setenv("LOKI_TEST_KEY", "key-123")
+
+	h := pushWithAuth(t, &sinks.Auth{
+		BearerToken: "${LOKI_TEST_TOKEN}",
+		Headers:     map[string]string{"X-Api-Key": "$LOKI_TEST_KEY"},
+	})
+
+	if got := h.Get("Authorization"); got != "Bearer s3cret" {
+		t.Errorf("Expected bearer token, got %q", got)
+	}
+	if got := h.Get("X-Api-Key"); got != "key-123" {
+		t.Errorf("Expected custom header, got %q", got)
+	}
+}
+
+// TestLokiBasicAuth verifies username and password are sent as basic auth
+func TestLokiBasicAuth(t *testing.T) {
+	h := pushWithAuth(t, &sinks.Auth{Username: "123456", Password: "glc_token"})
+
+	req := &http.Request{Header: h}
+	user, pass, ok := req.BasicAuth()
+	if !ok || user != "123456" || pass != "glc_token" {
+		t.Errorf("Expected basic auth 123456/glc_token, got %q %q %v", user, pass, ok)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib/sinks/loki"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type capturedPush struct {
+	contentType     string
+	contentEncoding string
+	body            []byte
+}
+
+func captureLokiPushes(t *testing.T, config *loki.Config, lines ...string) []capturedPush {
+	t.Helper()
+
+	var mu sync.Mutex
+	var pushes []capturedPush
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		pushes = append(pushes, capturedPush{
+			contentType:     r.Header.Get("Content-Type"),
+			contentEncoding: r.Header.Get("Content-Encoding"),
+			body:            body,
+		})
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	config.URL = server.URL
+	w := loki.New(config)
+	for _, line := range lines {
+		w.Write([]byte(line + "\n"))
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	w.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(pushes) != 1 {
+		t.Fatalf("Expected 1 push, got %d", len(pushes))
+	}
+	return pushes
+}
+
+// TestLokiGzipEncoding verifies gzip pushes carry compressed JSON
+func TestLokiGzipEncoding(t *testing.T) {
+	push := captureLokiPushes(t, &loki.Config{Enabled: true, Encoding: loki.EncodingGzip},
+		`{"service":"gzip-test","level":"INFO","message":"hello"}`)[0]
+
+	if push.contentType != "application/json" || push.contentEncoding != "gzip" {
+		t.Fatalf("Unexpected headers: %+v", push)
+	}
+
+	gz, err := gzip.NewReader(strings.NewReader(string(push.body)))
+	if err != nil {
+		t.Fatalf("Body is not gzip: %v", err)
+	}
+	var req lokiPushRequest
+	if err := json.NewDecoder(gz).Decode(&req); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+	if len(req.Streams) != 1 || req.Streams[0].Stream["service"] != "gzip-test" {
+		t.Errorf("Unexpected push request: %+v", req)
+	}
+}
+
+// TestLokiSnappyProtobufEncoding verifies snappy pushes carry a protobuf PushRequest
+func TestLokiSnappyProtobufEncoding(t *testing.T) {
+	var lines []string
+	for i := 0; i < 50; i++ {
+		lines = append(lines, fmt.Sprintf(`{"service":"snappy-test","level":"INFO","message":"repeated message body %d"}`, i))
+	}
+	push := captureLokiPushes(t, &loki.Config{Enabled: true, Encoding: loki.EncodingSnappy}, lines...)[0]
+
+	if push.contentType != "application/x-protobuf" {
+		t.Fatalf("Unexpected content type %q", push.contentType)
+	}
+
+	raw, err := snappyDecode(push.body)
+	if err != nil {
+		t.Fatalf("Failed to decode snappy body: %v", err)
+	}
+	if len(push.body) >= len(raw) {
+		t.Errorf("Expected compression, got %d bytes for %d", len(push.body), len(raw))
+	}
+
+	streams := protoFields(t, raw, 1)
+	if len(streams) != 1 {
+		t.Fatalf("Expected 1 stream, got %d", len(streams))
+	}
+	labels := protoFields(t, streams[0], 1)
+	entries := protoFields(t, streams[0], 2)
+	if string(labels[0]) != `{level="INFO", service="snappy-test"}` {
+		t.Errorf("Unexpected labels %s", labels[0])
+	}
+	if len(entries) != 50 {
+		t.Fatalf("Expected 50 entries, got %d", len(entries))
+	}
+	line := protoFields(t, entries[7], 2)
+	if string(line[0]) != `{"message":"repeated message body 7"}` {
+		t.Errorf("Unexpected line %s", line[0])
+	}
+	if ts := protoFields(t, entries[7], 1); len(ts) != 1 {
+		t.Errorf("Expected timestamp on entry")
+	}
+}
+
+// protoFields returns the length-delimited values of one field number
+func protoFields(t *testing.T, b []byte, field protowire.Number) [][]byte {
+	t.Helper()
+
+	var out [][]byte
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("Invalid protobuf tag")
+		}
+		b = b[n:]
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			b = b[n:]
+			continue
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			t.Fatalf("Invalid protobuf bytes")
+		}
+		if num == field {
+			out = append(out, v)
+		}
+		b = b[n:]
+	}
+	return out
+}
+
+// snappyDecode decodes the snappy block format
+func snappyDecode(src []byte) ([]byte, error) {
+	size, n := binary.Uvarint(src)
+	if n <= 0 {
+		return nil, fmt.Errorf("bad length")
+	}
+	src = src[n:]
+	dst := make([]byte, 0, size)
+
+	for len(src) > 0 {
+		tag := src[0]
+		switch tag & 3 {
+		case 0:
+			length := int(tag>>2) + 1
+			src = src[1:]
+			if length > 60 {
+				extra := length - 60
+				length = 0
+				for i := 0; i < extra; i++ {
+					length |= int(src[i]) << (8 * i)
+				}
+				length++
+				src = src[extra:]
+			}
+			dst = append(dst, src[:length]...)
+			src = src[length:]
+		case 2:
+			length := int(tag>>2) + 1
+			offset := int(src[1]) | int(src[2])<<8
+			src = src[3:]
+			if offset == 0 || offset > len(dst) {
+				return nil, fmt.Errorf("bad offset %d", offset)
+			}
+			for i := 0; i < length; i++ {
+				dst = append(dst, dst[len(dst)-offset])
+			}
+		default:
+			return nil, fmt.Errorf("unsupported tag %d", tag&3)
+		}
+	}
+
+	if uint64(len(dst)) != size {
+		return nil, fmt.Errorf("length mismatch: %d != %d", len(dst), size)
+	}
+	return dst, nil
+}
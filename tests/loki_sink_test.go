@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/sinks/loki"
+)
+
+type lokiPushRequest struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][]string        `json:"values"`
+	} `json:"streams"`
+}
+
+// TestLokiSinkLabelKeys verifies LabelKeys are promoted to stream labels and removed from the line
+func TestLokiSinkLabelKeys(t *testing.T) {
+	var mu sync.Mutex
+	var pushes []lokiPushRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req lokiPushRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode push request: %v", err)
+		}
+		mu.Lock()
+		pushes = append(pushes, req)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "loki-sink-test",
+		EnableLoki:  true,
+		Labels:      map[string]string{"pod": "api-1"},
+		Loki: &loki.Config{
+			Enabled:   true,
+			URL:       server.URL,
+			LabelKeys: []string{"service", "level", "labels.pod"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "sink-001", Method: "GET", Path: "/sink"})
+	logger.LogRequest(ctx, 200, 5*time.Millisecond)
+	logger.LogRequest(ctx, 503, 5*time.Millisecond)
+
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(pushes) == 0 {
+		t.Fatal("Expected at least one push request")
+	}
+
+	streams := 0
+	for _, push := range pushes {
+		for _, stream := range push.Streams {
+			streams++
+			if stream.Stream["service"] != "loki-sink-test" || stream.Stream["labels_pod"] != "api-1" {
+				t.Errorf("Unexpected stream labels: %v", stream.Stream)
+			}
+			for _, value := range stream.Values {
+				if strings.Contains(value[1], `"service"`) || strings.Contains(value[1], `"level"`) {
+					t.Errorf("Label fields should be removed from line: %s", value[1])
+				}
+				if !strings.Contains(value[1], `"request_id":"sink-001"`) {
+					t.Errorf("Expected request_id to stay in line: %s", value[1])
+				}
+			}
+		}
+	}
+
+	if streams != 2 {
+		t.Errorf("Expected 2 streams (INFO and CRITICAL), got %d", streams)
+	}
+}
+
+// TestLokiSinkFlush verifies Flush pushes queued entries without closing the logger
+func TestLokiSinkFlush(t *testing.T) {
+	var mu sync.Mutex
+	lines := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req lokiPushRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		for _, stream := range req.Streams {
+			lines += len(stream.Values)
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "loki-flush-test",
+		EnableLoki:  true,
+		Loki:        &loki.Config{Enabled: true, URL: server.URL},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "flush-001"})
+	logger.LogRequest(ctx, 200, time.Millisecond)
+	logger.LogRequest(ctx, 201, time.Millisecond)
+
+	if err := logger.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if lines != 2 {
+		t.Errorf("Expected 2 lines pushed by Flush, got %d", lines)
+	}
+}
+
+// TestLokiSinkBatchingAndRetries verifies BatchSize triggers pushes and transient failures are retried
+func TestLokiSinkBatchingAndRetries(t *testing.T) {
+	var mu sync.Mutex
+	var sizes []int
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts++
+		if attempts <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var req lokiPushRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		n := 0
+		for _, stream := range req.Streams {
+			n += len(stream.Values)
+		}
+		sizes = append(sizes, n)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	w := loki.New(&loki.Config{
+		Enabled:       true,
+		URL:           server.URL,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		MaxRetries:    2,
+	})
+
+	for i := 0; i < 5; i++ {
+		w.Write([]byte(`{"service":"batch-test","level":"INFO","message":"entry"}` + "\n"))
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Expected retried pushes to succeed: %v", err)
+	}
+	w.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 5 {
+		t.Errorf("Expected 5 push attempts (2 retried), got %d", attempts)
+	}
+	if len(sizes) != 3 || sizes[0] != 2 || sizes[1] != 2 || sizes[2] != 1 {
+		t.Errorf("Expected batches of 2, 2 and 1, got %v", sizes)
+	}
+	if st := w.Stats(); st.Pushed != 5 || st.Failed != 0 {
+		t.Errorf("Unexpected stats: %+v", st)
+	}
+}
+
+// TestLokiSinkWriteAfterClose verifies writes racing or following Close are dropped and reported instead of panicking
+func TestLokiSinkWriteAfterClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	w := loki.New(&loki.Config{Enabled: true, URL: server.URL})
+
+	var mu sync.Mutex
+	var reported []string
+	w.SetErrorHandler(func(source string, err error) {
+		mu.Lock()
+		reported = append(reported, source+": "+err.Error())
+		mu.Unlock()
+	})
+
+	line := []byte(`{"service":"close-test","level":"INFO","message":"entry"}` + "\n")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				w.Write(line)
+			}
+		}()
+	}
+	w.Close()
+	wg.Wait()
+
+	before := w.Stats().Dropped
+	if n, err := w.Write(line); n != len(line) || err != nil {
+		t.Errorf("Expected Write after Close to return (%d, nil), got (%d, %v)", len(line), n, err)
+	}
+	if got := w.Stats().Dropped; got != before+1 {
+		t.Errorf("Expected the write after Close to be counted as dropped, got %d -> %d", before, got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) == 0 || !strings.Contains(reported[len(reported)-1], "writer closed") {
+		t.Errorf("Expected a 'writer closed' report, got %v", reported)
+	}
+}
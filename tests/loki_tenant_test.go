@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/sinks/loki"
+)
+
+// tenantServer counts pushed entries per X-Scope-OrgID
+func tenantServer(t *testing.T, healthy *atomic.Bool) (*httptest.Server, func() map[string]int) {
+	var mu sync.Mutex
+	counts := make(map[string]int)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if healthy != nil && !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var req lokiPushRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		for _, stream := range req.Streams {
+			counts[r.Header.Get("X-Scope-OrgID")] += len(stream.Values)
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() map[string]int {
+		mu.Lock()
+		defer mu.Unlock()
+		out := make(map[string]int, len(counts))
+		for k, v := range counts {
+			out[k] = v
+		}
+		return out
+	}
+}
+
+// TestLokiTenantHeader verifies the configured tenant and per-logger overrides reach Loki
+func TestLokiTenantHeader(t *testing.T) {
+	server, counts := tenantServer(t, nil)
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "tenant-test",
+		EnableLoki:  true,
+		Loki:        &loki.Config{Enabled: true, URL: server.URL, TenantID: "platform"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "tenant", Method: "GET", Path: "/orders"})
+	logger.LogRequest(ctx, 200, time.Millisecond)
+	acme := logger.WithTenant("acme")
+	acme.LogRequest(ctx, 200, time.Millisecond)
+	acme.LogRequest(ctx, 201, time.Millisecond)
+	logger.Close()
+
+	got := counts()
+	if got["platform"] != 1 || got["acme"] != 2 || len(got) != 2 {
+		t.Errorf("Unexpected entries per tenant: %v", got)
+	}
+}
+
+// TestLokiTenantSurvivesSpill verifies replayed entries keep their tenant
+func TestLokiTenantSurvivesSpill(t *testing.T) {
+	var healthy atomic.Bool
+	server, counts := tenantServer(t, &healthy)
+
+	w := loki.New(&loki.Config{Enabled: true, URL: server.URL, SpillDir: t.TempDir(), MaxRetries: -1})
+	w.SetErrorHandler(func(string, error) {})
+
+	w.WriteTenant("acme", []byte(`{"service":"tenant-test","level":"INFO"}`+"\n"))
+	w.Write([]byte(`{"service":"tenant-test","level":"INFO"}` + "\n"))
+	if err := w.Flush(); err == nil {
+		t.Fatal("Expected flush to fail while Loki is down")
+	}
+
+	healthy.Store(true)
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Expected replay to succeed: %v", err)
+	}
+	w.Close()
+
+	got := counts()
+	if got["acme"] != 1 || got[""] != 1 {
+		t.Errorf("Unexpected entries per tenant after replay: %v", got)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/matrix"
+)
+
+// TestMatrixAlerter verifies the room message event and a stable transaction ID across retries
+func TestMatrixAlerter(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	var event map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("Authorization") != "Bearer syt_token" {
+			t.Errorf("Unexpected request %s with auth %q", r.Method, r.Header.Get("Authorization"))
+		}
+		mu.Lock()
+		paths = append(paths, r.URL.EscapedPath())
+		json.NewDecoder(r.Body).Decode(&event)
+		mu.Unlock()
+		w.Write([]byte(`{"event_id":"$abc"}`))
+	}))
+	defer server.Close()
+
+	alerter := matrix.New(&matrix.Config{
+		HomeserverURL: server.URL + "/",
+		AccessToken:   "syt_token",
+		RoomID:        "!ops:example.org",
+	})
+	payload := alerts.Payload{
+		ServiceName: "orders-api",
+		Level:       "CRITICAL",
+		Error:       "db <primary> unreachable",
+		RequestID:   "req-9",
+		Excerpt:     "ERROR  : db <primary> unreachable",
+		Timestamp:   time.Now(),
+	}
+	for range 2 {
+		if err := alerter.Send(t.Context(), payload); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	prefix := "/_matrix/client/v3/rooms/%21ops:example.org/send/m.room.message/"
+	if len(paths) != 2 || !strings.HasPrefix(paths[0], prefix) || paths[0] != paths[1] {
+		t.Errorf("Expected two sends with the same transaction ID under %s, got %q", prefix, paths)
+	}
+	if event["msgtype"] != "m.text" || event["format"] != "org.matrix.custom.html" {
+		t.Errorf("Unexpected event %v", event)
+	}
+	if !strings.Contains(event["formatted_body"], "db &lt;primary&gt; unreachable") {
+		t.Errorf("Expected escaped HTML body, got %q", event["formatted_body"])
+	}
+	if !strings.Contains(event["formatted_body"], "<details><summary>Log Excerpt</summary>") {
+		t.Errorf("Expected collapsed excerpt, got %q", event["formatted_body"])
+	}
+	if !strings.Contains(event["body"], "Request ID: req-9") {
+		t.Errorf("Expected plain body with details, got %q", event["body"])
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/metrics"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TestLatencyMetrics verifies per-route histograms are recorded and exported
+func TestLatencyMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger, err := logging.New(&logging.Config{ServiceName: "metrics-test"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	recorder := metrics.NewRecorder(0.1, 1)
+
+	r := gin.New()
+	r.Use(middleware.GinMiddleware(logger))
+	r.Use(middleware.GinLoggerWithConfig(logger, middleware.LoggerConfig{
+		Rules:   []middleware.RouteRule{{Pattern: "/users/:id", SampleRate: 0.0001}},
+		Metrics: recorder,
+	}))
+	r.GET("/users/:id", func(c *gin.Context) { c.String(200, "ok") })
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/2", nil))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders/{id}", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(500) })
+	handler := middleware.HTTPMiddleware(logger)(middleware.HTTPLoggerWithConfig(logger, middleware.LoggerConfig{
+		Metrics: recorder,
+	})(mux))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders/9", nil))
+
+	rec := httptest.NewRecorder()
+	recorder.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	expected := []string{
+		"# TYPE http_request_duration_seconds histogram",
+		`http_request_duration_seconds_count{method="GET",route="/users/:id",status_class="2xx"} 2`,
+		`http_request_duration_seconds_bucket{method="GET",route="/users/:id",status_class="2xx",le="+Inf"} 2`,
+		`http_request_duration_seconds_count{method="GET",route="GET /orders/{id}",status_class="5xx"} 1`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("Expected %q in metrics output:\n%s", line, body)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+	"github.com/gorilla/mux"
+)
+
+// TestMuxRouteTemplate verifies the gorilla/mux path template is recorded in Loki entries
+func TestMuxRouteTemplate(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/mux-test.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "mux-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "mux-test",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	router := mux.NewRouter()
+	router.Use(middleware.MuxMiddleware(logger))
+	router.Use(middleware.HTTPLogger(logger))
+	router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/users/42", nil))
+	logger.Close()
+
+	if rec.Header().Get("X-Request-ID") == "" {
+		t.Error("Expected X-Request-ID response header")
+	}
+	if rec.Header().Get("X-Correlation-ID") == "" {
+		t.Error("Expected generated X-Correlation-ID response header")
+	}
+
+	entry := readSingleLokiEntry(t, basicLogDir+"/mux-test.loki.log")
+	if entry.HTTP["route"] != "/users/{id}" {
+		t.Errorf("Expected route template /users/{id}, got %q", entry.HTTP["route"])
+	}
+	if entry.CorrelationID != rec.Header().Get("X-Correlation-ID") {
+		t.Errorf("Expected correlation_id %q, got %q", rec.Header().Get("X-Correlation-ID"), entry.CorrelationID)
+	}
+	if entry.HTTP["path"] != "/users/42" {
+		t.Errorf("Expected raw path /users/42, got %q", entry.HTTP["path"])
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TestAlwaysAlertPanics verifies panics are alerted as CRITICAL past MinLevel and rate limiting
+func TestAlwaysAlertPanics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/panic-alert.loki.log")
+
+	messages := make(chan map[string]any, 10)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]any
+		json.NewDecoder(r.Body).Decode(&msg)
+		messages <- msg
+	}))
+	defer webhook.Close()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "panic-alert-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "panic-alert",
+		EnableFile:     true,
+		EnableRotation: false,
+		Alerts: &logging.AlertsConfig{
+			Enabled:           true,
+			MinLevel:          "CRITICAL",
+			RateLimitSec:      300,
+			AlwaysAlertPanics: true,
+			Slack:             &slack.Config{Enabled: true, WebhookURL: webhook.URL},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	engine := gin.New()
+	engine.Use(middleware.GinMiddleware(logger), middleware.GinLogger(logger), middleware.GinRecovery(logger))
+	engine.GET("/crash", func(c *gin.Context) { panic("nil order") })
+	engine.GET("/fail", func(c *gin.Context) {
+		c.Error(errors.New("invalid order"))
+		c.Status(400)
+	})
+
+	for _, path := range []string{"/crash", "/crash", "/fail"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	logger.Close()
+
+	if got := len(messages); got != 2 {
+		t.Errorf("Expected both panics and no ERROR to be alerted, got %d alerts", got)
+	}
+
+	content, _ := os.ReadFile(basicLogDir + "/panic-alert.loki.log")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 Loki entries, got %d", len(lines))
+	}
+	for i, want := range []string{"CRITICAL", "CRITICAL", "ERROR"} {
+		var entry LokiLogEntry
+		json.Unmarshal([]byte(lines[i]), &entry)
+		if entry.Level != want {
+			t.Errorf("Entry %d: expected level %s, got %s", i, want, entry.Level)
+		}
+	}
+}
+
+// TestPanicAlertsRateLimitedByDefault verifies repeated panics stay rate limited without the flag
+func TestPanicAlertsRateLimitedByDefault(t *testing.T) {
+	messages := make(chan map[string]any, 10)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		messages <- nil
+	}))
+	defer webhook.Close()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "panic-alert-default-test",
+		Alerts: &logging.AlertsConfig{
+			Enabled:  true,
+			MinLevel: "ERROR",
+			Slack:    &slack.Config{Enabled: true, WebhookURL: webhook.URL},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /crash", func(w http.ResponseWriter, r *http.Request) { panic("nil order") })
+	handler := middleware.HTTPMiddleware(logger)(middleware.HTTPLogger(logger)(middleware.HTTPRecovery(logger)(mux)))
+	for range 2 {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/crash", nil))
+	}
+	logger.Close()
+
+	select {
+	case <-messages:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the first panic to be alerted")
+	}
+	if got := len(messages); got != 0 {
+		t.Errorf("Expected the repeated panic to be rate limited, got %d extra alerts", got)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+)
+
+// TestOutboundPropagation verifies request ID and trace headers reach downstream services
+func TestOutboundPropagation(t *testing.T) {
+	logger, err := logging.New(&logging.Config{ServiceName: "propagation-test"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	var downstream http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downstream = r.Header.Clone()
+	}))
+	defer backend.Close()
+
+	client := &http.Client{Transport: logging.Transport(nil)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/checkout", func(w http.ResponseWriter, r *http.Request) {
+		req, _ := http.NewRequestWithContext(r.Context(), "GET", backend.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Downstream request failed: %v", err)
+			return
+		}
+		resp.Body.Close()
+
+		if req.Header.Get("X-Request-ID") != "" {
+			t.Error("Transport must not modify the caller's request")
+		}
+	})
+
+	req := httptest.NewRequest("GET", "/checkout", nil)
+	req.Header.Set("X-Request-ID", "upstream-req-7")
+	req.Header.Set("X-Correlation-ID", "checkout-flow-1")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	middleware.HTTPMiddleware(logger)(mux).ServeHTTP(httptest.NewRecorder(), req)
+
+	if downstream.Get("X-Request-ID") != "upstream-req-7" {
+		t.Errorf("Expected X-Request-ID forwarded, got %q", downstream.Get("X-Request-ID"))
+	}
+	if downstream.Get("X-Correlation-ID") != "checkout-flow-1" {
+		t.Errorf("Expected X-Correlation-ID forwarded, got %q", downstream.Get("X-Correlation-ID"))
+	}
+	if downstream.Get("traceparent") != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("Expected traceparent forwarded, got %q", downstream.Get("traceparent"))
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+	"github.com/ahmadsaubani/go-logging-lib/sinks/loki"
+)
+
+// proxyServer answers every proxied request itself and records the target URLs
+func proxyServer(t *testing.T) (*httptest.Server, chan string) {
+	targets := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		targets <- r.URL.String()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, targets
+}
+
+// TestLokiExplicitProxy verifies pushes are sent through the configured proxy
+func TestLokiExplicitProxy(t *testing.T) {
+	proxy, targets := proxyServer(t)
+
+	w := loki.New(&loki.Config{Enabled: true, URL: "http://loki.example.invalid/loki/api/v1/push", Proxy: proxy.URL})
+	w.Write([]byte(`{"service":"proxy-test","level":"INFO"}` + "\n"))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Expected push through proxy to succeed: %v", err)
+	}
+	w.Close()
+
+	if got := <-targets; got != "http://loki.example.invalid/loki/api/v1/push" {
+		t.Errorf("Unexpected proxied URL %q", got)
+	}
+}
+
+// TestSlackExplicitProxy verifies webhooks use the configured proxy unless told to go direct
+func TestSlackExplicitProxy(t *testing.T) {
+	proxy, targets := proxyServer(t)
+
+	payload := alerts.Payload{ServiceName: "proxy-test", Level: "ERROR", Error: "boom"}
+	a := slack.New(&slack.Config{Enabled: true, WebhookURL: "http://hooks.example.invalid/services/x", Proxy: proxy.URL})
+	if err := a.Send(t.Context(), payload); err != nil {
+		t.Fatalf("Expected webhook through proxy to succeed: %v", err)
+	}
+	if got := <-targets; got != "http://hooks.example.invalid/services/x" {
+		t.Errorf("Unexpected proxied URL %q", got)
+	}
+
+	direct := slack.New(&slack.Config{Enabled: true, WebhookURL: "http://hooks.example.invalid/services/x", Proxy: "direct"})
+	if err := direct.Send(t.Context(), payload); err == nil {
+		t.Error("Expected direct send to an unresolvable host to fail")
+	}
+	if len(targets) != 0 {
+		t.Error("Expected direct send to bypass the proxy")
+	}
+}
+
+// TestInvalidProxyFailsNew verifies an unparsable proxy URL is reported by New
+func TestInvalidProxyFailsNew(t *testing.T) {
+	_, err := logging.New(&logging.Config{
+		ServiceName: "proxy-test",
+		Loki:        &loki.Config{Proxy: "proxy.internal:3128"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "loki proxy") {
+		t.Errorf("Expected loki proxy error, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TestCustomPanicResponse verifies recovery middlewares render the configured panic response
+func TestCustomPanicResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger, err := logging.New(&logging.Config{ServiceName: "recovery-test"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	r := gin.New()
+	r.Use(middleware.GinMiddleware(logger))
+	r.Use(middleware.GinRecoveryWithHandler(logger, func(c *gin.Context, err error) {
+		meta, _ := logging.FromContext(c.Request.Context())
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "unavailable", "request_id": meta.RequestID})
+	}))
+	r.GET("/boom", func(c *gin.Context) { panic("db down") })
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/boom", nil)
+	req.Header.Set("X-Request-ID", "gin-panic-1")
+	r.ServeHTTP(rec, req)
+
+	var body map[string]string
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if rec.Code != http.StatusServiceUnavailable || body["request_id"] != "gin-panic-1" {
+		t.Errorf("Unexpected Gin panic response: %d %s", rec.Code, rec.Body.String())
+	}
+
+	var recorded error
+	mux := http.NewServeMux()
+	mux.HandleFunc("/boom", func(w http.ResponseWriter, r *http.Request) { panic("cache down") })
+	handler := middleware.HTTPMiddleware(logger)(middleware.HTTPRecoveryWithHandler(logger,
+		func(w http.ResponseWriter, r *http.Request, err error) {
+			recorded = err
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"internal"}`))
+		})(mux))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/boom", nil))
+
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), `"internal"`) {
+		t.Errorf("Unexpected HTTP panic response: %d %s", rec.Code, rec.Body.String())
+	}
+	if recorded == nil || !strings.Contains(recorded.Error(), "cache down") {
+		t.Errorf("Expected panic error passed to handler, got %v", recorded)
+	}
+}
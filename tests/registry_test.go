@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/sinks/loki"
+)
+
+// TestRegistry verifies named loggers share writers but keep separate service names
+func TestRegistry(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/registry-test.access.log")
+	os.Remove(basicLogDir + "/registry-test.loki.log")
+
+	registry, err := logging.NewRegistry(&logging.Config{
+		ServiceName:    "registry-base",
+		LogPath:        basicLogDir,
+		FilePrefix:     "registry-test",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+
+	acme := registry.Get("tenant-acme")
+	if registry.Get("tenant-acme") != acme {
+		t.Error("Expected Get to return the same logger for the same name")
+	}
+	globex := registry.Get("tenant-globex")
+
+	acme.Info("REGISTRY TEST: acme message")
+	globex.Info("REGISTRY TEST: globex message")
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "registry-001"})
+	acme.LogRequest(ctx, 200, time.Millisecond)
+	globex.LogRequest(ctx, 200, time.Millisecond)
+
+	if names := registry.Names(); len(names) != 2 || names[0] != "tenant-acme" {
+		t.Errorf("Unexpected registry names: %v", names)
+	}
+
+	if err := registry.Close(); err != nil {
+		t.Fatalf("Failed to close registry: %v", err)
+	}
+
+	access, err := os.ReadFile(basicLogDir + "/registry-test.access.log")
+	if err != nil {
+		t.Fatalf("Failed to read access log: %v", err)
+	}
+	if !strings.Contains(string(access), "[tenant-acme] [INFO] REGISTRY TEST: acme message") {
+		t.Error("Expected acme prefixed message in shared access log")
+	}
+	if !strings.Contains(string(access), "[tenant-globex] [INFO] REGISTRY TEST: globex message") {
+		t.Error("Expected globex prefixed message in shared access log")
+	}
+
+	loki, err := os.ReadFile(basicLogDir + "/registry-test.loki.log")
+	if err != nil {
+		t.Fatalf("Failed to read Loki log: %v", err)
+	}
+
+	services := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(string(loki)), "\n") {
+		var entry LokiLogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to parse Loki line: %v", err)
+		}
+		services[entry.Service] = true
+	}
+	if !services["tenant-acme"] || !services["tenant-globex"] {
+		t.Errorf("Expected both tenant services in shared Loki log, got %v", services)
+	}
+}
+
+// TestRegistryCSVAccessLog verifies named loggers do not prefix CSV access rows
+func TestRegistryCSVAccessLog(t *testing.T) {
+	dir := t.TempDir()
+
+	registry, err := logging.NewRegistry(&logging.Config{
+		ServiceName:      "registry-base",
+		LogPath:          dir,
+		FilePrefix:       "registry-csv",
+		EnableFile:       true,
+		EnableRotation:   false,
+		AccessLogFormat:  logging.FormatCSV,
+		AccessLogColumns: []string{"level", "status", "path", "msg"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+
+	acme := registry.Get("tenant-acme")
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "registry-csv-001", Path: "/orders"})
+	acme.LogRequest(ctx, 200, time.Millisecond)
+	acme.Info("csv message")
+
+	if err := registry.Close(); err != nil {
+		t.Fatalf("Failed to close registry: %v", err)
+	}
+
+	f, err := os.Open(dir + "/registry-csv.access.log")
+	if err != nil {
+		t.Fatalf("Failed to open access log: %v", err)
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("Access log is not valid CSV: %v", err)
+	}
+	want := [][]string{
+		{"level", "status", "path", "msg"},
+		{"INFO", "200", "/orders", ""},
+		{"INFO", "", "", "csv message"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Expected %d rows, got %d: %v", len(want), len(rows), rows)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("Row %d column %s: expected %q, got %q", i, want[0][j], want[i][j], rows[i][j])
+			}
+		}
+	}
+}
+
+// TestRegistryDrain verifies Drain on a named logger pushes pending Loki entries and Close leaves the shared writers open
+func TestRegistryDrain(t *testing.T) {
+	var mu sync.Mutex
+	lines := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req lokiPushRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		for _, stream := range req.Streams {
+			lines += len(stream.Values)
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	registry, err := logging.NewRegistry(&logging.Config{
+		ServiceName: "registry-base",
+		EnableLoki:  true,
+		Loki:        &loki.Config{Enabled: true, URL: server.URL, FlushInterval: time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	defer registry.Close()
+
+	acme := registry.Get("tenant-acme")
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "registry-drain-001"})
+
+	acme.LogRequest(ctx, 200, time.Millisecond)
+	if err := acme.Drain(ctx); err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	mu.Lock()
+	if lines != 1 {
+		t.Errorf("Expected Drain to push 1 line, got %d", lines)
+	}
+	mu.Unlock()
+
+	if err := acme.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	registry.Get("tenant-globex").LogRequest(ctx, 200, time.Millisecond)
+	if err := registry.Base().Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if lines != 2 {
+		t.Errorf("Expected the shared sink to keep pushing after a named logger's Close, got %d lines", lines)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestReportCountsRequestsAndTopErrors verifies the digest counters and the webhook delivery
+func TestReportCountsRequestsAndTopErrors(t *testing.T) {
+	received := make(chan logging.Report, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report logging.Report
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			t.Errorf("Failed to decode report: %v", err)
+		}
+		received <- report
+	}))
+	defer server.Close()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "report-test",
+		Environment: "staging",
+		Report:      &logging.ReportConfig{WebhookURL: server.URL, TopErrors: 1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "report", Method: "GET", Path: "/orders"})
+	for i := 1; i <= 18; i++ {
+		logger.LogRequest(ctx, 200, time.Duration(i)*time.Millisecond)
+	}
+	logger.LogRequestWithError(ctx, 500, 100*time.Millisecond, errors.New("db down"))
+	logger.LogRequestWithError(ctx, 503, 200*time.Millisecond, errors.New("db down"))
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("cache miss"))
+
+	report := logger.Report()
+	if report.Requests != 20 || report.Errors != 2 {
+		t.Fatalf("Expected 20 requests with 2 errors, got %d/%d", report.Requests, report.Errors)
+	}
+	if report.ErrorRate != 0.1 {
+		t.Errorf("Expected error rate 0.1, got %v", report.ErrorRate)
+	}
+	if report.P95LatencyMs != 100 {
+		t.Errorf("Expected p95 of 100ms, got %d", report.P95LatencyMs)
+	}
+	if len(report.TopErrors) != 1 || report.TopErrors[0] != (logging.ReportError{Message: "db down", Count: 2}) {
+		t.Errorf("Expected only the top error, got %+v", report.TopErrors)
+	}
+
+	if err := logger.SendReport(t.Context()); err != nil {
+		t.Fatalf("SendReport failed: %v", err)
+	}
+	select {
+	case sent := <-received:
+		if sent.Service != "report-test" || sent.Environment != "staging" || sent.Requests != 20 {
+			t.Errorf("Unexpected webhook report: %+v", sent)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Report was not posted")
+	}
+
+	if after := logger.Report(); after.Requests != 0 || len(after.TopErrors) != 0 {
+		t.Errorf("Expected a new period after sending, got %+v", after)
+	}
+}
+
+// TestReportRequiresEmailSettings verifies email reports are rejected without SMTP config
+func TestReportRequiresEmailSettings(t *testing.T) {
+	_, err := logging.New(&logging.Config{
+		ServiceName: "report-test",
+		Report:      &logging.ReportConfig{Email: true},
+	})
+	if err == nil {
+		t.Fatal("Expected error for email report without alerts.email")
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TestPanicRequestDump verifies recovered panics log and alert a redacted dump of the request
+func TestPanicRequestDump(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/request-dump.error.log")
+
+	messages := make(chan map[string]any, 2)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]any
+		json.NewDecoder(r.Body).Decode(&msg)
+		messages <- msg
+	}))
+	defer webhook.Close()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:        "request-dump-test",
+		LogPath:            basicLogDir,
+		FilePrefix:         "request-dump",
+		EnableFile:         true,
+		EnableRotation:     false,
+		DumpRequestOnPanic: true,
+		RedactHeaders:      []string{"X-Session"},
+		Alerts: &logging.AlertsConfig{
+			Enabled:  true,
+			MinLevel: "ERROR",
+			Slack:    &slack.Config{Enabled: true, WebhookURL: webhook.URL},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	crash := func(body io.Reader) {
+		io.ReadAll(body)
+		panic("nil order")
+	}
+
+	engine := gin.New()
+	engine.Use(middleware.GinMiddleware(logger), middleware.GinLogger(logger), middleware.GinRecovery(logger))
+	engine.POST("/orders", func(c *gin.Context) { crash(c.Request.Body) })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /refunds", func(w http.ResponseWriter, r *http.Request) { crash(r.Body) })
+	handler := middleware.HTTPMiddleware(logger)(middleware.HTTPLogger(logger)(middleware.HTTPRecovery(logger)(mux)))
+
+	for _, serve := range []struct {
+		h    http.Handler
+		path string
+	}{{engine, "/orders"}, {handler, "/refunds"}} {
+		req := httptest.NewRequest("POST", serve.path, strings.NewReader(`{"sku":"A-1"}`))
+		req.Header.Set("Authorization", "Bearer secret-token")
+		req.Header.Set("X-Session", "secret-session")
+		req.Header.Set("X-Tenant", "acme")
+		serve.h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	logger.Close()
+
+	errorLog, _ := os.ReadFile(basicLogDir + "/request-dump.error.log")
+	requests := []string{string(errorLog)}
+	for range 2 {
+		requests = append(requests, slackFields(<-messages)["Request"])
+	}
+
+	for i, dump := range requests {
+		for _, want := range []string{"POST /orders", "POST /refunds", "X-Tenant: acme", `{"sku":"A-1"}`, "[REDACTED]"} {
+			if i > 0 && strings.HasPrefix(want, "POST") {
+				continue
+			}
+			if !strings.Contains(dump, want) {
+				t.Errorf("Dump %d: expected %q in:\n%s", i, want, dump)
+			}
+		}
+		if strings.Contains(dump, "secret") {
+			t.Errorf("Dump %d: expected credentials to be redacted:\n%s", i, dump)
+		}
+	}
+	if n := strings.Count(string(errorLog), "REQUEST:"); n != 2 {
+		t.Errorf("Expected 2 request dumps in the error log, got %d", n)
+	}
+}
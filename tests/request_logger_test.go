@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TestRequestScopedLogger verifies FromGin and FromRequest carry the request Meta and report the handler as source
+func TestRequestScopedLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/request-logger.access.log")
+	os.Remove(basicLogDir + "/request-logger.error.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "request-logger-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "request-logger",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	engine := gin.New()
+	engine.Use(middleware.GinMiddleware(logger))
+	engine.GET("/products", func(c *gin.Context) {
+		reqLog := logging.FromGin(c)
+		reqLog.Info("cache miss")
+		reqLog.Error(errors.New("price feed stale"))
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /stock", func(w http.ResponseWriter, r *http.Request) {
+		logging.FromRequest(r).Warn("stock below threshold")
+	})
+	handler := middleware.HTTPMiddleware(logger)(mux)
+
+	req := httptest.NewRequest("GET", "/products", nil)
+	req.Header.Set("X-Request-ID", "scoped-gin")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest("GET", "/stock", nil)
+	req.Header.Set("X-Request-ID", "scoped-http")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	outside := logging.FromRequest(httptest.NewRequest("GET", "/", nil))
+	if outside != nil {
+		t.Error("Expected no request logger outside the middleware")
+	}
+	outside.Info("dropped")
+	logger.Close()
+
+	accessLog, _ := os.ReadFile(basicLogDir + "/request-logger.access.log")
+	for _, want := range []string{
+		"[INFO] [REQ:scoped-gin] GET     /products | cache miss",
+		"[WARN] [REQ:scoped-http] GET     /stock | stock below threshold",
+	} {
+		if !strings.Contains(string(accessLog), want) {
+			t.Errorf("Expected %q in access log, got:\n%s", want, accessLog)
+		}
+	}
+	if strings.Contains(string(accessLog), "dropped") {
+		t.Error("Expected the nil request logger to log nothing")
+	}
+
+	errorLog, _ := os.ReadFile(basicLogDir + "/request-logger.error.log")
+	if !strings.Contains(string(errorLog), "REQ    : scoped-gin") || !strings.Contains(string(errorLog), "FROM   : request_logger_test.go:") {
+		t.Errorf("Expected request ID and handler source in error log, got:\n%s", errorLog)
+	}
+}
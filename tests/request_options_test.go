@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestLogRequestWithOptions verifies sizes, route, level and extra fields reach the Loki entry
+func TestLogRequestWithOptions(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/request-options.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "request-options-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "request-options",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "options-1", Method: "POST", Path: "/orders/42/items"})
+	_, _, line, _ := runtime.Caller(0)
+	logger.LogRequestWithOptions(ctx, logging.RequestLogOptions{
+		Status:   422,
+		Latency:  15 * time.Millisecond,
+		Err:      errors.New("invalid item"),
+		Level:    logging.LevelWarn,
+		Route:    "/orders/:id/items",
+		BytesIn:  512,
+		BytesOut: 64,
+		Fields:   map[string]interface{}{"tenant": "acme"},
+	})
+	logger.Close()
+
+	content, _ := os.ReadFile(basicLogDir + "/request-options.loki.log")
+	var entry struct {
+		LokiLogEntry
+		Tenant   string           `json:"tenant"`
+		Request  map[string]int64 `json:"request"`
+		Response map[string]int64 `json:"response"`
+	}
+	if err := json.Unmarshal(content, &entry); err != nil {
+		t.Fatalf("Failed to parse Loki line: %v\n%s", err, content)
+	}
+
+	if entry.StatusCode != 422 || entry.Level != "WARN" || entry.LatencyMS != 15 {
+		t.Errorf("Expected 422 WARN 15ms, got %d %s %dms", entry.StatusCode, entry.Level, entry.LatencyMS)
+	}
+	if entry.HTTP["route"] != "/orders/:id/items" {
+		t.Errorf("Expected route override, got %q", entry.HTTP["route"])
+	}
+	if entry.Request["bytes"] != 512 || entry.Response["bytes"] != 64 {
+		t.Errorf("Expected 512 bytes in and 64 out, got %v / %v", entry.Request, entry.Response)
+	}
+	if entry.Tenant != "acme" {
+		t.Errorf("Expected extra field tenant, got %q", entry.Tenant)
+	}
+	if entry.Errors == nil || entry.Errors.Source["line"] != float64(line+1) {
+		t.Errorf("Expected error source at the call site (line %d), got %+v", line+1, entry.Errors)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TestGinRouteRules verifies per-route skip, level override, and body capture
+func TestGinRouteRules(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ginLogDir := "../examples/gin/logs"
+	os.MkdirAll(ginLogDir, 0755)
+	os.Remove(ginLogDir + "/route-rules.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "route-rules-test",
+		LogPath:        ginLogDir,
+		FilePrefix:     "route-rules",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(middleware.GinMiddleware(logger))
+	r.Use(middleware.GinLoggerWithConfig(logger, middleware.LoggerConfig{
+		Rules: []middleware.RouteRule{
+			{Pattern: "/metrics", Skip: true},
+			{Pattern: "/webhooks/*", Level: logging.LevelDebug, LogBody: true},
+		},
+	}))
+
+	r.GET("/metrics", func(c *gin.Context) { c.String(200, "ok") })
+	r.POST("/webhooks/:provider", func(c *gin.Context) { c.String(200, "ok") })
+	r.GET("/users", func(c *gin.Context) { c.String(200, "ok") })
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/metrics", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/webhooks/stripe", strings.NewReader(`{"event":"paid"}`)))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+
+	logger.Close()
+
+	content, err := os.ReadFile(ginLogDir + "/route-rules.loki.log")
+	if err != nil {
+		t.Fatalf("Failed to read Loki log: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 Loki entries (/metrics skipped), got %d", len(lines))
+	}
+
+	for _, line := range lines {
+		var entry LokiLogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to parse Loki line: %v", err)
+		}
+
+		switch entry.HTTP["path"] {
+		case "/webhooks/stripe":
+			if entry.Level != "DEBUG" {
+				t.Errorf("Expected DEBUG level for webhook, got %s", entry.Level)
+			}
+			if entry.HTTP["body"] != `{"event":"paid"}` {
+				t.Errorf("Expected webhook body captured, got %q", entry.HTTP["body"])
+			}
+		case "/users":
+			if entry.Level != "INFO" {
+				t.Errorf("Expected INFO level for /users, got %s", entry.Level)
+			}
+			if _, ok := entry.HTTP["body"]; ok {
+				t.Error("Expected no body for /users")
+			}
+		default:
+			t.Errorf("Unexpected entry for path %s", entry.HTTP["path"])
+		}
+	}
+}
+
+// TestGinRouteRulesLargeBody verifies a large body reaches the handler intact while only its start is logged
+func TestGinRouteRulesLargeBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "route-body-test",
+		LogPath:        dir,
+		FilePrefix:     "route-body",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(middleware.GinMiddleware(logger))
+	r.Use(middleware.GinLoggerWithConfig(logger, middleware.LoggerConfig{
+		Rules: []middleware.RouteRule{{Pattern: "/upload", LogBody: true}},
+	}))
+
+	payload := strings.Repeat("x", 64*1024)
+	var received int
+	r.POST("/upload", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		received = len(body)
+		c.String(200, "ok")
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/upload", strings.NewReader(payload)))
+	logger.Close()
+
+	if received != len(payload) {
+		t.Errorf("Expected handler to read %d bytes, got %d", len(payload), received)
+	}
+
+	content, err := os.ReadFile(dir + "/route-body.loki.log")
+	if err != nil {
+		t.Fatalf("Failed to read Loki log: %v", err)
+	}
+
+	var entry LokiLogEntry
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(content))), &entry); err != nil {
+		t.Fatalf("Failed to parse Loki line: %v", err)
+	}
+	body := entry.HTTP["body"]
+	if !strings.HasSuffix(body, "...(truncated)") || len(body) > 4096+len("...(truncated)") {
+		t.Errorf("Expected a truncated body in the log, got %d bytes", len(body))
+	}
+}
+
+// failingBody returns n bytes, then a read error, like a client dropping mid-upload
+type failingBody struct {
+	n int
+}
+
+func (b *failingBody) Read(p []byte) (int, error) {
+	if b.n == 0 {
+		return 0, errors.New("connection reset")
+	}
+	k := min(len(p), b.n, 100)
+	for i := range p[:k] {
+		p[i] = 'x'
+	}
+	b.n -= k
+	return k, nil
+}
+
+// TestGinRouteRulesBodyReadError verifies a failed body capture leaves the bytes already read to the handler
+func TestGinRouteRulesBodyReadError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger, err := logging.New(&logging.Config{ServiceName: "route-body-error-test"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	r := gin.New()
+	r.Use(middleware.GinMiddleware(logger))
+	r.Use(middleware.GinLoggerWithConfig(logger, middleware.LoggerConfig{
+		Rules: []middleware.RouteRule{{Pattern: "/upload", LogBody: true}},
+	}))
+
+	var received int
+	var readErr error
+	r.POST("/upload", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		received, readErr = len(body), err
+		c.String(400, "bad body")
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/upload", &failingBody{n: 1000}))
+
+	if received != 1000 {
+		t.Errorf("Expected handler to read the 1000 bytes sent before the error, got %d", received)
+	}
+	if readErr == nil || readErr.Error() != "connection reset" {
+		t.Errorf("Expected handler to see the read error, got %v", readErr)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+)
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestSelfLogRateLimitsEncodeErrors verifies unencodable entries are reported once per window
+func TestSelfLogRateLimitsEncodeErrors(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+
+	internal := &lockedBuffer{}
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "selflog-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "selflog-test",
+		EnableFile:     true,
+		EnableRotation: false,
+		InternalLog:    internal,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.AddHook(func(e *logging.Entry) bool {
+		e.Fields["conn"] = make(chan int)
+		return true
+	})
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "selflog", Method: "GET", Path: "/orders"})
+	for i := 0; i < 3; i++ {
+		logger.LogRequest(ctx, 200, time.Millisecond)
+	}
+
+	out := internal.String()
+	if strings.Count(out, "\n") != 1 {
+		t.Fatalf("Expected one self-log line, got: %q", out)
+	}
+	if !strings.Contains(out, "loki.encode") || !strings.Contains(out, "failed to encode entry") {
+		t.Errorf("Expected encode failure in self-log, got: %q", out)
+	}
+	if got := logger.Stats().InternalErrors; got != 3 {
+		t.Errorf("Expected 3 internal errors, got %d", got)
+	}
+}
+
+// TestSelfLogAlertFailure verifies failed alert deliveries go to the self-log instead of stdout
+func TestSelfLogAlertFailure(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer webhook.Close()
+
+	internal := &lockedBuffer{}
+	logger, err := logging.New(&logging.Config{
+		ServiceName:  "selflog-alert-test",
+		EnableStdout: false,
+		InternalLog:  internal,
+		Alerts: &logging.AlertsConfig{
+			Enabled:  true,
+			MinLevel: "ERROR",
+			Slack:    &slack.Config{Enabled: true, WebhookURL: webhook.URL},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "selflog-alert", Method: "POST", Path: "/pay"})
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("payment gateway down"))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(internal.String(), "alerts.slack") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	out := internal.String()
+	if !strings.Contains(out, "alerts.slack: failed to send alert") {
+		t.Errorf("Expected alert failure in self-log, got: %q", out)
+	}
+	if got := logger.Stats().Alerts.Failed; got != 1 {
+		t.Errorf("Expected 1 failed alert, got %d", got)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+)
+
+// TestServerlessMode verifies stdout-only JSON output, platform request IDs and draining before return
+func TestServerlessMode(t *testing.T) {
+	dir := t.TempDir()
+
+	var delivered atomic.Bool
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		delivered.Store(true)
+	}))
+	defer webhook.Close()
+
+	stdout, pipe, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	realStdout := os.Stdout
+	os.Stdout = pipe
+	defer func() { os.Stdout = realStdout }()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "serverless-test",
+		LogPath:     dir,
+		EnableFile:  true,
+		Serverless:  true,
+		Alerts: &logging.AlertsConfig{
+			Enabled:  true,
+			MinLevel: "ERROR",
+			Slack:    &slack.Config{Enabled: true, WebhookURL: webhook.URL},
+		},
+	})
+	os.Stdout = realStdout
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+		logging.FromRequest(r).Info("loading orders")
+		logger.Error(r.Context(), errors.New("orders table missing"))
+		middleware.AddHTTPError(r, errors.New("orders table missing"))
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	handler := middleware.WrapHTTP(logger, mux)
+
+	lambda := httptest.NewRequest("GET", "/orders", nil)
+	lambda.Header.Set("X-Amzn-Lambda-Context", `{"request_id":"8f5e2c1a-lambda","deadline":1770000000000}`)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, lambda)
+
+	if !delivered.Load() {
+		t.Error("Expected the alert to be delivered before the handler returned")
+	}
+	if got := rec.Header().Get("X-Request-ID"); got != "8f5e2c1a-lambda" {
+		t.Errorf("Expected Lambda request ID, got %q", got)
+	}
+
+	cloudRun := httptest.NewRequest("GET", "/health", nil)
+	cloudRun.Header.Set("X-Cloud-Trace-Context", "105445aa7843bc8bf206b12000100000/1;o=1")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, cloudRun)
+	if got := rec.Header().Get("X-Request-ID"); got != "105445aa7843bc8bf206b12000100000" {
+		t.Errorf("Expected Cloud Run trace ID, got %q", got)
+	}
+
+	logger.Close()
+	pipe.Close()
+	out, _ := io.ReadAll(stdout)
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected message, error, and two request entries, got %d:\n%s", len(lines), out)
+	}
+	for _, line := range lines {
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("Expected only JSON lines on stdout, got %q: %v", line, err)
+		}
+		if rec["request_id"] != "8f5e2c1a-lambda" && rec["request_id"] != "105445aa7843bc8bf206b12000100000" {
+			t.Errorf("Expected platform request ID, got %v", rec["request_id"])
+		}
+	}
+
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected no log files in serverless mode, got %d", len(files))
+	}
+}
+
+// TestPlatformRequestID verifies which headers carry the platform request ID
+func TestPlatformRequestID(t *testing.T) {
+	for _, tc := range []struct {
+		header, value, want string
+	}{
+		{"X-Amzn-Lambda-Context", `{"request_id":"abc-123"}`, "abc-123"},
+		{"X-Amzn-Lambda-Context", `not json`, ""},
+		{"X-Cloud-Trace-Context", "trace-1/span-2;o=1", "trace-1"},
+		{"X-Cloud-Trace-Context", "trace-1;o=1", "trace-1"},
+		{"X-Request-ID", "client-id", ""},
+	} {
+		h := http.Header{}
+		h.Set(tc.header, tc.value)
+		if got := logging.PlatformRequestID(h); got != tc.want {
+			t.Errorf("%s %q: expected %q, got %q", tc.header, tc.value, tc.want, got)
+		}
+	}
+}
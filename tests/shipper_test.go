@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/sinks/loki"
+)
+
+// TestShipperPositions verifies the shipper pushes each .loki line once, across restarts and outages
+func TestShipperPositions(t *testing.T) {
+	logDir := t.TempDir()
+	positions := filepath.Join(logDir, "positions.json")
+
+	var mu sync.Mutex
+	var shipped []string
+	var failing atomic.Bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var req lokiPushRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode push request: %v", err)
+		}
+		mu.Lock()
+		for _, stream := range req.Streams {
+			if stream.Stream["service"] != "shipper-test" {
+				t.Errorf("Unexpected stream labels: %v", stream.Stream)
+			}
+			for _, value := range stream.Values {
+				shipped = append(shipped, value[1])
+			}
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	writeEntries := func(n int) {
+		logger, err := logging.New(&logging.Config{
+			ServiceName:    "shipper-test",
+			LogPath:        logDir,
+			FilePrefix:     "shipper-test",
+			EnableFile:     true,
+			EnableRotation: true,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "ship", Method: "GET", Path: "/ship"})
+		for i := 0; i < n; i++ {
+			logger.LogRequest(ctx, 200, time.Millisecond)
+		}
+		logger.Close()
+	}
+
+	ship := func() {
+		shipper, err := loki.NewShipper(loki.ShipperConfig{
+			Loki:          &loki.Config{URL: server.URL},
+			Paths:         []string{filepath.Join(logDir, "shipper-test.loki*.log")},
+			PositionsFile: positions,
+			PollInterval:  time.Hour,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create shipper: %v", err)
+		}
+		if err := shipper.Close(); err != nil {
+			t.Fatalf("Failed to close shipper: %v", err)
+		}
+	}
+
+	shippedCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(shipped)
+	}
+
+	writeEntries(3)
+	ship()
+	if got := shippedCount(); got != 3 {
+		t.Fatalf("Expected 3 shipped lines, got %d", got)
+	}
+
+	writeEntries(2)
+	failing.Store(true)
+	ship()
+	if got := shippedCount(); got != 3 {
+		t.Fatalf("Lines must not be acknowledged while Loki fails, got %d", got)
+	}
+
+	failing.Store(false)
+	ship()
+	if got := shippedCount(); got != 5 {
+		t.Fatalf("Expected the 2 new lines after recovery (5 total), got %d", got)
+	}
+
+	data, err := os.ReadFile(positions)
+	if err != nil {
+		t.Fatalf("Failed to read positions file: %v", err)
+	}
+	var stored struct {
+		Positions map[string]int64 `json:"positions"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil || len(stored.Positions) != 1 {
+		t.Fatalf("Expected one tracked file (symlink deduplicated), got %s", data)
+	}
+	for file, offset := range stored.Positions {
+		info, _ := os.Stat(file)
+		if info == nil || info.Size() != offset {
+			t.Errorf("Expected offset at end of %s, got %d", file, offset)
+		}
+	}
+}
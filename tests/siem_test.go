@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestCEFFormat verifies the Loki stream can be written as ArcSight CEF lines
+func TestCEFFormat(t *testing.T) {
+	line := writeSIEMEntry(t, "cef-test", logging.FormatCEF)
+
+	if !strings.HasPrefix(line, `CEF:0|go-logging-lib|cef-test|1.0|500|charge failed: amount=0 \| retry|10|`) {
+		t.Errorf("Unexpected CEF header: %q", line)
+	}
+	for _, want := range []string{
+		"src=10.0.0.1",
+		"requestMethod=POST",
+		"request=/payments",
+		"cn1=500 cn1Label=statusCode",
+		"cn2=12 cn2Label=latencyMs",
+		"cs1=req-siem cs1Label=requestId",
+		`msg=charge failed: amount\=0 | retry`,
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected %q in CEF line %q", want, line)
+		}
+	}
+}
+
+// TestLEEFFormat verifies the Loki stream can be written as QRadar LEEF lines
+func TestLEEFFormat(t *testing.T) {
+	line := writeSIEMEntry(t, "leef-test", logging.FormatLEEF)
+
+	if !strings.HasPrefix(line, "LEEF:1.0|go-logging-lib|leef-test|1.0|500|") {
+		t.Errorf("Unexpected LEEF header: %q", line)
+	}
+	for _, want := range []string{
+		"\tsev=10\tcat=CRITICAL\tsrc=10.0.0.1\tmethod=POST\turl=/payments\t",
+		"\tstatus=500\tlatencyMs=12\trequestId=req-siem\t",
+		"\tmsg=charge failed: amount=0 | retry",
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected %q in LEEF line %q", want, line)
+		}
+	}
+}
+
+func writeSIEMEntry(t *testing.T, prefix string, format logging.Format) string {
+	t.Helper()
+
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/" + prefix + ".loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    prefix,
+		LogPath:        basicLogDir,
+		FilePrefix:     prefix,
+		EnableFile:     true,
+		EnableRotation: false,
+		LokiFormat:     format,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{
+		RequestID: "req-siem",
+		IP:        "10.0.0.1",
+		Method:    "POST",
+		Path:      "/payments",
+	})
+	logger.LogRequestWithError(ctx, 500, 12*time.Millisecond, errors.New("charge failed: amount=0 | retry"))
+	logger.Close()
+
+	content, err := os.ReadFile(basicLogDir + "/" + prefix + ".loki.log")
+	if err != nil {
+		t.Fatalf("Failed to read Loki log: %v", err)
+	}
+	return strings.TrimSpace(string(content))
+}
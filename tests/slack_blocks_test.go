@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+)
+
+// slackButtons returns the URLs of the action buttons in a Slack Block Kit message keyed by label
+func slackButtons(msg map[string]any) map[string]string {
+	attachment := msg["attachments"].([]any)[0].(map[string]any)
+	buttons := map[string]string{}
+	for _, b := range attachment["blocks"].([]any) {
+		block := b.(map[string]any)
+		if block["type"] != "actions" {
+			continue
+		}
+		for _, e := range block["elements"].([]any) {
+			button := e.(map[string]any)
+			buttons[button["text"].(map[string]any)["text"].(string)] = button["url"].(string)
+		}
+	}
+	return buttons
+}
+
+// TestSlackBlockKitGrafanaButton verifies Block Kit output with a Grafana link for the request ID
+func TestSlackBlockKitGrafanaButton(t *testing.T) {
+	messages := make(chan map[string]any, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]any
+		json.NewDecoder(r.Body).Decode(&msg)
+		messages <- msg
+	}))
+	defer webhook.Close()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "orders-api",
+		Alerts: &logging.AlertsConfig{
+			Enabled:  true,
+			MinLevel: "ERROR",
+			Slack: &slack.Config{
+				Enabled:    true,
+				WebhookURL: webhook.URL,
+				GrafanaURL: "https://grafana.example.com/explore?query={request_id}",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "req 42", Method: "POST", Path: "/orders"})
+	logger.ErrorLoki(ctx, logging.LevelError, errors.New("stock <0> & falling"))
+	logger.Close()
+
+	msg := <-messages
+	if text, _ := msg["text"].(string); text == "" {
+		t.Error("Expected fallback text for notifications")
+	}
+	if fields := slackFields(msg); fields["Request ID"] != "req 42" {
+		t.Errorf("Expected request ID field, got %q", fields["Request ID"])
+	}
+
+	attachment := msg["attachments"].([]any)[0].(map[string]any)
+	blocks := attachment["blocks"].([]any)
+	if errText := blocks[1].(map[string]any)["text"].(map[string]any)["text"]; errText != "stock &lt;0&gt; &amp; falling" {
+		t.Errorf("Expected escaped error text, got %q", errText)
+	}
+
+	want := "https://grafana.example.com/explore?query=req+42"
+	if got := slackButtons(msg)["View in Grafana"]; got != want {
+		t.Errorf("Expected Grafana button %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib/sinks/loki"
+)
+
+// TestLokiSpillReplay verifies unpushable entries survive an outage and a restart on disk
+func TestLokiSpillReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	var healthy atomic.Bool
+	var mu sync.Mutex
+	var lines []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var req lokiPushRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		for _, stream := range req.Streams {
+			for _, value := range stream.Values {
+				lines = append(lines, value[1])
+			}
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	config := &loki.Config{Enabled: true, URL: server.URL, SpillDir: dir}
+
+	w := loki.New(config)
+	w.SetErrorHandler(func(string, error) {})
+
+	for i := 1; i <= 3; i++ {
+		fmt.Fprintf(w, `{"ts":"2026-10-15T10:00:0%dZ","level":"INFO","service":"spill-test","seq":%d}`+"\n", i, i)
+	}
+	if err := w.Flush(); err == nil {
+		t.Fatal("Expected flush to fail while Loki is down")
+	}
+
+	// spilled entries are pending, so this one queues behind them on disk
+	fmt.Fprintf(w, `{"ts":"2026-10-15T10:00:04Z","level":"INFO","service":"spill-test","seq":4}`+"\n")
+	w.Close()
+
+	if st := w.Stats(); st.Spilled != 4 || st.Dropped != 0 || st.Failed != 0 {
+		t.Fatalf("Unexpected stats while down: %+v", st)
+	}
+
+	healthy.Store(true)
+
+	// a new writer picks up the spill log left by the previous one
+	w = loki.New(config)
+	w.SetErrorHandler(func(string, error) {})
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Expected replay to succeed: %v", err)
+	}
+	w.Close()
+
+	if st := w.Stats(); st.Replayed != 4 {
+		t.Errorf("Expected 4 replayed entries, got %+v", st)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines pushed, got %d: %v", len(lines), lines)
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, fmt.Sprintf(`"seq":%d`, i+1)) {
+			t.Errorf("Expected entries replayed in order, got %s at %d", line, i)
+		}
+	}
+
+	info, err := os.Stat(dir + "/loki-spill.wal")
+	if err != nil || info.Size() != 0 {
+		t.Errorf("Expected spill file truncated after replay, got %v %v", info, err)
+	}
+}
+
+// TestLokiSpillTornTail verifies a spill log ending in a partial line (crash mid-append) replays without hanging
+func TestLokiSpillTornTail(t *testing.T) {
+	dir := t.TempDir()
+
+	var mu sync.Mutex
+	var lines []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req lokiPushRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		for _, stream := range req.Streams {
+			for _, value := range stream.Values {
+				lines = append(lines, value[1])
+			}
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	wal := `{"level":"INFO","service":"torn-test","seq":1}` + "\n" + `{"msg":"tor`
+	if err := os.WriteFile(dir+"/loki-spill.wal", []byte(wal), 0644); err != nil {
+		t.Fatalf("Failed to write spill file: %v", err)
+	}
+
+	w := loki.New(&loki.Config{Enabled: true, URL: server.URL, SpillDir: dir})
+	w.SetErrorHandler(func(string, error) {})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Flush()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Expected replay to succeed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Flush hung on the torn spill tail")
+	}
+
+	fmt.Fprintf(w, `{"level":"INFO","service":"torn-test","seq":2}`+"\n")
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	w.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(lines) != 2 || !strings.Contains(lines[0], `"seq":1`) || !strings.Contains(lines[1], `"seq":2`) {
+		t.Errorf("Expected the complete spilled line and the new one, got %v", lines)
+	}
+	if st := w.Stats(); st.Replayed != 1 {
+		t.Errorf("Expected 1 replayed entry, got %+v", st)
+	}
+}
+
+// TestLokiSpillDirUnusable verifies an unusable SpillDir is reported through the error handler
+func TestLokiSpillDirUnusable(t *testing.T) {
+	blocker := t.TempDir() + "/not-a-dir"
+	if err := os.WriteFile(blocker, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	w := loki.New(&loki.Config{Enabled: true, URL: "http://127.0.0.1:1", SpillDir: blocker})
+	defer w.Close()
+
+	var reported []string
+	w.SetErrorHandler(func(source string, err error) {
+		reported = append(reported, source+": "+err.Error())
+	})
+
+	if len(reported) != 1 || !strings.HasPrefix(reported[0], "loki.spill: spill disabled:") {
+		t.Errorf("Expected the spill error on SetErrorHandler, got %v", reported)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestLoggerStats verifies pipeline counters for levels, bytes, drops and suppression
+func TestLoggerStats(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	for _, stream := range []string{"access", "error", "loki"} {
+		os.Remove(basicLogDir + "/stats-test." + stream + ".log")
+	}
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "stats-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "stats-test",
+		EnableFile:     true,
+		EnableRotation: false,
+		ErrorRepeatSec: 60,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.AddHook(func(e *logging.Entry) bool {
+		return e.Meta.Path != "/healthz"
+	})
+
+	ctx := logging.WithMeta(t.Context(), logging.Meta{RequestID: "stats", Method: "GET", Path: "/orders"})
+	health := logging.WithMeta(t.Context(), logging.Meta{RequestID: "health", Method: "GET", Path: "/healthz"})
+
+	logger.LogRequest(ctx, 200, time.Millisecond)
+	logger.LogRequest(ctx, 200, time.Millisecond)
+	logger.LogRequest(health, 200, time.Millisecond)
+	logger.Warn(ctx, "slow upstream")
+	logger.Info("started")
+	logger.Error(ctx, errors.New("db down"))
+	logger.Error(ctx, errors.New("db down"))
+	logger.Close()
+
+	st := logger.Stats()
+
+	want := map[logging.LogLevel]uint64{
+		logging.LevelInfo:  3,
+		logging.LevelWarn:  1,
+		logging.LevelError: 1,
+		logging.LevelDebug: 0,
+	}
+	for level, n := range want {
+		if st.Entries[level] != n {
+			t.Errorf("Expected %d %s entries, got %d", n, level, st.Entries[level])
+		}
+	}
+	if st.Dropped != 1 {
+		t.Errorf("Expected 1 entry dropped by hook, got %d", st.Dropped)
+	}
+	if st.Suppressed != 1 {
+		t.Errorf("Expected 1 suppressed error, got %d", st.Suppressed)
+	}
+	if st.WriteErrors != 0 {
+		t.Errorf("Expected no write errors, got %d", st.WriteErrors)
+	}
+
+	for _, stream := range []string{"access", "loki"} {
+		info, err := os.Stat(basicLogDir + "/stats-test." + stream + ".log")
+		if err != nil {
+			t.Fatalf("Failed to stat %s log: %v", stream, err)
+		}
+		if st.BytesWritten[stream] != uint64(info.Size()) {
+			t.Errorf("Expected %d %s bytes, got %d", info.Size(), stream, st.BytesWritten[stream])
+		}
+	}
+	if st.BytesWritten["error"] == 0 {
+		t.Error("Expected bytes written to the error stream")
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestRedirectStdLog verifies stdlib log output lands in the error stream
+func TestRedirectStdLog(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/stdlog-test.error.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "stdlog-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "stdlog-test",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	srv := &http.Server{}
+	restore := logger.RedirectStdLog(srv)
+
+	log.Println("STDLOG TEST: message from dependency")
+	srv.ErrorLog.Println("STDLOG TEST: http server error")
+
+	restore()
+	time.Sleep(100 * time.Millisecond)
+
+	content, err := os.ReadFile(basicLogDir + "/stdlog-test.error.log")
+	if err != nil {
+		t.Fatalf("Failed to read error log: %v", err)
+	}
+
+	if !strings.Contains(string(content), "[STDLOG] ") || !strings.Contains(string(content), "message from dependency") {
+		t.Error("Expected stdlib log message not found in error log")
+	}
+	if !strings.Contains(string(content), "[HTTP] ") || !strings.Contains(string(content), "http server error") {
+		t.Error("Expected http.Server ErrorLog message not found in error log")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestStreamPaths verifies per-stream directories override LogPath for their stream only
+func TestStreamPaths(t *testing.T) {
+	dir := t.TempDir()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName: "stream-paths-test",
+		LogPath:     dir + "/ephemeral",
+		FilePrefix:  "app",
+		EnableFile:  true,
+		StreamPaths: map[string]string{"error": dir + "/persistent"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "paths", Method: "GET", Path: "/orders"})
+	logger.LogRequest(ctx, 200, time.Millisecond)
+	logger.Error(ctx, errors.New("disk full"))
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	for _, path := range []string{
+		dir + "/ephemeral/app.access.log",
+		dir + "/ephemeral/app.loki.log",
+		dir + "/persistent/app.error.log",
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to exist: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(dir + "/ephemeral/app.error.log"); err == nil {
+		t.Error("Expected no error file under LogPath")
+	}
+
+	data, err := os.ReadFile(dir + "/persistent/app.error.log")
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	if !strings.Contains(string(data), "disk full") {
+		t.Errorf("Expected error in persistent file, got: %s", data)
+	}
+}
+
+// TestStreamPathsValidation verifies unknown streams and empty paths are rejected
+func TestStreamPathsValidation(t *testing.T) {
+	for _, paths := range []map[string]string{
+		{"audit": "/tmp/audit"},
+		{"error": ""},
+	} {
+		_, err := logging.New(&logging.Config{
+			ServiceName: "stream-paths-test",
+			LogPath:     t.TempDir(),
+			StreamPaths: paths,
+		})
+		if err == nil || !strings.Contains(err.Error(), "stream_paths") {
+			t.Errorf("Expected stream_paths error for %v, got: %v", paths, err)
+		}
+	}
+}
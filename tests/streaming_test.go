@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+)
+
+// TestHTTPStreamingResponse verifies Flusher pass-through and response stats in Loki
+func TestHTTPStreamingResponse(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/stream-test.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "stream-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "stream-test",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			t.Error("Expected wrapped writer to implement http.Flusher")
+			return
+		}
+		if _, ok := w.(http.Hijacker); !ok {
+			t.Error("Expected wrapped writer to implement http.Hijacker")
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		time.Sleep(20 * time.Millisecond)
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, "data: %d\n\n", i)
+			flusher.Flush()
+		}
+	})
+
+	handler := middleware.HTTPMiddleware(logger)(middleware.HTTPLogger(logger)(mux))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/events", nil))
+	logger.Close()
+
+	if !rec.Flushed {
+		t.Error("Expected flush to reach the underlying writer")
+	}
+
+	content, err := os.ReadFile(basicLogDir + "/stream-test.loki.log")
+	if err != nil {
+		t.Fatalf("Failed to read Loki log: %v", err)
+	}
+
+	var entry struct {
+		Response map[string]int64 `json:"response"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(content))), &entry); err != nil {
+		t.Fatalf("Failed to parse Loki line: %v", err)
+	}
+	if entry.Response["bytes"] != int64(rec.Body.Len()) {
+		t.Errorf("Expected %d bytes, got %d", rec.Body.Len(), entry.Response["bytes"])
+	}
+	if entry.Response["first_byte_ms"] < 20 {
+		t.Errorf("Expected first byte after ~20ms, got %dms", entry.Response["first_byte_ms"])
+	}
+}
+
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+	pushed []string
+}
+
+func (p *pushRecorder) Push(target string, opts *http.PushOptions) error {
+	p.pushed = append(p.pushed, target)
+	return nil
+}
+
+// TestHTTPServerPush verifies http.Pusher passes through and reports ErrNotSupported without push support
+func TestHTTPServerPush(t *testing.T) {
+	logger, err := logging.New(&logging.Config{ServiceName: "push-test"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	var pushErr error
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
+		pusher, ok := w.(http.Pusher)
+		if !ok {
+			t.Error("Expected wrapped writer to implement http.Pusher")
+			return
+		}
+		pushErr = pusher.Push("/app.css", nil)
+		w.Write([]byte("<html></html>"))
+	})
+	handler := middleware.HTTPMiddleware(logger)(middleware.HTTPLogger(logger)(mux))
+
+	rec := &pushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if pushErr != nil || len(rec.pushed) != 1 || rec.pushed[0] != "/app.css" {
+		t.Errorf("Expected push to reach the underlying writer, got %v (err %v)", rec.pushed, pushErr)
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if pushErr != http.ErrNotSupported {
+		t.Errorf("Expected http.ErrNotSupported without push support, got %v", pushErr)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestRepeatedErrorSuppression verifies repeated errors collapse into a summary line
+func TestRepeatedErrorSuppression(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/suppress-test.error.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "suppress-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "suppress-test",
+		EnableFile:     true,
+		EnableRotation: false,
+		ErrorRepeatSec: 60,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "suppress-001", Method: "GET", Path: "/orders"})
+
+	for i := 0; i < 5; i++ {
+		logger.Error(ctx, errors.New("SUPPRESS TEST: database connection failed"))
+	}
+	logger.Error(ctx, errors.New("SUPPRESS TEST: different error"))
+
+	logger.Close()
+
+	content, err := os.ReadFile(basicLogDir + "/suppress-test.error.log")
+	if err != nil {
+		t.Fatalf("Failed to read error log: %v", err)
+	}
+
+	contentStr := string(content)
+	if n := strings.Count(contentStr, "ERROR  : SUPPRESS TEST: database connection failed"); n != 1 {
+		t.Errorf("Expected 1 full error block, got %d", n)
+	}
+	if !strings.Contains(contentStr, "ERROR  : SUPPRESS TEST: different error") {
+		t.Error("Expected distinct error to be logged")
+	}
+	if !strings.Contains(contentStr, "[REPEATED] err=SUPPRESS TEST: database connection failed repeated 4 times") {
+		t.Error("Expected repeated summary line in error log")
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/telegram"
+)
+
+// telegramServer records sendMessage bodies posted to a fake Bot API
+func telegramServer(t *testing.T) (*httptest.Server, func() []map[string]any) {
+	var mu sync.Mutex
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bottoken/sendMessage" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []map[string]any {
+		mu.Lock()
+		defer mu.Unlock()
+		return bodies
+	}
+}
+
+// TestTelegramTopicAndMarkdownV2 verifies the forum topic ID and MarkdownV2 escaping
+func TestTelegramTopicAndMarkdownV2(t *testing.T) {
+	server, sent := telegramServer(t)
+	alerter := telegram.New(&telegram.Config{
+		BotToken:  "token",
+		ChatID:    "-100123",
+		ThreadID:  42,
+		ParseMode: "MarkdownV2",
+		APIURL:    server.URL,
+	})
+
+	err := alerter.Send(t.Context(), alerts.Payload{
+		ServiceName: "orders-api",
+		Level:       "ERROR",
+		Error:       "price must be > 0 (got -1.5)",
+		Path:        "/orders",
+		Stack:       []string{"main.go:12 `handler`"},
+		Timestamp:   time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	bodies := sent()
+	if len(bodies) != 1 {
+		t.Fatalf("Expected one message, got %d", len(bodies))
+	}
+	body := bodies[0]
+	if body["parse_mode"] != "MarkdownV2" || body["message_thread_id"] != float64(42) {
+		t.Errorf("Expected MarkdownV2 in topic 42, got %v / %v", body["parse_mode"], body["message_thread_id"])
+	}
+	text := body["text"].(string)
+	if !strings.Contains(text, `price must be \> 0 \(got \-1\.5\)`) {
+		t.Errorf("Expected escaped error text, got %q", text)
+	}
+	if !strings.Contains(text, "```\nmain.go:12 \\`handler\\`\n```") {
+		t.Errorf("Expected escaped stack code block, got %q", text)
+	}
+}
+
+// TestTelegramSplitsLongMessages verifies messages over the API limit are split with valid code blocks
+func TestTelegramSplitsLongMessages(t *testing.T) {
+	server, sent := telegramServer(t)
+	alerter := telegram.New(&telegram.Config{BotToken: "token", ChatID: "1", APIURL: server.URL})
+
+	var stack []string
+	for range 40 {
+		stack = append(stack, strings.Repeat("frame ", 40))
+	}
+	if err := alerter.Send(t.Context(), alerts.Payload{Level: "CRITICAL", Error: "boom", Stack: stack, Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	bodies := sent()
+	if len(bodies) < 3 {
+		t.Fatalf("Expected the stack to be split over several messages, got %d", len(bodies))
+	}
+	for i, body := range bodies {
+		text := body["text"].(string)
+		if n := utf8.RuneCountInString(text); n > 4096 {
+			t.Errorf("Message %d has %d characters", i, n)
+		}
+		if strings.Count(text, "<pre>") != strings.Count(text, "</pre>") {
+			t.Errorf("Message %d has an unbalanced code block", i)
+		}
+		if _, ok := body["message_thread_id"]; ok {
+			t.Errorf("Expected no thread ID without a topic")
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TestTimingHeader verifies Server-Timing and X-Response-Time headers on both middlewares
+func TestTimingHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger, err := logging.New(&logging.Config{ServiceName: "timing-test"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	r := gin.New()
+	r.Use(middleware.GinMiddleware(logger))
+	r.Use(middleware.GinLoggerWithConfig(logger, middleware.LoggerConfig{
+		TimingHeader: middleware.HeaderServerTiming,
+	}))
+	r.GET("/body", func(c *gin.Context) { c.String(200, "ok") })
+	r.GET("/empty", func(c *gin.Context) { c.Status(204) })
+
+	for _, p := range []string{"/body", "/empty"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest("GET", p, nil))
+		if v := rec.Header().Get("Server-Timing"); !strings.HasPrefix(v, "app;dur=") {
+			t.Errorf("Expected Server-Timing on Gin %s, got %q", p, v)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/body", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) })
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {})
+	handler := middleware.HTTPMiddleware(logger)(middleware.HTTPLoggerWithConfig(logger, middleware.LoggerConfig{
+		TimingHeader: middleware.HeaderResponseTime,
+	})(mux))
+
+	for _, p := range []string{"/body", "/empty"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", p, nil))
+		if v := rec.Header().Get("X-Response-Time"); !strings.HasSuffix(v, "ms") {
+			t.Errorf("Expected X-Response-Time on HTTP %s, got %q", p, v)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/slack"
+	"github.com/ahmadsaubani/go-logging-lib/outbound"
+	"github.com/ahmadsaubani/go-logging-lib/sinks/loki"
+)
+
+// writeServerCA stores the test server's certificate as a PEM CA bundle
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+
+	path := t.TempDir() + "/ca.pem"
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0644); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+	return path
+}
+
+// writeClientCert creates a self-signed client certificate and key
+func writeClientCert(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "logging-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	dir := t.TempDir()
+	os.WriteFile(dir+"/client.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	os.WriteFile(dir+"/client.key", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return dir + "/client.pem", dir + "/client.key"
+}
+
+// TestLokiMutualTLS verifies pushes trust a private CA and present a client certificate
+func TestLokiMutualTLS(t *testing.T) {
+	clients := make(chan string, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clients <- r.TLS.PeerCertificates[0].Subject.CommonName
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	certFile, keyFile := writeClientCert(t)
+
+	w := loki.New(&loki.Config{
+		Enabled: true,
+		URL:     server.URL,
+		TLS: &outbound.TLSConfig{
+			CAFile:     writeServerCA(t, server),
+			CertFile:   certFile,
+			KeyFile:    keyFile,
+			MinVersion: "1.2",
+		},
+	})
+	w.Write([]byte(`{"service":"tls-test","level":"INFO"}` + "\n"))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Expected mTLS push to succeed: %v", err)
+	}
+	w.Close()
+
+	if cn := <-clients; cn != "logging-client" {
+		t.Errorf("Expected client certificate logging-client, got %q", cn)
+	}
+}
+
+// TestLokiUntrustedServer verifies pushes fail without the private CA
+func TestLokiUntrustedServer(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	w := loki.New(&loki.Config{Enabled: true, URL: server.URL, MaxRetries: -1})
+	w.SetErrorHandler(func(string, error) {})
+	w.Write([]byte(`{"service":"tls-test","level":"INFO"}` + "\n"))
+	if err := w.Flush(); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("Expected certificate error, got %v", err)
+	}
+	w.Close()
+}
+
+// TestInvalidTLSConfigFailsNew verifies broken TLS settings are reported by New
+func TestInvalidTLSConfigFailsNew(t *testing.T) {
+	_, err := logging.New(&logging.Config{
+		ServiceName: "tls-test",
+		Alerts: &logging.AlertsConfig{
+			Enabled: true,
+			Slack: &slack.Config{
+				Enabled:    true,
+				WebhookURL: "https://hooks.slack.com/services/x",
+				TLS:        &outbound.TLSConfig{CAFile: "/nonexistent/ca.pem"},
+			},
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "slack TLS") {
+		t.Errorf("Expected slack TLS error, got %v", err)
+	}
+
+	_, err = logging.New(&logging.Config{
+		ServiceName: "tls-test",
+		Loki:        &loki.Config{TLS: &outbound.TLSConfig{MinVersion: "1.4"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "min version") {
+		t.Errorf("Expected min version error, got %v", err)
+	}
+}
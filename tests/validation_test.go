@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type createOrder struct {
+	Email    string `json:"email" binding:"required,email"`
+	Quantity int    `json:"quantity" binding:"min=1"`
+}
+
+// TestGinValidationDetails verifies binding errors passed to c.Error produce errors.validation entries
+func TestGinValidationDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/validation.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "validation-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "validation",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	engine := gin.New()
+	engine.Use(middleware.GinMiddleware(logger), middleware.GinLogger(logger))
+	engine.POST("/orders", func(c *gin.Context) {
+		var req createOrder
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(err)
+			c.Status(400)
+			return
+		}
+		c.Status(201)
+	})
+
+	for _, body := range []string{
+		`{"email":"not-an-email","quantity":0}`,
+		`{"email":"a@b.c","quantity":"two"}`,
+	} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/orders", strings.NewReader(body)))
+	}
+	logger.Close()
+
+	content, _ := os.ReadFile(basicLogDir + "/validation.loki.log")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 Loki entries, got %d: %s", len(lines), content)
+	}
+
+	want := [][]logging.FieldError{
+		{
+			{Field: "Email", Tag: "email", Value: "not-an-email"},
+			{Field: "Quantity", Tag: "min", Param: "1", Value: "0"},
+		},
+		{
+			{Field: "quantity", Tag: "type", Param: "int", Value: "string"},
+		},
+	}
+	for i, line := range lines {
+		var entry struct {
+			Errors struct {
+				Error      string               `json:"error"`
+				Validation []logging.FieldError `json:"validation"`
+			} `json:"errors"`
+		}
+		json.Unmarshal([]byte(line), &entry)
+		if !strings.Contains(entry.Errors.Error, "Error #01") {
+			t.Errorf("Entry %d: expected the c.Errors message to be kept, got %q", i, entry.Errors.Error)
+		}
+		if len(entry.Errors.Validation) != len(want[i]) {
+			t.Fatalf("Entry %d: expected %d validation details, got %+v", i, len(want[i]), entry.Errors.Validation)
+		}
+		for j, fe := range entry.Errors.Validation {
+			if fe != want[i][j] {
+				t.Errorf("Entry %d: expected %+v, got %+v", i, want[i][j], fe)
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib/alerts"
+	"github.com/ahmadsaubani/go-logging-lib/alerts/webex"
+)
+
+// TestWebexAlerter verifies the webhook markdown message and the bot message with an Adaptive Card
+func TestWebexAlerter(t *testing.T) {
+	messages := make(chan map[string]any, 2)
+	auth := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]any
+		json.NewDecoder(r.Body).Decode(&msg)
+		auth <- r.Header.Get("Authorization")
+		messages <- msg
+	}))
+	defer server.Close()
+
+	payload := alerts.Payload{
+		ServiceName: "orders-api",
+		Level:       "CRITICAL",
+		Error:       "payment_failed for *card*",
+		RequestID:   "req-7",
+		Links:       []alerts.Link{{Name: "Grafana", URL: "https://grafana.example.com/x"}},
+		Timestamp:   time.Now(),
+	}
+
+	if err := webex.New(&webex.Config{WebhookURL: server.URL}).Send(t.Context(), payload); err != nil {
+		t.Fatalf("Webhook send failed: %v", err)
+	}
+	msg := <-messages
+	markdown, _ := msg["markdown"].(string)
+	if !strings.Contains(markdown, `payment\_failed for \*card\*`) || !strings.Contains(markdown, "- **Request ID:** req-7") {
+		t.Errorf("Expected escaped markdown with details, got %q", markdown)
+	}
+	if a := <-auth; a != "" || msg["attachments"] != nil {
+		t.Errorf("Expected a plain webhook message, got auth %q and %v", a, msg)
+	}
+
+	bot := webex.New(&webex.Config{BotToken: "bot-token", RoomID: "room-1", APIURL: server.URL})
+	if err := bot.Send(t.Context(), payload); err != nil {
+		t.Fatalf("Bot send failed: %v", err)
+	}
+	msg = <-messages
+	if a := <-auth; a != "Bearer bot-token" || msg["roomId"] != "room-1" {
+		t.Errorf("Expected bot auth and room, got %q and %v", a, msg["roomId"])
+	}
+	attachments, _ := msg["attachments"].([]any)
+	if len(attachments) != 1 {
+		t.Fatalf("Expected one card attachment, got %v", msg["attachments"])
+	}
+	card := attachments[0].(map[string]any)
+	if card["contentType"] != "application/vnd.microsoft.card.adaptive" {
+		t.Errorf("Unexpected content type %v", card["contentType"])
+	}
+	content, _ := json.Marshal(card["content"])
+	if !strings.Contains(string(content), `"title":"View in Grafana"`) || !strings.Contains(string(content), `"FactSet"`) {
+		t.Errorf("Expected facts and link action in card, got %s", content)
+	}
+
+	if err := webex.New(&webex.Config{BotToken: "bot-token"}).Send(t.Context(), payload); err == nil {
+		t.Error("Expected an error without a room ID")
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestWebSocketSession verifies connection lifecycle lines and Loki counters
+func TestWebSocketSession(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/ws-test.access.log")
+	os.Remove(basicLogDir + "/ws-test.loki.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "ws-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "ws-test",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{
+		RequestID: "ws-req-1",
+		Method:    "GET",
+		Path:      "/ws/chat",
+		IP:        "10.0.0.1",
+	})
+
+	normal := logger.TrackWebSocket(ctx)
+	normal.MessageIn(5)
+	normal.MessageOut(7)
+	normal.MessageOut(3)
+	normal.Close(1000, nil)
+	normal.Close(1000, nil)
+
+	dropped := logger.TrackWebSocket(ctx)
+	dropped.Close(1006, nil)
+
+	logger.Close()
+
+	access, _ := os.ReadFile(basicLogDir + "/ws-test.access.log")
+	if strings.Count(string(access), "| OPEN  |") != 2 || strings.Count(string(access), "| CLOSE |") != 2 {
+		t.Errorf("Expected two OPEN and two CLOSE lines, got:\n%s", access)
+	}
+
+	content, err := os.ReadFile(basicLogDir + "/ws-test.loki.log")
+	if err != nil {
+		t.Fatalf("Failed to read Loki log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 Loki entries, got %d", len(lines))
+	}
+
+	var first struct {
+		Level      string           `json:"level"`
+		StatusCode int              `json:"status_code"`
+		WebSocket  map[string]int64 `json:"websocket"`
+	}
+	json.Unmarshal([]byte(lines[0]), &first)
+	if first.Level != "INFO" || first.StatusCode != 101 {
+		t.Errorf("Expected INFO/101 for normal close, got %s/%d", first.Level, first.StatusCode)
+	}
+	if first.WebSocket["messages_in"] != 1 || first.WebSocket["messages_out"] != 2 ||
+		first.WebSocket["bytes_in"] != 5 || first.WebSocket["bytes_out"] != 10 {
+		t.Errorf("Unexpected websocket counters: %v", first.WebSocket)
+	}
+
+	var second LokiLogEntry
+	json.Unmarshal([]byte(lines[1]), &second)
+	if second.Level != "ERROR" {
+		t.Errorf("Expected ERROR level for abnormal close, got %s", second.Level)
+	}
+	if second.Errors == nil || !strings.Contains(second.Errors.Error, "1006") {
+		t.Errorf("Expected abnormal close error, got %+v", second.Errors)
+	}
+	if second.Errors != nil && second.Errors.Source["file"] != "websocket_test.go" {
+		t.Errorf("Expected source websocket_test.go, got %v", second.Errors.Source["file"])
+	}
+}
+
+// TestWebSocketSessionAccessFormat verifies OPEN/CLOSE lines follow AccessLogFormat and AccessLogMinStatus
+func TestWebSocketSessionAccessFormat(t *testing.T) {
+	dir := t.TempDir()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:      "ws-csv-test",
+		LogPath:          dir,
+		FilePrefix:       "ws-csv",
+		EnableFile:       true,
+		EnableRotation:   false,
+		AccessLogFormat:  logging.FormatCSV,
+		AccessLogColumns: []string{"ws_event", "status", "path", "close_code", "messages_in", "bytes_out", "error"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "ws-csv-1", Path: "/ws/chat"})
+	session := logger.TrackWebSocket(ctx)
+	session.MessageIn(5)
+	session.MessageOut(7)
+	session.Close(1006, nil)
+	logger.Close()
+
+	f, err := os.Open(dir + "/ws-csv.access.log")
+	if err != nil {
+		t.Fatalf("Failed to open access log: %v", err)
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("Access log is not valid CSV: %v", err)
+	}
+	want := [][]string{
+		{"ws_event", "status", "path", "close_code", "messages_in", "bytes_out", "error"},
+		{"OPEN", "101", "/ws/chat", "", "", "", ""},
+		{"CLOSE", "101", "/ws/chat", "1006", "1", "7", "websocket closed abnormally (code 1006)"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Expected %d rows, got %d: %v", len(want), len(rows), rows)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("Row %d column %s: expected %q, got %q", i, want[0][j], want[i][j], rows[i][j])
+			}
+		}
+	}
+
+	logfmtDir := t.TempDir()
+	logger, err = logging.New(&logging.Config{
+		ServiceName:     "ws-logfmt-test",
+		LogPath:         logfmtDir,
+		FilePrefix:      "ws-logfmt",
+		EnableFile:      true,
+		EnableRotation:  false,
+		AccessLogFormat: logging.FormatLogfmt,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	session = logger.TrackWebSocket(ctx)
+	session.Close(1000, nil)
+	logger.Close()
+
+	access, _ := os.ReadFile(logfmtDir + "/ws-logfmt.access.log")
+	lines := strings.Split(strings.TrimSpace(string(access)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "ws_event=OPEN") ||
+		!strings.Contains(lines[1], "ws_event=CLOSE") || !strings.Contains(lines[1], "close_code=1000") {
+		t.Errorf("Expected logfmt OPEN and CLOSE lines, got:\n%s", access)
+	}
+
+	filteredDir := t.TempDir()
+	logger, err = logging.New(&logging.Config{
+		ServiceName:        "ws-filter-test",
+		LogPath:            filteredDir,
+		FilePrefix:         "ws-filter",
+		EnableFile:         true,
+		EnableRotation:     false,
+		AccessLogMinStatus: 400,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.TrackWebSocket(ctx).Close(1000, nil)
+	logger.Close()
+
+	if access, _ := os.ReadFile(filteredDir + "/ws-filter.access.log"); len(access) != 0 {
+		t.Errorf("Expected AccessLogMinStatus to filter websocket lines, got:\n%s", access)
+	}
+	if loki, _ := os.ReadFile(filteredDir + "/ws-filter.loki.log"); !strings.Contains(string(loki), `"status_code":101`) {
+		t.Errorf("Expected the Loki entry to be kept, got:\n%s", loki)
+	}
+}
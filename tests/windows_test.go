@@ -0,0 +1,95 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestWindowsOpenLogRemovable verifies open log files can be renamed and deleted, then recreated on Reopen
+func TestWindowsOpenLogRemovable(t *testing.T) {
+	dir := t.TempDir()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "windows-test",
+		LogPath:        dir,
+		FilePrefix:     "win",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	access := filepath.Join(dir, "win.access.log")
+	logger.Info("WINDOWS TEST: before rotate")
+
+	if err := os.Rename(access, access+".1"); err != nil {
+		t.Fatalf("Expected rename of open log to succeed: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "win.loki.log")); err != nil {
+		t.Fatalf("Expected delete of open log to succeed: %v", err)
+	}
+	if err := logger.Reopen(); err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	logger.Info("WINDOWS TEST: after rotate")
+
+	rotated, _ := os.ReadFile(access + ".1")
+	current, _ := os.ReadFile(access)
+	if !strings.Contains(string(rotated), "before rotate") || !strings.Contains(string(current), "after rotate") {
+		t.Errorf("Unexpected content, rotated: %s, current: %s", rotated, current)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "win.loki.log")); err != nil {
+		t.Errorf("Expected deleted loki file to be recreated: %v", err)
+	}
+}
+
+// TestWindowsPaths verifies backslash log paths and source roots
+func TestWindowsPaths(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "windows-test",
+		LogPath:        dir + `\logs\`,
+		StreamPaths:    map[string]string{"error": dir + `\errors`},
+		FilePrefix:     "win",
+		EnableFile:     true,
+		EnableRotation: true,
+		FullPaths:      true,
+		SourceRoot:     filepath.Dir(wd) + `\`,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := logging.WithMeta(context.Background(), logging.Meta{RequestID: "windows-paths"})
+	logger.Error(ctx, errors.New("path failure"))
+	logger.Close()
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "logs", "win.access-*.log"))
+	if len(matches) != 1 {
+		t.Errorf("Expected one rotated access file under logs, got %v", matches)
+	}
+
+	errorLog, err := os.ReadFile(filepath.Join(dir, "errors", "win.error.log"))
+	if err != nil {
+		matches, _ = filepath.Glob(filepath.Join(dir, "errors", "win.error-*.log"))
+		if len(matches) != 1 {
+			t.Fatalf("Expected one error file under errors, got %v", matches)
+		}
+		errorLog, _ = os.ReadFile(matches[0])
+	}
+	if !strings.Contains(string(errorLog), "FROM   : tests/windows_test.go:") {
+		t.Errorf("Expected root-relative path in error log, got:\n%s", errorLog)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+	"github.com/ahmadsaubani/go-logging-lib/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TestOneCallWiring verifies UseGin and WrapHTTP log panicking requests with request metadata
+func TestOneCallWiring(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/wiring.loki.log")
+	os.Remove(basicLogDir + "/wiring.error.log")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "wiring-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "wiring",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	engine := gin.New()
+	middleware.UseGin(engine, logger)
+	engine.GET("/crash", func(c *gin.Context) { panic("nil order") })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /crash", func(w http.ResponseWriter, r *http.Request) { panic("nil refund") })
+	handler := middleware.WrapHTTP(logger, mux)
+
+	for _, h := range []http.Handler{engine, handler} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/crash", nil))
+		if rec.Code != 500 || rec.Header().Get("X-Request-ID") == "" {
+			t.Errorf("Expected 500 with a request ID, got %d %v", rec.Code, rec.Header())
+		}
+	}
+	logger.Close()
+
+	content, _ := os.ReadFile(basicLogDir + "/wiring.loki.log")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 Loki entries, got %d: %s", len(lines), content)
+	}
+	for i, want := range []string{"PANIC: nil order", "PANIC: nil refund"} {
+		var entry LokiLogEntry
+		json.Unmarshal([]byte(lines[i]), &entry)
+		if entry.StatusCode != 500 || entry.RequestID == "" || entry.Errors == nil || entry.Errors.Error != want {
+			t.Errorf("Entry %d: expected 500 with %q, got %s", i, want, lines[i])
+		}
+	}
+
+	errorLog, _ := os.ReadFile(basicLogDir + "/wiring.error.log")
+	if !strings.Contains(string(errorLog), "PANIC: nil order") {
+		t.Errorf("Expected the Gin panic in the error log, got:\n%s", errorLog)
+	}
+}
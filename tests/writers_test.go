@@ -0,0 +1,331 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahmadsaubani/go-logging-lib"
+)
+
+// TestDailyWriterFallback verifies write failures fall back instead of returning errors
+func TestDailyWriterFallback(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+
+	w, err := logging.NewDailyWriter(basicLogDir+"/fallback-test", false)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+
+	if _, err := w.Write([]byte("FALLBACK TEST: before failure\n")); err != nil {
+		t.Fatalf("Unexpected write error: %v", err)
+	}
+
+	// Closing the underlying file makes the next write fail
+	w.Close()
+
+	n, err := w.Write([]byte("FALLBACK TEST: after failure\n"))
+	if err != nil {
+		t.Errorf("Expected fallback write to succeed, got %v", err)
+	}
+	if n != len("FALLBACK TEST: after failure\n") {
+		t.Errorf("Expected full length reported, got %d", n)
+	}
+
+	stats := w.Stats()
+	if stats.Writes != 2 || stats.Failures != 1 || !stats.Fallback || stats.LastError == "" {
+		t.Errorf("Unexpected writer stats: %+v", stats)
+	}
+}
+
+// TestLoggerReopen verifies files moved away by logrotate are recreated on Reopen
+func TestLoggerReopen(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/reopen-test.access.log")
+	os.Remove(basicLogDir + "/reopen-test.access.log.1")
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:    "reopen-test",
+		LogPath:        basicLogDir,
+		FilePrefix:     "reopen-test",
+		EnableFile:     true,
+		EnableRotation: false,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("REOPEN TEST: before rotate")
+
+	if err := os.Rename(basicLogDir+"/reopen-test.access.log", basicLogDir+"/reopen-test.access.log.1"); err != nil {
+		t.Fatalf("Failed to rename log: %v", err)
+	}
+
+	if err := logger.Reopen(); err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+
+	logger.Info("REOPEN TEST: after rotate")
+
+	rotated, _ := os.ReadFile(basicLogDir + "/reopen-test.access.log.1")
+	current, _ := os.ReadFile(basicLogDir + "/reopen-test.access.log")
+
+	if !strings.Contains(string(rotated), "before rotate") || strings.Contains(string(rotated), "after rotate") {
+		t.Errorf("Unexpected rotated file content: %s", rotated)
+	}
+	if !strings.Contains(string(current), "after rotate") {
+		t.Errorf("Expected new file to receive writes after reopen, got: %s", current)
+	}
+}
+
+// TestLatestSymlink verifies the undated name links to the current rotated file
+func TestLatestSymlink(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+	os.Remove(basicLogDir + "/symlink-test.log")
+
+	w, err := logging.NewDailyWriter(basicLogDir+"/symlink-test", true)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer w.Close()
+
+	target, err := os.Readlink(basicLogDir + "/symlink-test.log")
+	if err != nil {
+		t.Fatalf("Expected latest symlink: %v", err)
+	}
+
+	expected := "symlink-test-" + time.Now().Format("2006-01-02") + ".log"
+	if target != expected {
+		t.Errorf("Expected symlink target %s, got %s", expected, target)
+	}
+}
+
+// TestRotationInterval verifies file suffixes for each rotation interval
+func TestRotationInterval(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+
+	now := time.Now()
+	year, week := now.ISOWeek()
+
+	cases := []struct {
+		interval logging.RotationInterval
+		suffix   string
+	}{
+		{logging.RotateHourly, now.Format("2006-01-02-15")},
+		{logging.RotateDaily, now.Format("2006-01-02")},
+		{logging.RotateWeekly, fmt.Sprintf("%d-W%02d", year, week)},
+		{logging.RotateMonthly, now.Format("2006-01")},
+	}
+
+	for _, tc := range cases {
+		base := basicLogDir + "/interval-" + string(tc.interval)
+		expected := base + "-" + tc.suffix + ".log"
+		os.Remove(expected)
+
+		w, err := logging.NewRotatingWriter(base, true, tc.interval)
+		if err != nil {
+			t.Fatalf("Failed to create %s writer: %v", tc.interval, err)
+		}
+		w.Write([]byte("INTERVAL TEST\n"))
+		w.Close()
+
+		if _, err := os.Stat(expected); err != nil {
+			t.Errorf("Expected %s file %s: %v", tc.interval, expected, err)
+		}
+	}
+}
+
+// TestSizeRotation verifies sequence-numbered files once the size limit is hit
+func TestSizeRotation(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+
+	base := basicLogDir + "/size-test"
+	today := time.Now().Format("2006-01-02")
+	for _, suffix := range []string{"", ".1", ".2", ".3"} {
+		os.Remove(base + "-" + today + suffix + ".log")
+	}
+
+	w, err := logging.NewWriter(logging.WriterOptions{
+		BasePath:       base,
+		EnableRotation: true,
+		MaxSizeBytes:   64,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+
+	// 30 byte lines: two fit in each 64 byte file
+	line := []byte(strings.Repeat("x", 29) + "\n")
+	for i := 0; i < 5; i++ {
+		w.Write(line)
+	}
+	w.Close()
+
+	expectedSizes := map[string]int64{"": 60, ".1": 60, ".2": 30}
+	for suffix, size := range expectedSizes {
+		name := base + "-" + today + suffix + ".log"
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Errorf("Expected rotated file %s: %v", name, err)
+			continue
+		}
+		if info.Size() != size {
+			t.Errorf("Expected %s to be %d bytes, got %d", name, size, info.Size())
+		}
+	}
+
+	if _, err := os.Stat(base + "-" + today + ".3.log"); err == nil {
+		t.Error("Did not expect a fourth file")
+	}
+}
+
+// TestFileNameTemplate verifies templated names with a custom date layout and size sequence
+func TestFileNameTemplate(t *testing.T) {
+	basicLogDir := "../examples/basic/logs"
+	os.MkdirAll(basicLogDir, 0755)
+
+	today := time.Now().Format("20060102")
+	for _, name := range []string{"tmpl.error." + today + ".log", "tmpl.loki." + today + ".log", "tmpl.access." + today + ".log", "tmpl.access." + today + ".1.log"} {
+		os.Remove(basicLogDir + "/" + name)
+	}
+
+	logger, err := logging.New(&logging.Config{
+		ServiceName:      "template-test",
+		LogPath:          basicLogDir,
+		FilePrefix:       "tmpl",
+		EnableFile:       true,
+		EnableRotation:   true,
+		FileNameTemplate: "{prefix}.{stream}.{date}.{seq}.log",
+		FileDateLayout:   "20060102",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Info("templated")
+	logger.Close()
+
+	for _, name := range []string{"tmpl.access." + today + ".log", "tmpl.error." + today + ".log", "tmpl.loki." + today + ".log"} {
+		if _, err := os.Stat(basicLogDir + "/" + name); err != nil {
+			t.Errorf("Expected %s: %v", name, err)
+		}
+	}
+
+	w, err := logging.NewWriter(logging.WriterOptions{
+		BasePath:       basicLogDir + "/tmpl.access",
+		Stream:         "access",
+		EnableRotation: true,
+		NameTemplate:   "{prefix}.{stream}.{date}.{seq}.log",
+		DateLayout:     "20060102",
+		MaxSizeBytes:   16,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	w.Write([]byte(strings.Repeat("x", 20) + "\n"))
+	w.Close()
+
+	if _, err := os.Stat(basicLogDir + "/tmpl.access." + today + ".1.log"); err != nil {
+		t.Errorf("Expected size rotation to start a .1 file: %v", err)
+	}
+
+	for _, tmpl := range []string{"{prefix}.{date}.log", "{prefix}.{stream}.log"} {
+		_, err := logging.New(&logging.Config{
+			ServiceName:      "template-test",
+			LogPath:          basicLogDir,
+			EnableRotation:   true,
+			FileNameTemplate: tmpl,
+		})
+		if err == nil {
+			t.Errorf("Expected template %q to be rejected", tmpl)
+		}
+	}
+}
+
+// TestWriterReopensMovedFile verifies a deleted or renamed file is recreated on a later write
+func TestWriterReopensMovedFile(t *testing.T) {
+	dir := t.TempDir()
+	var reports []string
+
+	w, err := logging.NewWriter(logging.WriterOptions{
+		BasePath:      dir + "/moved",
+		CheckInterval: time.Millisecond,
+		OnError:       func(source string, err error) { reports = append(reports, err.Error()) },
+	})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("first\n"))
+	if err := os.Rename(dir+"/moved.log", dir+"/moved.log.1"); err != nil {
+		t.Fatalf("Failed to rename log: %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	w.Write([]byte("second\n"))
+
+	if err := os.Remove(dir + "/moved.log"); err != nil {
+		t.Fatalf("Failed to remove log: %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	w.Write([]byte("third\n"))
+
+	rotated, _ := os.ReadFile(dir + "/moved.log.1")
+	current, _ := os.ReadFile(dir + "/moved.log")
+	if string(rotated) != "first\n" {
+		t.Errorf("Expected only the first entry in the renamed file, got %q", rotated)
+	}
+	if string(current) != "third\n" {
+		t.Errorf("Expected the recreated file to hold the last entry, got %q", current)
+	}
+	if stats := w.Stats(); stats.Reopens != 2 || stats.Fallback {
+		t.Errorf("Expected 2 reopens without fallback, got %+v", stats)
+	}
+	if len(reports) != 2 || !strings.Contains(reports[0], "moved or deleted") {
+		t.Errorf("Expected reopens to be reported, got %v", reports)
+	}
+}
+
+// TestLoggerFileCheck verifies logger files are checked every FileCheckSec unless disabled
+func TestLoggerFileCheck(t *testing.T) {
+	for _, disabled := range []bool{false, true} {
+		dir := t.TempDir()
+		logger, err := logging.New(&logging.Config{
+			ServiceName:      "file-check-test",
+			LogPath:          dir,
+			FilePrefix:       "check",
+			EnableFile:       true,
+			EnableRotation:   false,
+			FileCheckSec:     1,
+			DisableFileCheck: disabled,
+			InternalLog:      &lockedBuffer{},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+
+		logger.Info("FILE CHECK: before delete")
+		os.Remove(dir + "/check.access.log")
+		time.Sleep(1100 * time.Millisecond)
+		logger.Info("FILE CHECK: after delete")
+		logger.Close()
+
+		current, err := os.ReadFile(dir + "/check.access.log")
+		if disabled {
+			if err == nil {
+				t.Errorf("Expected no recreated file with DisableFileCheck, got %q", current)
+			}
+			continue
+		}
+		if !strings.Contains(string(current), "after delete") {
+			t.Errorf("Expected recreated access file, got %q (%v)", current, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+/**
+ * FieldError is one failed binding or validation rule, emitted in the Loki
+ * entry's errors.validation list.
+ */
+type FieldError struct {
+	Field string `json:"field"`
+	Tag   string `json:"tag"`
+	Param string `json:"param,omitempty"`
+	Value string `json:"value"`
+}
+
+/**
+ * ValidationDetails extracts per-field details from binding errors returned by
+ * c.ShouldBind* (validator.ValidationErrors and JSON type mismatches), also
+ * when wrapped or joined with other errors.
+ *
+ * @param err Error to inspect
+ * @return []FieldError Field details, nil if err is not a binding error
+ */
+func ValidationDetails(err error) []FieldError {
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		fields := make([]FieldError, 0, len(verrs))
+		for _, fe := range verrs {
+			fields = append(fields, FieldError{
+				Field: fieldPath(fe.Namespace()),
+				Tag:   fe.Tag(),
+				Param: fe.Param(),
+				Value: fmt.Sprint(fe.Value()),
+			})
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return []FieldError{{
+			Field: typeErr.Field,
+			Tag:   "type",
+			Param: typeErr.Type.String(),
+			Value: typeErr.Value,
+		}}
+	}
+
+	return nil
+}
+
+// fieldPath drops the top-level struct name, e.g. "CreateOrder.Items[0].Qty" -> "Items[0].Qty"
+func fieldPath(namespace string) string {
+	if _, rest, ok := strings.Cut(namespace, "."); ok {
+		return rest
+	}
+	return namespace
+}
@@ -0,0 +1,191 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	wsCloseNormal    = 1000
+	wsCloseGoingAway = 1001
+
+	wsEventOpen  = "OPEN"
+	wsEventClose = "CLOSE"
+)
+
+type WebSocketSession struct {
+	logger      *Logger
+	ctx         context.Context
+	start       time.Time
+	messagesIn  atomic.Int64
+	messagesOut atomic.Int64
+	bytesIn     atomic.Int64
+	bytesOut    atomic.Int64
+	closed      atomic.Bool
+}
+
+/**
+ * TrackWebSocket logs a websocket upgrade and returns a session that records
+ * traffic until Close. HTTPLogger only sees the handshake, so the session writes
+ * its own access line and Loki entry when the connection ends.
+ * Counters are safe to update from concurrent reader and writer goroutines.
+ *
+ * @param ctx Context of the upgraded request, carrying its Meta
+ * @return *WebSocketSession Session to report messages and closure on
+ */
+func (l *Logger) TrackWebSocket(ctx context.Context) *WebSocketSession {
+	s := &WebSocketSession{
+		logger: l,
+		ctx:    ctx,
+		start:  time.Now(),
+	}
+
+	s.writeAccess(LevelInfo, &wsRecord{event: wsEventOpen}, 0, nil)
+
+	return s
+}
+
+/**
+ * MessageIn records a message received from the client.
+ *
+ * @param size Message size in bytes
+ */
+func (s *WebSocketSession) MessageIn(size int) {
+	s.messagesIn.Add(1)
+	s.bytesIn.Add(int64(size))
+}
+
+/**
+ * MessageOut records a message sent to the client.
+ *
+ * @param size Message size in bytes
+ */
+func (s *WebSocketSession) MessageOut(size int) {
+	s.messagesOut.Add(1)
+	s.bytesOut.Add(int64(size))
+}
+
+/**
+ * Close logs the end of the connection with its duration and traffic counters.
+ * Close codes other than 1000 (normal) and 1001 (going away), or a non-nil err,
+ * are treated as abnormal: the entry is logged at ERROR level and alerts fire.
+ * Only the first call has an effect.
+ *
+ * @param code WebSocket close code (1006 if the connection dropped without one)
+ * @param err Optional read/write error that ended the connection
+ */
+func (s *WebSocketSession) Close(code int, err error) {
+	if !s.closed.CompareAndSwap(false, true) {
+		return
+	}
+
+	duration := time.Since(s.start)
+
+	if err == nil && code != wsCloseNormal && code != wsCloseGoingAway {
+		err = fmt.Errorf("websocket closed abnormally (code %d)", code)
+	}
+
+	level := LevelInfo
+	if err != nil {
+		level = LevelError
+	}
+
+	fields := map[string]interface{}{
+		"websocket": map[string]int64{
+			"close_code":   int64(code),
+			"messages_in":  s.messagesIn.Load(),
+			"messages_out": s.messagesOut.Load(),
+			"bytes_in":     s.bytesIn.Load(),
+			"bytes_out":    s.bytesOut.Load(),
+		},
+	}
+	if !s.logger.writeLokiFields(s.ctx, string(level), http.StatusSwitchingProtocols, duration, err, 3, fields) {
+		return
+	}
+
+	s.writeAccess(level, &wsRecord{
+		event:       wsEventClose,
+		closeCode:   code,
+		messagesIn:  s.messagesIn.Load(),
+		messagesOut: s.messagesOut.Load(),
+		bytesIn:     s.bytesIn.Load(),
+		bytesOut:    s.bytesOut.Load(),
+	}, duration, err)
+
+	if err != nil {
+		s.logger.sendAlert(s.ctx, string(level), err, 2)
+	}
+}
+
+/**
+ * writeAccess writes an OPEN or CLOSE access line in the configured access
+ * format. The line counts as status 101, so AccessLogMinStatus above that
+ * leaves websocket sessions to Loki only.
+ */
+func (s *WebSocketSession) writeAccess(level LogLevel, ws *wsRecord, duration time.Duration, err error) {
+	l := s.logger
+	if http.StatusSwitchingProtocols < l.config.AccessLogMinStatus {
+		return
+	}
+	meta, _ := FromContext(s.ctx)
+
+	switch l.config.AccessLogFormat {
+	case FormatCSV:
+		l.accessLogger.Print(l.csvRow(accessRecord{
+			level:   level,
+			meta:    meta,
+			status:  http.StatusSwitchingProtocols,
+			latency: duration,
+			err:     err,
+			ws:      ws,
+		}))
+	case FormatLogfmt:
+		fields := []logfmtField{
+			{"ts", time.Now().Format(time.RFC3339)},
+			{"level", level},
+			{"request_id", meta.RequestID},
+			{"ws_event", ws.event},
+			{"status", http.StatusSwitchingProtocols},
+			{"ip", meta.IP},
+			{"path", meta.Path},
+		}
+		if ws.event == wsEventClose {
+			fields = append(fields,
+				logfmtField{"close_code", ws.closeCode},
+				logfmtField{"duration_ms", duration.Milliseconds()},
+				logfmtField{"messages_in", ws.messagesIn},
+				logfmtField{"bytes_in", ws.bytesIn},
+				logfmtField{"messages_out", ws.messagesOut},
+				logfmtField{"bytes_out", ws.bytesOut},
+			)
+		}
+		l.accessLogger.Print(encodeLogfmt(fields))
+	default:
+		if ws.event == wsEventOpen {
+			l.accessLogger.Printf(
+				"[WS:%s] %s | OPEN  | %15s | %s",
+				meta.RequestID,
+				s.start.Format(time.RFC3339),
+				meta.IP,
+				meta.Path,
+			)
+			return
+		}
+		l.accessLogger.Printf(
+			"[WS:%s] %s | CLOSE | %15s | %s | code=%d duration=%v in=%d/%dB out=%d/%dB",
+			meta.RequestID,
+			time.Now().Format(time.RFC3339),
+			meta.IP,
+			meta.Path,
+			ws.closeCode,
+			duration,
+			ws.messagesIn,
+			ws.bytesIn,
+			ws.messagesOut,
+			ws.bytesOut,
+		)
+	}
+}
@@ -1,18 +1,103 @@
 package logging
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
+const writerRetryInterval = 30 * time.Second
+
+type RotationInterval string
+
+const (
+	RotateHourly  RotationInterval = "hourly"
+	RotateDaily   RotationInterval = "daily"
+	RotateWeekly  RotationInterval = "weekly"
+	RotateMonthly RotationInterval = "monthly"
+)
+
 type DailyWriter struct {
 	mu             sync.Mutex
 	basePath       string
 	file           *os.File
+	path           string
 	current        string
 	enableRotation bool
+	interval       RotationInterval
+	nameTemplate   string
+	stream         string
+	dateLayout     string
+	date           string
+	maxSize        int64
+	size           int64
+	syncEvery      int
+	unsynced       int
+	seq            int
+	header         []byte
+	onError        func(source string, err error)
+	fallback       io.Writer
+	failed         bool
+	lastRetry      time.Time
+	checkInterval  time.Duration
+	lastCheck      time.Time
+	stats          WriterStats
+}
+
+/**
+ * WriterStats holds internal counters for a DailyWriter.
+ * Failures counts writes that could not reach the file and went to the fallback.
+ * Syncs counts fsyncs, from SyncEvery and from Flush. Reopens counts files
+ * re-created after an external tool moved or deleted them.
+ */
+type WriterStats struct {
+	Writes    uint64
+	Failures  uint64
+	Recovered uint64
+	Syncs     uint64
+	Reopens   uint64
+	Fallback  bool
+	LastError string
+}
+
+/**
+ * WriterOptions configures a DailyWriter.
+ * MaxSizeBytes adds size based rotation on top of the time boundary: when the
+ * current file would exceed it, a sequence-numbered file is started
+ * (app.access-2026-02-04.1.log, app.access-2026-02-04.2.log, ...).
+ * NameTemplate replaces the default name (BasePath-date.seq.log) with one built
+ * from {prefix}, {stream}, {date} and {seq}, e.g. "{prefix}.{stream}.{date}.{seq}.log",
+ * in the directory of BasePath. Stream is the suffix of BasePath filling
+ * {stream}; the rest of the base name fills {prefix}. An empty {date} or {seq}
+ * drops the separator in front of it. DateLayout, a time layout, overrides
+ * the interval's date format and should not be coarser than the interval.
+ * Header, if set, is written at the start of every new (empty) file, e.g. a
+ * CSV header row. SyncEvery fsyncs the file after every N successful writes,
+ * trading throughput for entries that survive a crash or power loss (0 leaves
+ * it to the OS). CheckInterval, if set, makes a write stat the file at most
+ * that often and reopen it when it was moved or deleted, instead of writing
+ * on into an unlinked file. Fallback receives entries while the file cannot
+ * be written (default: stdout). OnError receives write failures; without it
+ * they are printed to stdout.
+ */
+type WriterOptions struct {
+	BasePath       string
+	EnableRotation bool
+	Interval       RotationInterval
+	NameTemplate   string
+	Stream         string
+	DateLayout     string
+	MaxSizeBytes   int64
+	SyncEvery      int
+	CheckInterval  time.Duration
+	Header         []byte
+	Fallback       io.Writer
+	OnError        func(source string, err error)
 }
 
 /**
@@ -25,69 +110,474 @@ type DailyWriter struct {
  * @return error Error if file creation fails
  */
 func NewDailyWriter(basePath string, enableRotation bool) (*DailyWriter, error) {
+	return NewRotatingWriter(basePath, enableRotation, RotateDaily)
+}
+
+/**
+ * NewRotatingWriter creates a rotating writer with a configurable interval.
+ * File suffixes follow the interval: 2006-01-02-15 (hourly), 2006-01-02 (daily),
+ * 2006-W01 (ISO week), or 2006-01 (monthly).
+ *
+ * @param basePath Base path for log files (without extension)
+ * @param enableRotation Enable time based file rotation
+ * @param interval Rotation boundary (defaults to daily if empty or unknown)
+ * @return *DailyWriter Rotating file writer
+ * @return error Error if file creation fails
+ */
+func NewRotatingWriter(basePath string, enableRotation bool, interval RotationInterval) (*DailyWriter, error) {
+	return NewWriter(WriterOptions{
+		BasePath:       basePath,
+		EnableRotation: enableRotation,
+		Interval:       interval,
+	})
+}
+
+/**
+ * NewWriter creates a rotating writer from options.
+ * Rotation happens at whichever comes first: the time boundary or the size limit.
+ *
+ * @param opts Writer options
+ * @return *DailyWriter Rotating file writer
+ * @return error Error if file creation fails
+ */
+func NewWriter(opts WriterOptions) (*DailyWriter, error) {
+	fallback := opts.Fallback
+	if fallback == nil {
+		fallback = os.Stdout
+	}
+
 	w := &DailyWriter{
-		basePath:       basePath,
-		enableRotation: enableRotation,
+		basePath:       opts.BasePath,
+		enableRotation: opts.EnableRotation,
+		interval:       opts.Interval,
+		nameTemplate:   opts.NameTemplate,
+		stream:         opts.Stream,
+		dateLayout:     opts.DateLayout,
+		maxSize:        opts.MaxSizeBytes,
+		syncEvery:      opts.SyncEvery,
+		checkInterval:  opts.CheckInterval,
+		header:         opts.Header,
+		onError:        opts.OnError,
+		fallback:       fallback,
 	}
-	if err := w.rotateIfNeeded(); err != nil {
+	if err := w.rotateIfNeeded(0); err != nil {
 		return nil, err
 	}
 	return w, nil
 }
 
+/**
+ * Write appends p to the current log file.
+ * If the file cannot be written (disk full, permission change), the entry goes to
+ * stdout instead and the file is re-opened periodically until writes succeed again.
+ * Errors are never returned to log.Logger, where they would be silently dropped.
+ *
+ * @param p Bytes to write
+ * @return int Number of bytes accepted
+ * @return error Always nil
+ */
 func (w *DailyWriter) Write(p []byte) (n int, err error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	if err := w.rotateIfNeeded(); err != nil {
-		return 0, err
+	w.stats.Writes++
+
+	if w.failed {
+		if time.Since(w.lastRetry) < writerRetryInterval || !w.recover() {
+			return w.writeFallback(p)
+		}
+	}
+
+	if w.checkInterval > 0 && time.Since(w.lastCheck) >= w.checkInterval {
+		w.lastCheck = time.Now()
+		if w.moved() {
+			w.reopenMoved()
+		}
+	}
+
+	if err := w.rotateIfNeeded(len(p)); err != nil {
+		w.markFailed(err)
+		return w.writeFallback(p)
+	}
+
+	n, err = w.file.Write(p)
+	w.size += int64(n)
+	if err != nil {
+		w.markFailed(err)
+		return w.writeFallback(p[n:])
+	}
+
+	if w.syncEvery > 0 {
+		w.unsynced++
+		if w.unsynced >= w.syncEvery {
+			// the entry is in the file already, only later writes take the fallback
+			if err := w.sync(); err != nil {
+				w.markFailed(err)
+			}
+		}
+	}
+
+	return len(p), nil
+}
+
+func (w *DailyWriter) sync() error {
+	w.unsynced = 0
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+	w.stats.Syncs++
+	return nil
+}
+
+/**
+ * Stats returns a snapshot of the writer counters.
+ *
+ * @return WriterStats Current counters and fallback state
+ */
+func (w *DailyWriter) Stats() WriterStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.stats
+}
+
+func (w *DailyWriter) markFailed(err error) {
+	if !w.failed {
+		if w.onError != nil {
+			w.onError("writer", fmt.Errorf("write to %s failed, falling back to %s: %w", w.basePath, w.fallbackName(), err))
+		} else {
+			fmt.Printf("[DailyWriter] write to %s failed: %v, falling back to %s\n", w.basePath, err, w.fallbackName())
+		}
+	}
+
+	w.failed = true
+	w.lastRetry = time.Now()
+	w.stats.Fallback = true
+	w.stats.LastError = err.Error()
+}
+
+func (w *DailyWriter) recover() bool {
+	w.lastRetry = time.Now()
+
+	if w.file != nil {
+		_ = w.file.Close()
+		w.file = nil
+	}
+	w.current = ""
+
+	if err := w.rotateIfNeeded(0); err != nil {
+		w.stats.LastError = err.Error()
+		return false
+	}
+
+	// a fallback file only receives entries, never notices about them
+	if w.fallback == os.Stdout {
+		fmt.Fprintf(w.fallback, "[DailyWriter] write to %s recovered\n", w.basePath)
+	}
+
+	w.failed = false
+	w.stats.Fallback = false
+	w.stats.Recovered++
+	return true
+}
+
+/**
+ * moved reports whether the open file no longer sits at its path, because it
+ * was deleted or renamed (e.g. by logrotate without a postrotate Reopen).
+ */
+func (w *DailyWriter) moved() bool {
+	if w.file == nil {
+		return false
+	}
+
+	onDisk, err := os.Stat(w.path)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	open, err := w.file.Stat()
+	if err != nil {
+		return false
+	}
+	return !os.SameFile(onDisk, open)
+}
+
+/**
+ * reopenMoved closes the moved file and lets rotateIfNeeded open a new one at
+ * the expected path. If that fails, the next write takes the fallback path.
+ */
+func (w *DailyWriter) reopenMoved() {
+	_ = w.file.Close()
+	w.file = nil
+	w.current = ""
+	w.stats.Reopens++
+
+	if w.onError != nil {
+		w.onError("writer", fmt.Errorf("%s was moved or deleted, reopening", w.path))
+	} else {
+		fmt.Printf("[DailyWriter] %s was moved or deleted, reopening\n", w.path)
+	}
+}
+
+func (w *DailyWriter) fallbackName() string {
+	if f, ok := w.fallback.(*DailyWriter); ok {
+		return f.basePath
+	}
+	return "stdout"
+}
+
+func (w *DailyWriter) writeFallback(p []byte) (int, error) {
+	w.stats.Failures++
+	_, _ = w.fallback.Write(p)
+	return len(p), nil
+}
+
+func (w *DailyWriter) periodKey(t time.Time) string {
+	switch w.interval {
+	case RotateHourly:
+		return t.Format("2006-01-02-15")
+	case RotateWeekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case RotateMonthly:
+		return t.Format("2006-01")
+	default:
+		return t.Format("2006-01-02")
+	}
+}
+
+/**
+ * dateLabel is the {date} part of file names for t: DateLayout if set,
+ * otherwise the period key.
+ */
+func (w *DailyWriter) dateLabel(t time.Time) string {
+	if w.dateLayout != "" {
+		return t.Format(w.dateLayout)
+	}
+	return w.periodKey(t)
+}
+
+func (w *DailyWriter) filename(date string, seq int) string {
+	if w.nameTemplate != "" {
+		return w.templateName(date, seq)
+	}
+
+	name := w.basePath
+	if date != "" {
+		name += "-" + date
+	}
+	if seq > 0 {
+		name += "." + strconv.Itoa(seq)
+	}
+	return name + ".log"
+}
+
+func (w *DailyWriter) templateName(date string, seq int) string {
+	seqLabel := ""
+	if seq > 0 {
+		seqLabel = strconv.Itoa(seq)
+	}
+
+	base := filepath.Base(w.basePath)
+	values := map[string]string{
+		"prefix": base,
+		"stream": w.stream,
+		"date":   date,
+		"seq":    seqLabel,
+	}
+	if w.stream != "" {
+		values["prefix"] = strings.TrimSuffix(base, "."+w.stream)
+	}
+
+	return filepath.Join(filepath.Dir(w.basePath), expandNameTemplate(w.nameTemplate, values))
+}
+
+/**
+ * expandNameTemplate replaces {name} placeholders with values. A placeholder
+ * that expands to nothing also drops one ".", "-" or "_" right before it, so
+ * "{prefix}.{stream}.{date}.{seq}.log" yields app.access.2026-02-04.log for
+ * the first file of a period.
+ */
+func expandNameTemplate(tmpl string, values map[string]string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(tmpl, '{')
+		end := strings.IndexByte(tmpl, '}')
+		if start < 0 || end < start {
+			b.WriteString(tmpl)
+			return b.String()
+		}
+
+		b.WriteString(tmpl[:start])
+		value, known := values[tmpl[start+1:end]]
+		if !known {
+			value = tmpl[start : end+1]
+		}
+		if value == "" {
+			out := b.String()
+			if n := len(out); n > 0 && strings.ContainsRune(".-_", rune(out[n-1])) {
+				b.Reset()
+				b.WriteString(out[:n-1])
+			}
+		}
+		b.WriteString(value)
+		tmpl = tmpl[end+1:]
 	}
-	return w.file.Write(p)
 }
 
-func (w *DailyWriter) rotateIfNeeded() error {
-	today := time.Now().Format("2006-01-02")
+/**
+ * validateNameTemplate checks Config.FileNameTemplate: {stream} must be present
+ * so streams do not share a file, {date} when files rotate by time and {seq}
+ * when they rotate by size.
+ */
+func validateNameTemplate(tmpl string, rotation, sizeLimit bool) error {
+	required := []string{"{stream}"}
+	if rotation {
+		required = append(required, "{date}")
+	}
+	if sizeLimit {
+		required = append(required, "{seq}")
+	}
 
-	if !w.enableRotation {
-		if w.file != nil {
-			return nil
+	for _, p := range required {
+		if !strings.Contains(tmpl, p) {
+			return fmt.Errorf("file name template %q needs %s", tmpl, p)
 		}
-		return w.openFile(w.basePath + ".log")
 	}
+	return nil
+}
+
+func (w *DailyWriter) sizeExceeded(incoming int) bool {
+	return w.maxSize > 0 && w.size > 0 && w.size+int64(incoming) > w.maxSize
+}
 
-	if w.file != nil && w.current == today {
+/**
+ * lastSequence returns the highest existing sequence number for a period,
+ * so a restarted process keeps appending to the newest file.
+ */
+func (w *DailyWriter) lastSequence(date string) int {
+	seq := 0
+	for {
+		if _, err := os.Stat(w.filename(date, seq+1)); err != nil {
+			return seq
+		}
+		seq++
+	}
+}
+
+func (w *DailyWriter) rotateIfNeeded(incoming int) error {
+	period, date := "", ""
+	if w.enableRotation {
+		now := time.Now()
+		period, date = w.periodKey(now), w.dateLabel(now)
+	}
+
+	samePeriod := w.file != nil && w.current == period
+	if samePeriod && !w.sizeExceeded(incoming) {
 		return nil
 	}
 
+	if samePeriod {
+		w.seq++
+	} else {
+		w.seq = w.lastSequence(date)
+	}
+
 	if w.file != nil {
+		if w.unsynced > 0 {
+			_ = w.sync()
+		}
 		_ = w.file.Close()
+		w.file = nil
 	}
 
-	filename := w.basePath + "-" + today + ".log"
+	filename := w.filename(date, w.seq)
 	if err := w.openFile(filename); err != nil {
 		return err
 	}
 
-	w.current = today
+	w.current = period
+	if w.enableRotation {
+		w.updateLatestLink(filename)
+	}
 	return nil
 }
 
+/**
+ * updateLatestLink points basePath.log at the current rotated file so tail -f
+ * and simple tooling can follow a stable name. Regular files at that path are
+ * left untouched, and failures (e.g. no symlink permission, which Windows
+ * requires Developer Mode or admin rights for) are ignored.
+ *
+ * @param filename Path of the newly opened rotated file
+ */
+func (w *DailyWriter) updateLatestLink(filename string) {
+	link := w.basePath + ".log"
+
+	if info, err := os.Lstat(link); err == nil && info.Mode()&os.ModeSymlink == 0 {
+		return
+	}
+
+	tmp := link + ".tmp"
+	_ = os.Remove(tmp)
+
+	if err := os.Symlink(filepath.Base(filename), tmp); err != nil {
+		return
+	}
+
+	if err := os.Rename(tmp, link); err != nil {
+		_ = os.Remove(tmp)
+	}
+}
+
 func (w *DailyWriter) openFile(filename string) error {
 	dir := filepath.Dir(w.basePath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
 
-	file, err := os.OpenFile(
-		filename,
-		os.O_CREATE|os.O_APPEND|os.O_WRONLY,
-		0644,
-	)
+	file, err := openLogFile(filename)
 	if err != nil {
 		return err
 	}
 
+	w.size = 0
+	if info, err := file.Stat(); err == nil {
+		w.size = info.Size()
+	}
+
+	if w.size == 0 && len(w.header) > 0 {
+		n, _ := file.Write(w.header)
+		w.size = int64(n)
+	}
+
 	w.file = file
+	w.path = filename
+	return nil
+}
+
+/**
+ * Reopen closes the current file handle and opens the target path again.
+ * Use after an external tool (e.g. logrotate) has moved the file away.
+ *
+ * @return error Error if the file cannot be re-opened
+ */
+func (w *DailyWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file != nil {
+		_ = w.file.Close()
+		w.file = nil
+	}
+	w.current = ""
+
+	if err := w.rotateIfNeeded(0); err != nil {
+		w.markFailed(err)
+		return err
+	}
+
+	w.failed = false
+	w.stats.Fallback = false
 	return nil
 }
 
@@ -99,4 +589,87 @@ func (w *DailyWriter) Close() error {
 		return w.file.Close()
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+/**
+ * Flush commits the current file's contents to stable storage.
+ *
+ * @return error Error from fsync, if any
+ */
+func (w *DailyWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil || w.failed {
+		return nil
+	}
+	return w.sync()
+}
+
+/**
+ * lazyStream defers opening a DailyWriter until the first write, for streams
+ * such as debug whose file should only exist once something is logged to it.
+ */
+type lazyStream struct {
+	mu      sync.Mutex
+	open    func() (*DailyWriter, error)
+	onError func(source string, err error)
+	w       *DailyWriter
+}
+
+func (s *lazyStream) writer() (*DailyWriter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.w == nil {
+		w, err := s.open()
+		if err != nil {
+			return nil, err
+		}
+		s.w = w
+	}
+	return s.w, nil
+}
+
+func (s *lazyStream) opened() *DailyWriter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.w
+}
+
+func (s *lazyStream) Write(p []byte) (int, error) {
+	w, err := s.writer()
+	if err != nil {
+		// same contract as DailyWriter: never lose the entry, never return the error
+		if s.onError != nil {
+			s.onError("writer", fmt.Errorf("open failed, falling back to stdout: %w", err))
+		} else {
+			fmt.Printf("[DailyWriter] open failed: %v, falling back to stdout\n", err)
+		}
+		os.Stdout.Write(p)
+		return len(p), nil
+	}
+	return w.Write(p)
+}
+
+func (s *lazyStream) Reopen() error {
+	if w := s.opened(); w != nil {
+		return w.Reopen()
+	}
+	return nil
+}
+
+func (s *lazyStream) Flush() error {
+	if w := s.opened(); w != nil {
+		return w.Flush()
+	}
+	return nil
+}
+
+func (s *lazyStream) Close() error {
+	if w := s.opened(); w != nil {
+		return w.Close()
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package logging
+
+import "os"
+
+/**
+ * openLogFile opens filename for appending, creating it if needed.
+ *
+ * @param filename Path of the log file
+ * @return *os.File Open file positioned for appends
+ * @return error Error if the file cannot be opened
+ */
+func openLogFile(filename string) (*os.File, error) {
+	return os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+}
@@ -0,0 +1,55 @@
+//go:build windows
+
+package logging
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+const (
+	fileReadAttributes  = 0x0080
+	fileWriteAttributes = 0x0100
+
+	errorSharingViolation syscall.Errno = 32
+	errorLockViolation    syscall.Errno = 33
+)
+
+/**
+ * openLogFile opens filename for appending, creating it if needed. Unlike
+ * os.OpenFile the handle is shared for delete, so rotation tools can rename or
+ * remove the file while it is open, as on POSIX systems (Reopen then picks up
+ * the new file). Sharing violations from scanners or indexers that briefly hold
+ * a freshly rotated file are retried for up to 100ms.
+ *
+ * @param filename Path of the log file
+ * @return *os.File Open file positioned for appends
+ * @return error Error if the file cannot be opened
+ */
+func openLogFile(filename string) (*os.File, error) {
+	path, err := syscall.UTF16PtrFromString(filename)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: filename, Err: err}
+	}
+
+	for attempt := 0; ; attempt++ {
+		h, err := syscall.CreateFile(
+			path,
+			syscall.FILE_APPEND_DATA|fileWriteAttributes|fileReadAttributes|syscall.SYNCHRONIZE,
+			syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+			nil,
+			syscall.OPEN_ALWAYS,
+			syscall.FILE_ATTRIBUTE_NORMAL,
+			0,
+		)
+		if err == nil {
+			return os.NewFile(uintptr(h), filename), nil
+		}
+		if attempt >= 9 || !(errors.Is(err, errorSharingViolation) || errors.Is(err, errorLockViolation)) {
+			return nil, &os.PathError{Op: "open", Path: filename, Err: err}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}